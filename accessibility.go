@@ -0,0 +1,219 @@
+package main
+
+import (
+	"math"
+	"os/exec"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Keyboard-only operation and announcements:
+
+// Every tool can be used without a mouse:
+//
+//	1-9, 0:       pick a tool (same order as the toolbar; Shift+1, Shift+2, ...: the ones after the tenth).
+//	Tab:          focus the next vertex (Shift+Tab: previous).
+//	Arrows:       focus the nearest vertex in that direction.
+//	Shift+Arrows: move the focused vertex.
+//	Enter/Space:  use the tool on the focused vertex (Add Edge / Delete Edge / Set Weight: once per end).
+//	              With Select, adds the focused vertex to the selection or takes it out.
+//	              Without a focused vertex, only Enter does (Space + drag pans the canvas).
+//	              With Add Vertex, places a vertex next to the focused one.
+//	              With the Pen, continues the chain from the focused vertex.
+//	Escape:       stop an animation, cancel a half-made edge, end the pen chain, drop the selection, or drop the focus.
+//
+// Announcements are short sentences describing what just happened, for screen readers and TTS.
+// Register hooks with OnAnnounce, or set AnnounceCommand in the settings (e.g. "espeak" or "say")
+// to have every announcement spoken.
+
+const keyboardMoveStep = 10 // Pixels per Shift+Arrow press
+
+// Registers a hook that receives every announcement.
+func (app *App) OnAnnounce(hook func(msg string)) {
+	app.announce = append(app.announce, hook)
+}
+
+// Sends a message to the announcement hooks.
+func (app *App) Announce(msg string) {
+	if msg == "" {
+		return
+	}
+	for _, hook := range app.announce {
+		hook(msg)
+	}
+	if cmd := app.Settings.AnnounceCommand; cmd != "" {
+		go exec.Command(cmd, msg).Run() // Don't block the game loop on speech
+	}
+}
+
+// Describes an action for announcements. Called before the action is applied.
+func (app *App) describe(a Action) string {
+	label := func(i int) string {
+		if app.Graph.CheckVertices(i) != nil {
+			return "?"
+		}
+		return app.Graph.Vertices[i].Label
+	}
+	switch a.Kind {
+	case ActionAddVertex:
+		return T("announce.add_vertex", a.Label)
+	case ActionAddEdge:
+		return T("announce.add_edge", label(a.V1), label(a.V2))
+	case ActionDeleteVertex:
+		return T("announce.delete_vertex", label(a.V1))
+	case ActionDeleteEdge:
+		return T("announce.delete_edge", label(a.V1), label(a.V2))
+	case ActionColorVertex:
+		return T("announce.color_vertex", label(a.V1))
+	case ActionNameVertex:
+		return T("announce.name_vertex", label(a.V1), a.Label)
+	case ActionClear:
+		return T("announce.clear")
+	case ActionStyleEdge:
+		return T("announce.style_edge", label(a.V1), label(a.V2))
+	case ActionWeightVertex:
+		return T("announce.weight_vertex", label(a.V1), formatMetric(a.Weight))
+	case ActionTimeVertex:
+		return T("announce.time", label(a.V1), a.Time.String())
+	case ActionPlaceLabel:
+		return T("announce.place_label", label(a.V1))
+	case ActionLayerVertex:
+		return T("announce.layer_vertex", label(a.V1))
+	case ActionTimeEdge:
+		return T("announce.time", label(a.V1)+" - "+label(a.V2), a.Time.String())
+	case ActionDefineAttr:
+		return T("announce.define_attr", a.Column.Name, T(attrTypeKeys[a.Column.Type]))
+	case ActionWeightEdge:
+		return T("announce.weight_edge", label(a.V1), label(a.V2), formatMetric(a.Weight))
+	case ActionReorderVertices:
+		return T("announce.reorder")
+	case ActionSetDirected:
+		if a.Directed {
+			return T("announce.directed")
+		}
+		return T("announce.undirected")
+	}
+	return "" // Moves happen too often to announce
+}
+
+// Moves the keyboard focus to a vertex and announces it.
+func (app *App) FocusVertex(i int) {
+	if app.Graph.CheckVertices(i) != nil {
+		return
+	}
+	app.Focused = &i
+	v := app.Graph.Vertices[i]
+	app.Announce(T("announce.focus", v.Label, app.Graph.Degree(i)))
+}
+
+// Finds the nearest vertex from the focused one in a direction (within 45 degrees of it).
+func (app *App) vertexInDirection(from int, dx, dy float64) int {
+	best, bestDist := -1, math.Inf(1)
+	v := app.Graph.Vertices[from]
+	for i, w := range app.Graph.Vertices {
+		ox, oy := w.X-v.X, w.Y-v.Y
+		dist := math.Hypot(ox, oy)
+		if i == from || dist == 0 {
+			continue
+		}
+		if (ox*dx+oy*dy)/dist < math.Cos(math.Pi/4) {
+			continue // Not in that direction
+		}
+		if dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// Processes the keyboard-only controls.
+func (app *App) HandleKeyboardNavigation() {
+	n := len(app.Graph.Vertices)
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+
+	// Tools: the digits pick the first ten, Shift+digits the next ten
+	toolKeys := []ebiten.Key{
+		ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3, ebiten.KeyDigit4, ebiten.KeyDigit5,
+		ebiten.KeyDigit6, ebiten.KeyDigit7, ebiten.KeyDigit8, ebiten.KeyDigit9, ebiten.KeyDigit0,
+	}
+	for t := range min(len(toolNames), 2*len(toolKeys)) {
+		if shift == (t >= len(toolKeys)) && inpututil.IsKeyJustPressed(toolKeys[t%len(toolKeys)]) {
+			app.SelectTool(Tool(t))
+		}
+	}
+
+	// Tab through vertices
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) && n > 0 {
+		next := 0
+		if app.Focused != nil {
+			next = *app.Focused + 1
+			if shift {
+				next = *app.Focused - 1 + n
+			}
+		}
+		app.FocusVertex(next % n)
+	}
+
+	// Arrows: navigate, or move with Shift
+	arrows := []struct {
+		key    ebiten.Key
+		dx, dy float64
+	}{
+		{ebiten.KeyArrowLeft, -1, 0},
+		{ebiten.KeyArrowRight, 1, 0},
+		{ebiten.KeyArrowUp, 0, -1},
+		{ebiten.KeyArrowDown, 0, 1},
+	}
+	for _, arrow := range arrows {
+		if !inpututil.IsKeyJustPressed(arrow.key) {
+			continue
+		}
+		if app.Focused == nil {
+			if n > 0 {
+				app.FocusVertex(0)
+			}
+			continue
+		}
+		if shift {
+			v := app.Graph.Vertices[*app.Focused]
+			app.Do(Action{Kind: ActionMoveVertex, V1: *app.Focused, X: v.X + arrow.dx*keyboardMoveStep, Y: v.Y + arrow.dy*keyboardMoveStep})
+		} else if i := app.vertexInDirection(*app.Focused, arrow.dx, arrow.dy); i >= 0 {
+			app.FocusVertex(i)
+		}
+	}
+
+	// Use the tool
+	// Space alone also pans the canvas while held with a drag, so it only acts on a focused vertex
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || (inpututil.IsKeyJustPressed(ebiten.KeySpace) && app.Focused != nil) {
+		switch {
+		case app.Tool == ToolAddVertex:
+			x, y := app.toWorld(float64(screenWidth)/2, float64(screenHeight)/2)
+			if app.Focused != nil {
+				v := app.Graph.Vertices[*app.Focused]
+				x, y = v.X+40, v.Y+40
+			}
+			app.AddVertexAt(x, y)
+			app.FocusVertex(len(app.Graph.Vertices) - 1)
+		case app.Focused != nil:
+			app.UseTool(*app.Focused)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if app.Importing != nil {
+			app.CancelImport()
+		} else if app.Animation != nil {
+			app.StopAnimation()
+		} else if app.EdgeStart != nil {
+			app.EdgeStart = nil
+			app.Announce(T("announce.edge_cancelled"))
+		} else if app.PenLast != nil {
+			app.EndPenChain()
+		} else if !app.Selection.Empty() {
+			app.Selection.Clear()
+		} else {
+			app.Focused = nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Transparency:
+
+// Vertex and edge colors may be partly transparent, so overlays (a highlighted subgraph, a bundle)
+// don't hide what's under them. Colors are color.RGBA, which is alpha-premultiplied: that's what
+// ebiten blends with, and PNG exports keep it. SVG and TikZ want the plain color plus an opacity,
+// see svgPaint and tikzOpacity.
+//
+// The option bars of the Add Vertex, Add Edge and Select tools cycle the opacity of the colors they set.
+
+var opacitySteps = []uint8{255, 191, 128, 64} // 100%, 75%, 50%, 25%
+
+// Returns the color at full opacity.
+func opaque(c color.RGBA) color.RGBA {
+	if c.A == 0 || c.A == 255 {
+		return color.RGBA{c.R, c.G, c.B, 255}
+	}
+	unmultiply := func(x uint8) uint8 { return uint8(min(255, int(x)*255/int(c.A))) }
+	return color.RGBA{unmultiply(c.R), unmultiply(c.G), unmultiply(c.B), 255}
+}
+
+// Returns the color with another opacity (alpha 0 to 255).
+func withOpacity(c color.RGBA, alpha uint8) color.RGBA {
+	c = opaque(c)
+	multiply := func(x uint8) uint8 { return uint8(int(x) * int(alpha) / 255) }
+	return color.RGBA{multiply(c.R), multiply(c.G), multiply(c.B), alpha}
+}
+
+// Returns the color at the next opacity step.
+func nextOpacity(c color.RGBA) color.RGBA {
+	for i, a := range opacitySteps {
+		if c.A >= a {
+			return withOpacity(c, opacitySteps[(i+1)%len(opacitySteps)])
+		}
+	}
+	return withOpacity(c, opacitySteps[0])
+}
+
+// Opacity in percent, for option labels.
+func opacityPercent(c color.RGBA) int {
+	return (int(c.A)*100 + 127) / 255
+}
+
+// SVG attributes painting with a color: attr is "fill" or "stroke".
+func svgPaint(attr string, c color.RGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf(`%s="%s"`, attr, svgColor(c))
+	}
+	return fmt.Sprintf(`%s="%s" %s-opacity="%.2f"`, attr, svgColor(c), attr, float64(c.A)/255)
+}
+
+// TikZ option for a transparent color, empty when opaque.
+func tikzOpacity(c color.RGBA) string {
+	if c.A == 255 {
+		return ""
+	}
+	return fmt.Sprintf(", opacity=%.2f", float64(c.A)/255)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Algorithm animations:
+
+// Algorithms that show their work (traversals, paths, ...) build an Animation: a list of steps,
+// each one a full snapshot of what's highlighted, so the player can go back and forth freely.
+// Steps are played one per Settings.AnimationStepMs, on top of the normal drawing.
+//
+//	Period / Comma: next / previous step (pauses).
+//	Slash:          play / pause.
+//	Escape:         stop the animation.
+//
+// The export dialog can save every step as step_001.png, step_002.png, ... for slides.
+
+const (
+	animationStepFile = "step_%03d.png"
+	animationLogLine  = 16 // Height of a line of the log panel
+)
+
+type AnimationStep struct {
+	Vertices map[int]color.RGBA // Vertices drawn in another color at this step
+	Edges    map[EdgeKey]bool   // Highlighted edges
+	Labels   map[int]string     // Text shown under vertices (e.g. an elimination ordering)
+	Text     string             // What happens at this step
+}
+
+type Animation struct {
+	Title   string
+	Steps   []AnimationStep
+	Log     []string // Line per step, listed at the side up to the current step (nil for none)
+	Step    int
+	Playing bool
+	shown   time.Time // When the current step came up
+}
+
+// Starts playing an animation from its first step.
+func (app *App) PlayAnimation(a *Animation) {
+	if len(a.Steps) == 0 {
+		return
+	}
+	a.Step, a.Playing, a.shown = 0, true, time.Now()
+	app.Animation = a
+	app.Announce(a.Title)
+}
+
+// Stops the animation, the graph is drawn normally again.
+func (app *App) StopAnimation() {
+	app.Animation = nil
+}
+
+// Current step of the animation, nil if none is running.
+func (app *App) animationStep() *AnimationStep {
+	if app.Animation == nil {
+		return nil
+	}
+	return &app.Animation.Steps[app.Animation.Step]
+}
+
+// Shows step k.
+func (a *Animation) show(k int) {
+	a.Step = max(0, min(k, len(a.Steps)-1))
+	a.shown = time.Now()
+}
+
+// Advances the animation and processes the player keys. Called every frame.
+func (app *App) UpdateAnimation() {
+	a := app.Animation
+	if a == nil {
+		return
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		a.Playing = false
+		a.show(a.Step + 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		a.Playing = false
+		a.show(a.Step - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeySlash):
+		a.Playing = !a.Playing
+		a.shown = time.Now()
+	}
+	if a.Playing && time.Since(a.shown) >= time.Duration(app.Settings.AnimationStepMs)*time.Millisecond {
+		if a.Step == len(a.Steps)-1 {
+			a.Playing = false // Stays on the last step
+			return
+		}
+		a.show(a.Step + 1)
+		app.Announce(a.Steps[a.Step].Text)
+	}
+}
+
+// Draws the step counter and description at the top of the canvas.
+func (app *App) DrawAnimationStatus(screen *ebiten.Image) {
+	a := app.Animation
+	if a == nil {
+		return
+	}
+	text := T("animation.step", a.Title, a.Step+1, len(a.Steps), a.Steps[a.Step].Text)
+	printAt(screen, text, (screenWidth-textWidth(text))/2, int(canvasTop())+22) // Under the title
+}
+
+// Draws the log lines up to the current step in a panel at the right of the canvas, the last
+// ones if they don't all fit.
+func (app *App) DrawAnimationLog(screen *ebiten.Image) {
+	a := app.Animation
+	if a == nil || a.Log == nil {
+		return
+	}
+	lines := a.Log[:a.Step+1]
+	top := canvasTop() + 50 // Under the step description
+	if fit := int((screenHeight-top-20)/animationLogLine) - 1; len(lines) > fit {
+		lines = lines[len(lines)-max(fit, 1):]
+	}
+	w := 0
+	for _, line := range lines {
+		w = max(w, textWidth(line))
+	}
+	x, y := float32(screenWidth-w-20), float32(top)
+	fillRect(screen, x, y, float32(w+10), float32(animationLogLine*len(lines)+6), color.RGBA{0, 0, 0, 200}, true)
+	printAt(screen, strings.Join(lines, "\n"), int(x)+5, int(y)+3)
+}
+
+// Draws the label the current step gives vertex i under it, reports whether there is one.
+func (app *App) drawStepLabel(screen *ebiten.Image, i int) bool {
+	step := app.animationStep()
+	if step == nil {
+		return false
+	}
+	text, ok := step.Labels[i]
+	if ok {
+		v := app.Graph.Vertices[i]
+		printAt(screen, text, int(v.X)-textWidth(text)/2, int(v.Y+app.vertexRadius(i))+1)
+	}
+	return ok
+}
+
+// Saves every step of the animation as a numbered PNG.
+func (app *App) exportAnimationSteps() {
+	a := app.Animation
+	if a == nil {
+		return
+	}
+	step := a.Step
+	defer a.show(step)
+	for k := range a.Steps {
+		a.show(k)
+		path := fmt.Sprintf(animationStepFile, k+1)
+		if err := app.exportPNG(path); err != nil {
+			app.Warn(T("warn.write_file", path, err))
+			return
+		}
+	}
+	app.Notify(T("animation.exported", len(a.Steps)))
+}
@@ -0,0 +1,40 @@
+package main
+
+// Bipartiteness:
+//
+//	Shift+B: check whether the graph is bipartite.
+//
+// If it is, the two sides get two contrasting palette colors (set on the vertices, one undo step, so
+// they stay in exports and saved files) and the message gives their sizes. If it isn't, an odd cycle
+// proves it: it's highlighted until Escape, and listed in the message. Edge directions are ignored.
+
+// Colors the two sides of a bipartite graph, or highlights an odd cycle.
+func (app *App) CheckBipartite() {
+	g := app.Graph
+	if len(g.Vertices) == 0 {
+		return
+	}
+	side, ok := g.Bipartition()
+	if !ok {
+		cycle := g.OddCycle()
+		step := cycleHighlight(cycle, highlightEdgeColor)
+		step.Text = T("bipartite.odd_cycle", len(cycle), g.pathText(append(cycle, cycle[0])))
+		app.PlayAnimation(&Animation{Title: T("bipartite.not"), Steps: []AnimationStep{step}})
+		app.Notify(step.Text)
+		return
+	}
+	var sizes [2]int
+	app.BeginTransaction(T("undo.bipartite"))
+	for i, v := range g.Vertices {
+		sizes[side[i]]++
+		// Slots 0 and 2 are far apart in every palette
+		if c := withOpacity(app.PaletteColor(2*side[i]), v.Color.A); c != v.Color {
+			app.Do(Action{Kind: ActionColorVertex, V1: i, Color: c})
+		}
+	}
+	app.Commit()
+	app.ColorMap = nil // It would hide the colors
+	msg := T("bipartite.sides", sizes[0], sizes[1])
+	app.Notify(msg)
+	app.Announce(msg)
+}
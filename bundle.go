@@ -0,0 +1,220 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Edge bundling:
+
+// For dense graphs: edges running roughly the same way are pulled together into bundles,
+// so the overall structure shows instead of a hairball. Force-directed edge bundling
+// (Holten & van Wijk, 2009): each edge is cut into points, points attract the matching points of
+// compatible edges (similar direction, length and position) while springs keep every edge in one piece.
+// Single edges between two vertices are bundled; loops and parallel edges are drawn as usual.
+//
+// B toggles bundling. Its slider (at the bottom of the canvas) sets the strength:
+// how far the drawn edges move from straight toward their bundled shape.
+// The bundles are recomputed whenever vertices move.
+
+const (
+	bundleCompatibility = 0.6  // Edge pairs less compatible than this don't attract
+	bundleSpring        = 0.1  // Stiffness of the springs along edges
+	bundleCycles        = 5    // Each cycle doubles the points per edge
+	bundleIterations    = 40   // Force steps in the first cycle, fewer later
+	bundleStep          = 0.04 // First step size, times the average edge length
+	bundleSliderLeft    = 150
+	bundleSliderRight   = 350
+	bundleSliderY       = screenHeight - 90 // Above the time slider
+)
+
+type bundleCache struct {
+	key   [2]float64          // Revision and a fingerprint of the positions
+	paths map[EdgeKey][]point // Bundled shape of each edge, ends included
+}
+
+// Fingerprint of the vertex positions, to tell when the bundles are stale.
+func (g *Graph) positionKey() float64 {
+	key := 0.0
+	for i, v := range g.Vertices {
+		key += v.X*float64(2*i+1) + v.Y*float64(2*i+2)
+	}
+	return key
+}
+
+// How much two edges should bundle, 0 to 1: similar angle, similar length, close midpoints.
+func edgeCompatibility(p0, p1, q0, q1 point) float64 {
+	px, py := p1.X-p0.X, p1.Y-p0.Y
+	qx, qy := q1.X-q0.X, q1.Y-q0.Y
+	lp, lq := math.Hypot(px, py), math.Hypot(qx, qy)
+	if lp == 0 || lq == 0 {
+		return 0
+	}
+	angle := math.Abs((px*qx + py*qy) / (lp * lq))
+	avg := (lp + lq) / 2
+	scale := 2 / (avg/math.Min(lp, lq) + math.Max(lp, lq)/avg)
+	mid := math.Hypot((p0.X+p1.X)/2-(q0.X+q1.X)/2, (p0.Y+p1.Y)/2-(q0.Y+q1.Y)/2)
+	position := avg / (avg + mid)
+	return angle * scale * position
+}
+
+// Resamples a polyline to n inner points (n+2 with the ends), evenly along its length.
+func resample(path []point, n int) []point {
+	total := 0.0
+	for k := 1; k < len(path); k++ {
+		total += math.Hypot(path[k].X-path[k-1].X, path[k].Y-path[k-1].Y)
+	}
+	out := []point{path[0]}
+	segment, walked := 1, 0.0
+	for k := 1; k <= n; k++ {
+		target := total * float64(k) / float64(n+1)
+		for segment < len(path)-1 && walked+math.Hypot(path[segment].X-path[segment-1].X, path[segment].Y-path[segment-1].Y) < target {
+			walked += math.Hypot(path[segment].X-path[segment-1].X, path[segment].Y-path[segment-1].Y)
+			segment++
+		}
+		a, b := path[segment-1], path[segment]
+		l := math.Hypot(b.X-a.X, b.Y-a.Y)
+		t := 0.0
+		if l > 0 {
+			t = math.Min(1, (target-walked)/l)
+		}
+		out = append(out, point{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t})
+	}
+	return append(out, path[len(path)-1])
+}
+
+// Computes the bundled shape of every single edge.
+func (g *Graph) BundleEdges() map[EdgeKey][]point {
+	var keys []EdgeKey
+	var paths [][]point
+	total := 0.0
+	for i := range g.Vertices {
+		for j := i + 1; j < len(g.Vertices); j++ {
+			if g.Multiplicity(i, j) == 1 {
+				a, b := g.Vertices[i], g.Vertices[j]
+				keys = append(keys, EdgeKey{i, j})
+				paths = append(paths, []point{{a.X, a.Y}, {b.X, b.Y}})
+				total += math.Hypot(b.X-a.X, b.Y-a.Y)
+			}
+		}
+	}
+	if len(keys) < 2 {
+		return nil
+	}
+
+	// Compatible pairs, with the other edge's direction (its points are matched in reverse if it runs the other way)
+	type partner struct {
+		edge     int
+		reversed bool
+	}
+	partners := make([][]partner, len(keys))
+	for e := range keys {
+		for f := e + 1; f < len(keys); f++ {
+			p, q := paths[e], paths[f]
+			if edgeCompatibility(p[0], p[1], q[0], q[1]) < bundleCompatibility {
+				continue
+			}
+			reversed := (p[1].X-p[0].X)*(q[1].X-q[0].X)+(p[1].Y-p[0].Y)*(q[1].Y-q[0].Y) < 0
+			partners[e] = append(partners[e], partner{f, reversed})
+			partners[f] = append(partners[f], partner{e, reversed})
+		}
+	}
+
+	step := bundleStep * total / float64(len(keys))
+	iterations, points := bundleIterations, 1
+	for range bundleCycles {
+		for e := range paths {
+			paths[e] = resample(paths[e], points)
+		}
+		for range iterations {
+			next := make([][]point, len(paths))
+			for e, p := range paths {
+				next[e] = append([]point{}, p...)
+				length := math.Hypot(p[len(p)-1].X-p[0].X, p[len(p)-1].Y-p[0].Y)
+				k := bundleSpring / (length * float64(points+1))
+				for n := 1; n <= points; n++ {
+					fx := k * (p[n-1].X + p[n+1].X - 2*p[n].X)
+					fy := k * (p[n-1].Y + p[n+1].Y - 2*p[n].Y)
+					for _, o := range partners[e] {
+						m := n
+						if o.reversed {
+							m = points + 1 - n
+						}
+						q := paths[o.edge][m]
+						dx, dy := q.X-p[n].X, q.Y-p[n].Y
+						if d := math.Hypot(dx, dy); d > 1e-6 {
+							fx += dx / d
+							fy += dy / d
+						}
+					}
+					next[e][n] = point{p[n].X + step*fx, p[n].Y + step*fy}
+				}
+			}
+			paths = next
+		}
+		points *= 2
+		step /= 2
+		iterations = iterations * 2 / 3
+	}
+
+	bundled := make(map[EdgeKey][]point, len(keys))
+	for e, k := range keys {
+		bundled[k] = paths[e]
+	}
+	return bundled
+}
+
+// Bundled shape of the edge between i and j as drawn (strength applied), nil if it isn't bundled.
+func (app *App) bundledEdge(i, j int) []point {
+	if !app.View.BundleEdges {
+		return nil
+	}
+	key := [2]float64{float64(app.revision), app.Graph.positionKey()}
+	if app.bundles.paths == nil || app.bundles.key != key {
+		app.bundles = bundleCache{key: key, paths: app.Graph.BundleEdges()}
+	}
+	path := app.bundles.paths[Edge(i, j)]
+	if path == nil {
+		return nil
+	}
+	a, b := path[0], path[len(path)-1]
+	s := app.Settings.BundleStrength
+	drawn := make([]point, len(path))
+	for n, p := range path {
+		t := float64(n) / float64(len(path)-1)
+		straight := point{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+		drawn[n] = point{straight.X + (p.X-straight.X)*s, straight.Y + (p.Y-straight.Y)*s}
+	}
+	return drawn
+}
+
+// Toggles edge bundling.
+func (app *App) ToggleBundling() {
+	app.toggleView(&app.View.BundleEdges, "view.bundle_edges")
+}
+
+// Handles the strength slider. Returns true while it has the mouse.
+func (app *App) UpdateBundleSlider(x, y float64) bool {
+	if !app.View.BundleEdges {
+		return false
+	}
+	f, active, done := app.bundleSlider.Update(x, y)
+	if !active {
+		return false
+	}
+	app.Settings.BundleStrength = f
+	if done {
+		app.saveOption()
+	}
+	return true
+}
+
+// Draws the strength slider.
+func (app *App) DrawBundleSlider(screen *ebiten.Image) {
+	if !app.View.BundleEdges {
+		return
+	}
+	s := app.Settings.BundleStrength
+	app.bundleSlider.Draw(screen, s, T("bundle.strength", int(math.Round(100*s))), "0", "100")
+}
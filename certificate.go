@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Certificates:
+
+// Yes/no answers come with their evidence: the two-coloring or an odd cycle for bipartiteness,
+// a spanning tree or two unconnected vertices for connectivity, the circuit itself (or the odd vertices)
+// for Eulerian circuits, a perfect elimination ordering (numbered on the vertices, with the largest clique
+// and a coloring using that many colors) or a chordless cycle for chordality, a subdivision of K5 or K3,3
+// for a graph that isn't planar (planarize.go). F4 asks a question; the evidence is highlighted on the canvas
+// (Escape clears it) and can be copied or saved as text.
+
+const certificateFile = "certificate.txt"
+
+type Certificate struct {
+	Question  string
+	Answer    bool
+	Evidence  []string // Lines of text explaining the answer
+	Highlight AnimationStep
+}
+
+// Labels of a vertex sequence, "A - B - C".
+func (g *Graph) pathText(path []int) string {
+	labels := make([]string, len(path))
+	for i, v := range path {
+		labels[i] = g.Vertices[v].Label
+	}
+	return strings.Join(labels, " - ")
+}
+
+// Highlight of a closed vertex sequence.
+func cycleHighlight(cycle []int, clr color.RGBA) AnimationStep {
+	step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: map[EdgeKey]bool{}}
+	for k, v := range cycle {
+		step.Vertices[v] = clr
+		step.Edges[Edge(v, cycle[(k+1)%len(cycle)])] = true
+	}
+	return step
+}
+
+// Is the graph bipartite? Evidence: the two sides, or an odd cycle.
+func (app *App) certifyBipartite() *Certificate {
+	g := app.Graph
+	c := &Certificate{Question: T("quiz.bipartite")}
+	if side, ok := g.Bipartition(); ok {
+		c.Answer = true
+		c.Highlight = AnimationStep{Vertices: map[int]color.RGBA{}}
+		var sides [2][]string
+		for i, s := range side {
+			c.Highlight.Vertices[i] = app.PaletteColor(2 * s) // Slots 0 and 2 are far apart in every palette
+			sides[s] = append(sides[s], g.Vertices[i].Label)
+		}
+		c.Evidence = []string{T("certificate.side", 1, strings.Join(sides[0], ", ")), T("certificate.side", 2, strings.Join(sides[1], ", "))}
+		return c
+	}
+	cycle := g.OddCycle()
+	c.Highlight = cycleHighlight(cycle, highlightEdgeColor)
+	c.Evidence = []string{T("certificate.odd_cycle", len(cycle), g.pathText(append(cycle, cycle[0])))}
+	return c
+}
+
+// Is the graph connected? Evidence: a spanning tree, or two vertices without a path between them.
+func (app *App) certifyConnected() *Certificate {
+	g := app.Graph
+	c := &Certificate{Question: T("quiz.connected"), Answer: g.IsConnected()}
+	if c.Answer {
+		c.Highlight = AnimationStep{Edges: map[EdgeKey]bool{}}
+		var edges []string
+		for _, e := range g.SpanningForest() {
+			c.Highlight.Edges[Edge(e[0], e[1])] = true
+			edges = append(edges, g.pathText(e[:]))
+		}
+		c.Evidence = []string{T("certificate.spanning_tree", strings.Join(edges, ", "))}
+		return c
+	}
+	component := g.Components()
+	for i := range g.Vertices {
+		if component[i] != 0 {
+			c.Highlight = AnimationStep{Vertices: map[int]color.RGBA{0: highlightEdgeColor, i: highlightEdgeColor}}
+			c.Evidence = []string{T("certificate.unreachable", g.Vertices[0].Label, g.Vertices[i].Label, g.ComponentCount())}
+			break
+		}
+	}
+	return c
+}
+
+// Does the graph have an Eulerian circuit? Evidence: the circuit, or a vertex of odd degree (in directed
+// mode, with in-degree and out-degree apart), or a second component with edges.
+func (app *App) certifyEulerian() *Certificate {
+	g := app.Graph
+	c := &Certificate{Question: T("quiz.eulerian"), Answer: g.IsEulerian()}
+	if c.Answer {
+		if circuit := g.EulerianCircuit(); circuit != nil {
+			c.Highlight = cycleHighlight(circuit[:len(circuit)-1], highlightEdgeColor)
+			c.Evidence = []string{T("certificate.circuit", g.pathText(circuit))}
+		} else {
+			c.Evidence = []string{T("certificate.no_edges")} // The empty walk does it
+		}
+		return c
+	}
+	var odd []string
+	c.Highlight = AnimationStep{Vertices: map[int]color.RGBA{}}
+	for _, i := range g.eulerianUnbalanced() {
+		odd = append(odd, g.Vertices[i].Label)
+		c.Highlight.Vertices[i] = highlightEdgeColor
+	}
+	switch {
+	case len(odd) > 0 && g.Directed:
+		c.Evidence = []string{T("certificate.unbalanced", strings.Join(odd, ", "))}
+	case len(odd) > 0:
+		c.Evidence = []string{T("certificate.odd_degree", strings.Join(odd, ", "))}
+	default:
+		c.Evidence = []string{T("certificate.edges_apart")}
+	}
+	return c
+}
+
+// Is the graph chordal? Evidence: a perfect elimination ordering, or a cycle without chords.
+// The ordering also gives the largest clique and an optimal coloring, shown with it.
+func (app *App) certifyChordal() *Certificate {
+	g := app.Graph
+	c := &Certificate{Question: T("certificate.chordal_question")}
+	peo, ok := g.PerfectEliminationOrder()
+	if !ok {
+		cycle := g.ChordlessCycle()
+		c.Highlight = cycleHighlight(cycle, highlightEdgeColor)
+		c.Evidence = []string{T("certificate.chordless_cycle", len(cycle), g.pathText(append(cycle, cycle[0])))}
+		return c
+	}
+	c.Answer = true
+	c.Highlight = AnimationStep{Vertices: map[int]color.RGBA{}, Labels: map[int]string{}, Edges: map[EdgeKey]bool{}}
+	for i, v := range peo {
+		c.Highlight.Labels[v] = strconv.Itoa(i + 1)
+	}
+	var largest []int
+	for _, clique := range g.chordalCliques(peo) {
+		if len(clique) > len(largest) {
+			largest = clique
+		}
+	}
+	for a, v := range largest {
+		for _, w := range largest[a+1:] {
+			c.Highlight.Edges[Edge(v, w)] = true
+		}
+	}
+	k, coloring := g.chordalColoring(peo)
+	for v, slot := range coloring {
+		c.Highlight.Vertices[v] = app.PaletteColor(slot)
+	}
+	c.Evidence = []string{
+		T("certificate.elimination_order", g.pathText(peo)),
+		T("certificate.largest_clique", g.pathText(largest)),
+		T("certificate.chordal_coloring", k),
+	}
+	return c
+}
+
+// Writes a certificate as text.
+func (c *Certificate) write(buf *bytes.Buffer) {
+	fmt.Fprintln(buf, c.Question)
+	fmt.Fprintln(buf, yesNo(c.Answer))
+	for _, line := range c.Evidence {
+		fmt.Fprintln(buf, line)
+	}
+}
+
+// Highlights a certificate on the canvas, as a one-step animation.
+func (app *App) HighlightCertificate(c *Certificate) {
+	step := c.Highlight
+	step.Text = yesNo(c.Answer)
+	app.PlayAnimation(&Animation{Title: c.Question, Steps: []AnimationStep{step}})
+}
+
+// Highlights a certificate and offers to keep its text.
+func (app *App) ShowCertificate(c *Certificate) {
+	app.HighlightCertificate(c)
+
+	var buf bytes.Buffer
+	c.write(&buf)
+	app.ShowDialog(&Dialog{
+		Message: c.Question + " " + yesNo(c.Answer) + ". " + strings.Join(c.Evidence, " "),
+		Buttons: []DialogButton{
+			{Label: T("info.clipboard"), Action: func() {
+				if err := writeClipboard(buf.String()); err != nil {
+					app.Warn(T("warn.clipboard", err))
+					return
+				}
+				app.Notify(T("certificate.copied"))
+			}},
+			{Label: T("info.text_file"), Action: func() {
+				app.saveFile(certificateFile, func(path string) {
+					if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+						app.Warn(T("warn.write_file", path, err))
+						return
+					}
+					app.Notify(T("info.saved", path))
+				})
+			}},
+			{Label: T("dialog.ok")},
+		},
+	})
+}
+
+// Is the graph planar? Evidence: a subdivision of K5 or K3,3, or a drawing without crossings (Shift+P).
+func (app *App) certifyPlanar() *Certificate {
+	g := app.Graph
+	c := &Certificate{Question: T("certificate.planar_question")}
+	edges, branch := g.Kuratowski()
+	if edges == nil {
+		c.Answer = true
+		c.Evidence = []string{T("certificate.planar_drawing")}
+		return c
+	}
+	c.Highlight = app.kuratowskiHighlight(edges, branch)
+	paths := make([]string, 0, len(edges))
+	for _, e := range edges {
+		paths = append(paths, g.pathText([]int{e.A, e.B}))
+	}
+	c.Evidence = []string{c.Highlight.Text, T("certificate.subdivision_edges", strings.Join(paths, ", "))}
+	return c
+}
+
+// Asks which question to answer with a certificate.
+func (app *App) ShowCertifyDialog() {
+	if len(app.Graph.Vertices) == 0 {
+		return
+	}
+	app.ShowDialog(&Dialog{
+		Message: T("certificate.dialog"),
+		Buttons: []DialogButton{
+			{Label: T("certificate.bipartite"), Action: func() { app.ShowCertificate(app.certifyBipartite()) }},
+			{Label: T("certificate.connected"), Action: func() { app.ShowCertificate(app.certifyConnected()) }},
+			{Label: T("certificate.eulerian"), Action: func() { app.ShowCertificate(app.certifyEulerian()) }},
+			{Label: T("certificate.chordal"), Action: func() { app.ShowCertificate(app.certifyChordal()) }},
+			{Label: T("certificate.planar"), Action: func() { app.ShowCertificate(app.certifyPlanar()) }},
+			{Label: T("dialog.cancel")},
+		},
+	})
+}
@@ -0,0 +1,201 @@
+package main
+
+// Chordal graphs:
+
+// Lexicographic breadth-first search visits the vertices so that, on a chordal graph, the reverse order
+// is a perfect elimination ordering: every vertex's later neighbors form a clique. Checking that
+// ordering tells chordal graphs apart, the cliques it gives are all the maximal cliques, and coloring
+// greedily along it backwards uses no more colors than the largest clique has vertices: all in linear time.
+// Loops and parallel edges are ignored, chordality is about the simple graph underneath.
+
+// Reports whether i and j are distinct and adjacent, whichever way the edges go.
+func (g *Graph) adjacent(i, j int) bool {
+	return i != j && g.Multiplicity(i, j) > 0
+}
+
+// Returns the vertices in lexicographic breadth-first search order.
+// Partition refinement: the vertices not visited yet are kept in groups, best label first, and visiting
+// a vertex splits every group into its neighbors, then the rest.
+func (g *Graph) LBFS() []int {
+	n := len(g.Vertices)
+	groups := [][]int{make([]int, n)}
+	for i := range groups[0] {
+		groups[0][i] = i
+	}
+	if n == 0 {
+		return nil
+	}
+	order := make([]int, 0, n)
+	for len(groups) > 0 {
+		v := groups[0][0]
+		groups[0] = groups[0][1:]
+		order = append(order, v)
+
+		var next [][]int
+		for _, group := range groups {
+			var in, out []int
+			for _, w := range group {
+				if g.adjacent(v, w) {
+					in = append(in, w)
+				} else {
+					out = append(out, w)
+				}
+			}
+			for _, part := range [][]int{in, out} {
+				if len(part) > 0 {
+					next = append(next, part)
+				}
+			}
+		}
+		groups = next
+	}
+	return order
+}
+
+// Returns the neighbors of v coming after it in an ordering (pos gives the places).
+func (g *Graph) laterNeighbors(v int, pos []int) []int {
+	var later []int
+	for w := range g.Vertices {
+		if g.adjacent(v, w) && pos[w] > pos[v] {
+			later = append(later, w)
+		}
+	}
+	return later
+}
+
+// Returns a perfect elimination ordering (reverse LBFS), and whether it is one, i.e. the graph is chordal.
+func (g *Graph) PerfectEliminationOrder() ([]int, bool) {
+	lbfs := g.LBFS()
+	n := len(lbfs)
+	peo, pos := make([]int, n), make([]int, n)
+	for i, v := range lbfs {
+		peo[n-1-i] = v
+		pos[v] = n - 1 - i
+	}
+	// Enough to check that each vertex's first later neighbor is adjacent to the other later ones
+	for _, v := range peo {
+		later := g.laterNeighbors(v, pos)
+		if len(later) == 0 {
+			continue
+		}
+		first := later[0]
+		for _, w := range later {
+			if pos[w] < pos[first] {
+				first = w
+			}
+		}
+		for _, w := range later {
+			if w != first && !g.adjacent(first, w) {
+				return peo, false
+			}
+		}
+	}
+	return peo, true
+}
+
+// Returns the maximal cliques of a chordal graph, given a perfect elimination ordering:
+// each vertex with its later neighbors, unless that's inside another such clique.
+func (g *Graph) chordalCliques(peo []int) [][]int {
+	pos := make([]int, len(peo))
+	for i, v := range peo {
+		pos[v] = i
+	}
+	candidates := make([][]int, len(peo))
+	in := make([][]bool, len(peo)) // in[i][w]: w belongs to candidate i
+	for i, v := range peo {
+		candidates[i] = append([]int{v}, g.laterNeighbors(v, pos)...)
+		in[i] = make([]bool, len(peo))
+		for _, w := range candidates[i] {
+			in[i][w] = true
+		}
+	}
+	var cliques [][]int
+	for i, c := range candidates {
+		maximal := true
+		for j := range i { // Only an earlier vertex's clique can hold this one
+			if len(candidates[j]) > len(c) && subset(c, in[j]) {
+				maximal = false
+				break
+			}
+		}
+		if maximal {
+			cliques = append(cliques, c)
+		}
+	}
+	return cliques
+}
+
+// Reports whether every element of s is in the set.
+func subset(s []int, set []bool) bool {
+	for _, x := range s {
+		if !set[x] {
+			return false
+		}
+	}
+	return true
+}
+
+// Colors a chordal graph with as few colors as possible, given a perfect elimination ordering:
+// greedily from the end, each vertex's colored neighbors are its later ones, a clique, so the
+// largest clique's size is enough. Returns the number of colors and the coloring.
+func (g *Graph) chordalColoring(peo []int) (int, []int) {
+	coloring := make([]int, len(peo))
+	k := 0
+	for i := len(peo) - 1; i >= 0; i-- {
+		v := peo[i]
+		taken := map[int]bool{}
+		for _, w := range peo[i+1:] {
+			if g.adjacent(v, w) {
+				taken[coloring[w]] = true
+			}
+		}
+		c := 0
+		for taken[c] {
+			c++
+		}
+		coloring[v] = c
+		k = max(k, c+1)
+	}
+	return k, coloring
+}
+
+// Returns a cycle of 4 or more vertices without chords, nil if the graph is chordal.
+// Such a cycle goes through some v and two of its neighbors a and b that aren't adjacent,
+// and comes back from b to a avoiding v's other neighbors: a shortest such path closes it.
+func (g *Graph) ChordlessCycle() []int {
+	n := len(g.Vertices)
+	for v := range n {
+		for a := range n {
+			for b := a + 1; b < n; b++ {
+				if !g.adjacent(v, a) || !g.adjacent(v, b) || g.adjacent(a, b) {
+					continue
+				}
+				parent := make([]int, n)
+				for i := range parent {
+					parent[i] = -1
+				}
+				parent[b] = b
+				queue := []int{b}
+				for len(queue) > 0 && parent[a] < 0 {
+					u := queue[0]
+					queue = queue[1:]
+					for w := range n {
+						if parent[w] < 0 && g.adjacent(u, w) && w != v && (w == a || !g.adjacent(v, w)) {
+							parent[w] = u
+							queue = append(queue, w)
+						}
+					}
+				}
+				if parent[a] < 0 {
+					continue
+				}
+				cycle := []int{v}
+				for u := a; u != b; u = parent[u] {
+					cycle = append(cycle, u)
+				}
+				return append(cycle, b)
+			}
+		}
+	}
+	return nil
+}
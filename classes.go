@@ -0,0 +1,90 @@
+package main
+
+import "strings"
+
+// Graph classes:
+
+// The standard classes the graph belongs to, listed with the graph info.
+// Each class is a test on the graph; new classes just need an entry in graphClasses.
+// Planarity, chordality, interval and permutation recognition live in their own files.
+
+type GraphClass struct {
+	Name string // Locale key
+	Test func(g *Graph) bool
+}
+
+var graphClasses = []GraphClass{
+	{Name: "class.tree", Test: func(g *Graph) bool {
+		return g.IsConnected() && g.IsSimple() && g.EdgeCount() == len(g.Vertices)-1
+	}},
+	{Name: "class.forest", Test: func(g *Graph) bool {
+		return g.IsSimple() && g.EdgeCount() == len(g.Vertices)-g.ComponentCount()
+	}},
+	{Name: "class.cycle", Test: func(g *Graph) bool {
+		if len(g.Vertices) < 3 || !g.IsSimple() || !g.IsConnected() {
+			return false
+		}
+		for i := range g.Vertices {
+			if g.Degree(i) != 2 {
+				return false
+			}
+		}
+		return true
+	}},
+	{Name: "class.complete", Test: func(g *Graph) bool {
+		n := len(g.Vertices)
+		return g.IsSimple() && g.EdgeCount() == n*(n-1)/2
+	}},
+	{Name: "class.bipartite", Test: func(g *Graph) bool {
+		_, ok := g.Bipartition()
+		return ok
+	}},
+	{Name: "class.regular", Test: func(g *Graph) bool {
+		degree := func(i int) int { return g.Degree(i) + g.AdjMatrix[i][i] } // A loop adds two to the degree
+		for i := range g.Vertices {
+			if degree(i) != degree(0) {
+				return false
+			}
+		}
+		return true
+	}},
+	{Name: "class.planar", Test: (*Graph).IsPlanar},
+	{Name: "class.chordal", Test: func(g *Graph) bool {
+		_, ok := g.PerfectEliminationOrder()
+		return ok
+	}},
+	{Name: "class.interval", Test: func(g *Graph) bool {
+		_, ok := g.IntervalCliqueOrder()
+		return ok
+	}},
+	{Name: "class.permutation", Test: func(g *Graph) bool {
+		_, _, ok := g.PermutationModel()
+		return ok
+	}},
+}
+
+// Returns the names of the classes the graph belongs to (none for the empty graph).
+func (g *Graph) Classes() []string {
+	if len(g.Vertices) == 0 {
+		return nil
+	}
+	if g.Directed {
+		g = g.underlying()
+	}
+	var names []string
+	for _, c := range graphClasses {
+		if c.Test(g) {
+			names = append(names, T(c.Name))
+		}
+	}
+	return names
+}
+
+// Returns the classes as one line, for the graph info.
+func (g *Graph) classSummary() string {
+	names := g.Classes()
+	if len(names) == 0 {
+		return T("info.classes", T("info.no_classes"))
+	}
+	return T("info.classes", strings.Join(names, ", "))
+}
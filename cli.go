@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Command line:
+
+// Besides opening a file, graph-tool runs commands without a window:
+//
+//	graph-tool metrics [-m degree,core,...] FILE
+//
+// writes the vertex metrics of a graph file (any format Ctrl+O opens, sessions included) as CSV to
+// standard output; -m picks the metrics, by their column names (all of them by default).
+//
+//	graph-tool analyze [-out DIR] [-m ...] [-workers N] FILE|PATTERN...
+//
+// does the same for many files at once, in parallel: for each one NAME-metrics.csv and NAME-info.txt
+// (what Print Info writes) go into DIR. Patterns (graphs/*.json) are expanded here too, for shells
+// that don't. Flags may come after the files. Two files with the same NAME (a/g.json and b/g.json,
+// g.json and g.gml) would overwrite each other's results, so the command refuses them before starting.
+// A file that fails doesn't stop the others; the command fails at the end if any did.
+
+var (
+	errUnknownMetric = errors.New("unknown metric")
+	errNoFiles       = errors.New("no files to analyze")
+	errAnalyze       = errors.New("some files failed")
+	errSameName      = errors.New("same result names")
+)
+
+// Headless commands by name.
+var commands = map[string]func(args []string, out io.Writer) error{
+	"metrics": metricsCommand,
+	"analyze": analyzeCommand,
+}
+
+// Parses flags wherever they are among the arguments, returns the others.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// Parses -m into metrics.
+func parseMetricFlag(names string) ([]Metric, error) {
+	var list []string
+	if names != "" {
+		list = strings.Split(names, ",")
+	}
+	return metricsNamed(list)
+}
+
+// Reads a graph file like Ctrl+O would, without asking anything.
+func loadGraphFile(path string) (*Graph, error) {
+	if strings.ToLower(filepath.Ext(path)) == ".gts" {
+		s, err := readSession(path)
+		if err != nil {
+			return nil, err
+		}
+		return s.Graph, nil
+	}
+	ig, err := readGraphFile(path)
+	if err != nil {
+		return nil, err
+	}
+	app := NewApp()
+	app.loadImported(ig)
+	return app.Graph, nil
+}
+
+// graph-tool metrics [-m names] FILE
+func metricsCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	names := fs.String("m", "", "metrics to write, comma separated (all by default)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: graph-tool metrics [-m degree,core,...] FILE")
+	}
+	ms, err := parseMetricFlag(*names)
+	if err != nil {
+		return err
+	}
+	g, err := loadGraphFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeMetricsCSV(out, g, ms)
+}
+
+// graph-tool analyze [-out DIR] [-m names] [-workers N] FILE|PATTERN...
+func analyzeCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	dir := fs.String("out", ".", "directory for the results")
+	names := fs.String("m", "", "metrics to write, comma separated (all by default)")
+	workers := fs.Int("workers", runtime.NumCPU(), "files analyzed at the same time")
+	patterns, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	ms, err := parseMetricFlag(*names)
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		if matches == nil {
+			matches = []string{pattern} // Not a pattern, or matches nothing: fails when opened
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return errNoFiles
+	}
+	paths, err = uniqueResultNames(paths)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex // Guards out and failed
+	failed := 0
+	var wg sync.WaitGroup
+	for range max(1, *workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				err := analyzeFile(path, *dir, ms)
+				mu.Lock()
+				if err != nil {
+					failed++
+					fmt.Fprintf(out, "%s: %v\n", path, err)
+				} else {
+					fmt.Fprintf(out, "%s: ok\n", path)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errAnalyze, failed, len(paths))
+	}
+	return nil
+}
+
+// The NAME of the results of a graph file: its base name without the extension.
+func resultName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// Drops files listed twice, and fails if two different files would write results of the same name.
+// Names are compared ignoring case, as some file systems do.
+func uniqueResultNames(paths []string) ([]string, error) {
+	var unique []string
+	seen := map[string]bool{}
+	byName := map[string]string{}
+	for _, path := range paths {
+		clean := filepath.Clean(path)
+		if seen[clean] {
+			continue
+		}
+		seen[clean] = true
+		name := strings.ToLower(resultName(clean))
+		if other, ok := byName[name]; ok {
+			return nil, fmt.Errorf("%w: %s and %s would both write %s-metrics.csv", errSameName, other, path, resultName(clean))
+		}
+		byName[name] = path
+		unique = append(unique, path)
+	}
+	return unique, nil
+}
+
+// Writes the metrics and the info of one graph file into dir.
+func analyzeFile(path, dir string, ms []Metric) error {
+	g, err := loadGraphFile(path)
+	if err != nil {
+		return err
+	}
+	name := resultName(path)
+	var metricsCSV, info bytes.Buffer
+	if err := writeMetricsCSV(&metricsCSV, g, ms); err != nil {
+		return err
+	}
+	writeGraphInfo(&info, g)
+	if err := os.WriteFile(filepath.Join(dir, name+"-metrics.csv"), metricsCSV.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+"-info.txt"), info.Bytes(), 0o644)
+}
+
+// Runs the command named by the first argument, if it is one. Reports whether it was.
+func runCommand(args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return false, nil
+	}
+	return true, command(args[1:], os.Stdout)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// System clipboard:
+
+// Ebiten has no clipboard API, so this goes through the usual command line tools of each platform.
+
+var errNoClipboard = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+// Commands that copy their stdin to the clipboard, in order of preference.
+func clipboardWriteCommands() [][]string {
+	switch runtime.GOOS {
+	case "windows":
+		return [][]string{{"clip"}}
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	default:
+		return [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+}
+
+// Commands that print the clipboard, in order of preference.
+func clipboardReadCommands() [][]string {
+	switch runtime.GOOS {
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}}
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	default:
+		return [][]string{{"wl-paste", "--no-newline"}, {"xclip", "-selection", "clipboard", "-o"}, {"xsel", "--clipboard", "--output"}}
+	}
+}
+
+// Returns the text on the system clipboard.
+func readClipboard() (string, error) {
+	for _, args := range clipboardReadCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		return string(out), err
+	}
+	return "", errNoClipboard
+}
+
+// Puts text on the system clipboard.
+func writeClipboard(text string) error {
+	for _, args := range clipboardWriteCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errNoClipboard
+}
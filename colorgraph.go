@@ -0,0 +1,174 @@
+package main
+
+import (
+	"slices"
+)
+
+// Coloring the graph:
+//
+//	Shift+K: color the graph, greedily or exactly.
+//
+// Greedy coloring is DSatur: the next vertex colored is the one whose neighbors already have the most
+// different colors (ties to the highest degree), given the lowest color none of them has. It's fast and
+// usually close, but not always optimal. The exact coloring is a branch and bound over the same order,
+// starting from the greedy coloring and only looking for colorings with fewer colors, so the number it
+// ends with is the chromatic number; it's offered up to exactColoringMaxVertices vertices. Either way
+// the colors are set on the vertices (one undo step), palette slot by slot, and coloring mode (K)
+// checks the result. Edge directions are ignored; a graph with a loop can't be colored.
+
+const exactColoringMaxVertices = 40
+
+// Returns the neighbors of every vertex, whichever way the edges go, loops and parallel edges left out.
+func (g *Graph) neighborLists() [][]int {
+	n := len(g.Vertices)
+	adj := make([][]int, n)
+	for i := range n {
+		for j := range n {
+			if i != j && g.Multiplicity(i, j) > 0 {
+				adj[i] = append(adj[i], j)
+			}
+		}
+	}
+	return adj
+}
+
+// Reports whether some vertex has a loop.
+func (g *Graph) hasLoop() bool {
+	for i := range g.Vertices {
+		if g.AdjMatrix[i][i] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Picks the uncolored vertex (coloring -1) whose neighbors have the most different colors, ties to the
+// most neighbors, then the lowest index. Returns -1 when every vertex is colored.
+func dsaturNext(adj [][]int, coloring []int) int {
+	best, bestSat := -1, -1
+	seen := map[int]bool{}
+	for v, c := range coloring {
+		if c >= 0 {
+			continue
+		}
+		clear(seen)
+		for _, w := range adj[v] {
+			if coloring[w] >= 0 {
+				seen[coloring[w]] = true
+			}
+		}
+		if sat := len(seen); sat > bestSat || (sat == bestSat && len(adj[v]) > len(adj[best])) {
+			best, bestSat = v, sat
+		}
+	}
+	return best
+}
+
+// Colors the graph greedily in DSatur order. Returns the number of colors and the color of each vertex.
+func dsaturColoring(adj [][]int) (int, []int) {
+	coloring := make([]int, len(adj))
+	for i := range coloring {
+		coloring[i] = -1
+	}
+	k := 0
+	for v := dsaturNext(adj, coloring); v >= 0; v = dsaturNext(adj, coloring) {
+		taken := map[int]bool{}
+		for _, w := range adj[v] {
+			taken[coloring[w]] = true
+		}
+		c := 0
+		for taken[c] {
+			c++
+		}
+		coloring[v] = c
+		k = max(k, c+1)
+	}
+	return k, coloring
+}
+
+// Colors the graph greedily (DSatur). ok is false if it has a loop.
+func (g *Graph) GreedyColoring() (k int, coloring []int, ok bool) {
+	if g.hasLoop() {
+		return 0, nil, false
+	}
+	k, coloring = dsaturColoring(g.neighborLists())
+	return k, coloring, true
+}
+
+// Colors the graph with as few colors as possible: branch and bound in DSatur order, starting from
+// the greedy coloring. Exponential in the worst case.
+func (g *Graph) exactColoring() (int, []int) {
+	adj := g.neighborLists()
+	bestK, best := dsaturColoring(adj)
+	coloring := make([]int, len(adj))
+	for i := range coloring {
+		coloring[i] = -1
+	}
+	var search func(colored, k int)
+	search = func(colored, k int) {
+		if colored == len(adj) {
+			bestK, best = k, slices.Clone(coloring)
+			return
+		}
+		v := dsaturNext(adj, coloring)
+		// Colors already used, or one new one, as long as that stays below the best found
+		for c := 0; c <= k && c+1 < bestK; c++ {
+			if slices.ContainsFunc(adj[v], func(w int) bool { return coloring[w] == c }) {
+				continue
+			}
+			coloring[v] = c
+			search(colored+1, max(k, c+1))
+			coloring[v] = -1
+		}
+	}
+	search(0, 0)
+	return bestK, best
+}
+
+// Asks how to color the graph.
+func (app *App) ShowColorGraphDialog() {
+	g := app.Graph
+	if len(g.Vertices) == 0 {
+		return
+	}
+	if g.hasLoop() {
+		app.Warn(T("colorgraph.loop"))
+		return
+	}
+	buttons := []DialogButton{{Label: T("colorgraph.greedy"), Action: func() { app.ColorGraph(false) }}}
+	if len(g.Vertices) <= exactColoringMaxVertices {
+		buttons = append(buttons, DialogButton{Label: T("colorgraph.exact"), Action: func() { app.ColorGraph(true) }})
+	}
+	buttons = append(buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(&Dialog{Message: T("colorgraph.dialog", exactColoringMaxVertices), Buttons: buttons})
+}
+
+// Colors the vertices, exactly or greedily, and reports the number of colors.
+func (app *App) ColorGraph(exact bool) {
+	g := app.Graph
+	var k int
+	var coloring []int
+	if exact {
+		k, coloring = g.exactColoring()
+	} else {
+		k, coloring, _ = g.GreedyColoring()
+	}
+	app.BeginTransaction(T("undo.color_graph"))
+	for i, v := range g.Vertices {
+		if c := withOpacity(app.PaletteColor(coloring[i]), v.Color.A); c != v.Color {
+			app.Do(Action{Kind: ActionColorVertex, V1: i, Color: c})
+		}
+	}
+	app.Commit()
+	app.ColorMap = nil // It would hide the colors
+
+	msg := T("colorgraph.greedy_done", k)
+	if exact {
+		msg = T("colorgraph.exact_done", k)
+	}
+	if k > len(app.Palette().Colors) {
+		msg += " " + T("components.colors_reused", len(app.Palette().Colors))
+	}
+	app.Notify(msg)
+	app.Announce(msg)
+}
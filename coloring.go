@@ -0,0 +1,108 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Coloring mode:
+
+// For coloring by hand: every vertex color is a class (whatever its opacity), numbered by its palette slot (1, 2, ...)
+// and written under the vertex. Edges whose ends share a class are flagged as they happen
+// and a panel at the top left says whether the coloring is proper and how many classes it uses.
+// The Color Vertex tool moves a vertex to the next class, as usual.
+//
+//	K: toggle coloring mode (also in the Color Vertex tool's option bar).
+
+const maxListedConflicts = 4 // Conflicting edges named in the panel, the rest are counted
+
+var conflictColor = color.RGBA{255, 140, 0, 255}
+
+// Returns the edges (loops included) whose ends have the same color, in order.
+func (g *Graph) ColoringConflicts() []EdgeKey {
+	var conflicts []EdgeKey
+	for i := range g.Vertices {
+		for j := i; j < len(g.Vertices); j++ {
+			if g.Multiplicity(i, j) > 0 && opaque(g.Vertices[i].Color) == opaque(g.Vertices[j].Color) {
+				conflicts = append(conflicts, Edge(i, j))
+			}
+		}
+	}
+	return conflicts
+}
+
+// Returns the number of colors in use.
+func (g *Graph) ColorClassCount() int {
+	classes := map[color.RGBA]bool{}
+	for _, v := range g.Vertices {
+		classes[opaque(v.Color)] = true
+	}
+	return len(classes)
+}
+
+// Class number of vertex i, "?" for colors outside the palette.
+func (app *App) colorClassLabel(i int) string {
+	slot := app.paletteIndex(app.Graph.Vertices[i].Color)
+	if slot < 0 {
+		return "?"
+	}
+	return strconv.Itoa(slot + 1)
+}
+
+// Reports whether coloring mode flags the edges between i and j.
+func (app *App) conflicting(i, j int) bool {
+	return app.View.ColoringMode && opaque(app.Graph.Vertices[i].Color) == opaque(app.Graph.Vertices[j].Color)
+}
+
+// Draws the class number under vertex i.
+func (app *App) drawColorClass(screen *ebiten.Image, i int) {
+	v := app.Graph.Vertices[i]
+	text := app.colorClassLabel(i)
+	printAt(screen, text, int(v.X)-textWidth(text)/2, int(v.Y+app.vertexRadius(i))+1)
+}
+
+// Describes the coloring: proper or not, and the conflicting edges.
+func (app *App) coloringStatus() (text string, proper bool) {
+	g := app.Graph
+	conflicts := g.ColoringConflicts()
+	if len(conflicts) == 0 {
+		return T("coloring.proper", g.ColorClassCount()), true
+	}
+	var edges []string
+	for _, e := range conflicts[:min(len(conflicts), maxListedConflicts)] {
+		edges = append(edges, g.Vertices[e.A].Label+"-"+g.Vertices[e.B].Label)
+	}
+	if len(conflicts) > maxListedConflicts {
+		edges = append(edges, T("coloring.more", len(conflicts)-maxListedConflicts))
+	}
+	return T("coloring.conflicts", len(conflicts), strings.Join(edges, ", ")), false
+}
+
+// Toggles coloring mode.
+func (app *App) ToggleColoringMode() {
+	app.toggleView(&app.View.ColoringMode, "view.coloring_mode")
+	if app.View.ColoringMode && len(app.Graph.Vertices) > 0 {
+		text, _ := app.coloringStatus()
+		app.Announce(text)
+	}
+}
+
+// Draws the validity panel at the top left of the canvas.
+func (app *App) DrawColoringStatus(screen *ebiten.Image) {
+	if !app.View.ColoringMode || len(app.Graph.Vertices) == 0 {
+		return
+	}
+	text, proper := app.coloringStatus()
+	border := conflictColor
+	if proper {
+		border = color.RGBA{0, 200, 0, 255}
+	}
+	x, y := float32(10), float32(canvasTop())+10
+	w := float32(textWidth(text) + 10)
+	fillRect(screen, x, y, w, 22, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, w, 22, 2, border, true)
+	printAt(screen, text, int(x)+5, int(y)+3)
+}
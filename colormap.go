@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Color by metric:
+
+// Colors every vertex by a metric (metrics.go) instead of its own color, with a legend on the canvas.
+// Categorical metrics get one palette color per value, amounts get a continuous gradient
+// (or palette colors per distinct value, when switched to categorical).
+// It's only a view, the vertices keep their colors.
+//
+//	C:       color by the next metric (after the last one, back to the vertices' own colors).
+//	Shift+C: switch between continuous and categorical colors.
+
+// Viridis, readable for all kinds of color vision and in grayscale.
+var gradientStops = []color.RGBA{
+	{68, 1, 84, 255}, {59, 82, 139, 255}, {33, 145, 140, 255}, {94, 201, 98, 255}, {253, 231, 37, 255},
+}
+
+type ColorMapping struct {
+	Metric      int // Index into vertexMetrics
+	Categorical bool
+}
+
+// Returns the gradient color at t (0 to 1).
+func gradientColor(t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t)) * float64(len(gradientStops)-1)
+	i := min(int(t), len(gradientStops)-2)
+	f := t - float64(i)
+	a, b := gradientStops[i], gradientStops[i+1]
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*f) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
+
+// Switches to coloring by the next metric, or back to plain colors after the last one.
+func (app *App) CycleColorMapping() {
+	ms := app.vertexMetrics()
+	next := 0
+	if app.ColorMap != nil {
+		next = app.ColorMap.Metric + 1
+	}
+	if next >= len(ms) {
+		app.ColorMap = nil
+		app.Notify(T("colormap.off"))
+		app.Announce(T("colormap.off"))
+		return
+	}
+	app.ColorMap = &ColorMapping{Metric: next, Categorical: ms[next].Categorical}
+	msg := T("colormap.by", T(ms[next].Name))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Switches the active mapping between continuous and categorical colors.
+func (app *App) ToggleColorMappingKind() {
+	ms := app.vertexMetrics()
+	if app.ColorMap == nil || ms[app.ColorMap.Metric].Categorical {
+		return // Categories have no order to make a gradient from
+	}
+	app.ColorMap.Categorical = !app.ColorMap.Categorical
+}
+
+// Formats a metric value for the legend, whole numbers stay whole.
+func formatMetric(v float64) string {
+	return fmt.Sprintf("%.3g", v)
+}
+
+// Returns the sorted distinct values.
+func distinctValues(values []float64) []float64 {
+	seen := map[float64]bool{}
+	var distinct []float64
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			distinct = append(distinct, v)
+		}
+	}
+	sort.Float64s(distinct)
+	return distinct
+}
+
+// Computes the mapped color of every vertex, nil if no mapping is active.
+func (app *App) mappedColors() []color.RGBA {
+	if app.ColorMap == nil {
+		return nil
+	}
+	values := app.metricValues(app.ColorMap.Metric)
+	colors := make([]color.RGBA, len(values))
+	if app.ColorMap.Categorical {
+		slot := map[float64]int{}
+		for n, v := range distinctValues(values) {
+			slot[v] = n
+		}
+		for i, v := range values {
+			colors[i] = app.PaletteColor(slot[v])
+		}
+		return colors
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	for i, v := range values {
+		t := 0.0
+		if hi > lo {
+			t = (v - lo) / (hi - lo)
+		}
+		colors[i] = gradientColor(t)
+	}
+	return colors
+}
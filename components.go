@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Component analysis:
+//
+//	O: color every connected component with its own palette color and report how many there are and
+//	their sizes (one undo step). Print Info lists them too.
+//
+// Unlike coloring by the component metric (C), this sets the vertex colors themselves, so they stay
+// when editing on, in exports and in saved files.
+
+// Returns the number of vertices in each connected component, in the numbering of Components.
+func (g *Graph) ComponentSizes() []int {
+	var sizes []int
+	for _, c := range g.Components() {
+		for c >= len(sizes) {
+			sizes = append(sizes, 0)
+		}
+		sizes[c]++
+	}
+	return sizes
+}
+
+// Describes the components: their number and sizes, largest first.
+func (g *Graph) componentSummary() string {
+	sizes := g.ComponentSizes()
+	slices.SortFunc(sizes, func(a, b int) int { return b - a })
+	list := make([]string, len(sizes))
+	for k, size := range sizes {
+		list[k] = strconv.Itoa(size)
+	}
+	return T("components.summary", len(sizes), strings.Join(list, ", "))
+}
+
+// Colors each connected component with a palette color of its own and reports them.
+func (app *App) AnalyzeComponents() {
+	g := app.Graph
+	if len(g.Vertices) == 0 {
+		return
+	}
+	component := g.Components()
+	app.BeginTransaction(T("undo.components"))
+	for i, v := range g.Vertices {
+		if c := withOpacity(app.PaletteColor(component[i]), v.Color.A); c != v.Color {
+			app.Do(Action{Kind: ActionColorVertex, V1: i, Color: c})
+		}
+	}
+	app.Commit()
+	app.ColorMap = nil // It would hide the colors
+	msg := app.Graph.componentSummary()
+	if slices.Max(component)+1 > len(app.Palette().Colors) {
+		msg += " " + T("components.colors_reused", len(app.Palette().Colors))
+	}
+	app.Notify(msg)
+	app.Announce(msg)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+)
+
+// Condensation:
+
+// X opens the quotient graph as a new, untitled document in a tab of its own (documents.go): one
+// vertex per class, labeled with its members and placed at their center, and an edge between two
+// classes when any of their members are joined.
+// The classes are those of the color mapping when it's categorical (components, strong components,
+// an attribute column, ...), otherwise the strong components, which makes it the condensation of a
+// directed graph. Edges keep their direction; edges inside a class are dropped. The drawing it came
+// from stays as it was in its own tab.
+
+// Returns the strongly connected component of each vertex, numbered from 0 in order of their first
+// vertex (Tarjan). In undirected mode these are the connected components.
+func (g *Graph) StrongComponents() []int {
+	n := len(g.Vertices)
+	index, low := make([]int, n), make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	root := make([]int, n) // Root of each vertex's component, numbered below
+	var stack []int
+	next := 0
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for w, count := range g.AdjMatrix[v] {
+			if count == 0 {
+				continue
+			}
+			if index[w] < 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] == index[v] {
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				root[w] = v
+				if w == v {
+					break
+				}
+			}
+		}
+	}
+	for v := range n {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+
+	number := map[int]int{}
+	component := make([]int, n)
+	for v := range n {
+		if _, ok := number[root[v]]; !ok {
+			number[root[v]] = len(number)
+		}
+		component[v] = number[root[v]]
+	}
+	return component
+}
+
+// Returns the class of each vertex to condense by, numbered from 0, and whether they come from the color mapping.
+func (app *App) condenseClasses() ([]int, bool) {
+	if app.ColorMap == nil || !app.ColorMap.Categorical {
+		return app.Graph.StrongComponents(), false
+	}
+	values := app.metricValues(app.ColorMap.Metric)
+	slot := map[float64]int{}
+	for n, v := range distinctValues(values) {
+		slot[v] = n
+	}
+	classes := make([]int, len(values))
+	for i, v := range values {
+		classes[i] = slot[v]
+	}
+	return classes, true
+}
+
+// Opens the quotient graph as a new, untitled document next to the current one.
+func (app *App) Condense() {
+	if len(app.Graph.Vertices) == 0 {
+		return
+	}
+	app.openTab(app.condense)
+}
+
+// Replaces the drawing with its quotient graph.
+func (app *App) condense() {
+	g, n := app.Graph, len(app.Graph.Vertices)
+	classes, mapped := app.condenseClasses()
+	count := 0
+	for _, c := range classes {
+		count = max(count, c+1)
+	}
+	members := make([][]string, count)
+	colors := make([]color.RGBA, count)
+	centers := make([]point, count)
+	mappedColors := app.mappedColors()
+	for i, v := range g.Vertices {
+		c := classes[i]
+		if len(members[c]) == 0 {
+			colors[c] = v.Color
+			if mapped {
+				colors[c] = mappedColors[i]
+			}
+		}
+		members[c] = append(members[c], v.Label)
+		centers[c].X += v.X
+		centers[c].Y += v.Y
+	}
+	type arc struct{ from, to int }
+	var arcs []arc
+	seen := map[arc]bool{}
+	for i, row := range g.AdjMatrix {
+		for j, k := range row {
+			a := arc{classes[i], classes[j]}
+			if !g.Directed && a.from > a.to {
+				a = arc{a.to, a.from}
+			}
+			if k > 0 && a.from != a.to && !seen[a] {
+				seen[a] = true
+				arcs = append(arcs, a)
+			}
+		}
+	}
+
+	app.BeginTransaction(T("undo.condense"))
+	defer app.Commit()
+	app.Do(Action{Kind: ActionClear})
+	for c := range count {
+		x, y := centers[c].X/float64(len(members[c])), centers[c].Y/float64(len(members[c]))
+		label := "{" + strings.Join(members[c], ",") + "}"
+		app.Do(Action{Kind: ActionAddVertex, X: x, Y: y, Label: label, Color: colors[c], Radius: app.Settings.Defaults.VertexRadius})
+	}
+	for _, a := range arcs {
+		app.Do(Action{Kind: ActionAddEdge, V1: a.from, V2: a.to})
+	}
+	app.Notify(T("condense.done", n, count))
+}
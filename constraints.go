@@ -0,0 +1,175 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Constraints:
+
+// For building graphs of a given class, H turns on constraints the edits must respect:
+// keep the graph simple, keep every degree at most k, keep it bipartite with the two vertex colors
+// as the parts A and B, or keep it a forest. An edit that would break one is blocked with a warning,
+// or in warn-only mode made anyway with the warning. Only edits that break a constraint the graph
+// met before are caught, so a graph that doesn't fit yet can still be fixed by hand.
+// Deleting never breaks any of them; adding edges, and vertices or colors for the parts, are what's checked,
+// along with leaving directed mode (edges both ways become parallel edges).
+
+type ConstraintKind int
+
+const (
+	ConstraintSimple ConstraintKind = iota
+	ConstraintMaxDegree
+	ConstraintBipartite
+	ConstraintForest
+)
+
+// Locale keys.
+var constraintNames = []string{
+	"constraint.simple",
+	"constraint.max_degree",
+	"constraint.bipartite",
+	"constraint.forest",
+}
+
+type Constraint struct {
+	Kind ConstraintKind
+	K    int // Maximum degree
+}
+
+// Reports whether a graph meets the constraint.
+func (c Constraint) Holds(g *Graph) bool {
+	switch c.Kind {
+	case ConstraintSimple:
+		return g.IsSimple()
+	case ConstraintMaxDegree:
+		for i := range g.Vertices {
+			if g.Degree(i)+g.AdjMatrix[i][i] > c.K { // A loop adds two to the degree
+				return false
+			}
+		}
+		return true
+	case ConstraintBipartite:
+		return g.ColorClassCount() <= 2 && len(g.ColoringConflicts()) == 0
+	case ConstraintForest:
+		return g.IsSimple() && g.EdgeCount() == len(g.Vertices)-g.ComponentCount()
+	}
+	return true
+}
+
+func (c Constraint) String() string {
+	if c.Kind == ConstraintMaxDegree {
+		return T(constraintNames[c.Kind], c.K)
+	}
+	return T(constraintNames[c.Kind])
+}
+
+// Reports whether an action can break a constraint.
+func constrained(a Action) bool {
+	return a.Kind == ActionAddEdge || a.Kind == ActionAddVertex || a.Kind == ActionColorVertex || a.Kind == ActionSetDirected
+}
+
+// Returns the constraints an action would break, trying it on a copy of the graph.
+func (app *App) violations(a Action) []Constraint {
+	if len(app.Constraints) == 0 || !constrained(a) {
+		return nil
+	}
+	trial := app.Graph.Clone()
+	if trial.Apply(a) != nil {
+		return nil // Do reports the error
+	}
+	var broken []Constraint
+	for _, c := range app.Constraints {
+		if !c.Holds(trial) && c.Holds(app.Graph) {
+			broken = append(broken, c)
+		}
+	}
+	return broken
+}
+
+// Checks an action against the constraints. Returns false if it's blocked.
+func (app *App) checkConstraints(a Action) bool {
+	broken := app.violations(a)
+	if len(broken) == 0 {
+		return true
+	}
+	names := make([]string, len(broken))
+	for i, c := range broken {
+		names[i] = c.String()
+	}
+	if app.ConstraintsWarnOnly {
+		app.Warn(T("constraint.broken", strings.Join(names, ", ")))
+		return true
+	}
+	app.Warn(T("constraint.blocked", strings.Join(names, ", ")))
+	return false
+}
+
+// Turns a constraint on, or off if it's on (for the maximum degree, whatever its k).
+func (app *App) ToggleConstraint(c Constraint) {
+	if i := slices.IndexFunc(app.Constraints, func(o Constraint) bool { return o.Kind == c.Kind }); i >= 0 {
+		app.Notify(T("constraint.off", app.Constraints[i].String()))
+		app.Constraints = slices.Delete(app.Constraints, i, i+1)
+		return
+	}
+	app.Constraints = append(app.Constraints, c)
+	msg := T("constraint.on", c.String())
+	if !c.Holds(app.Graph) {
+		msg = T("constraint.unmet", c.String())
+	}
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Asks for the maximum degree, then turns that constraint on.
+func (app *App) askMaxDegree() {
+	app.askPredicate(T("constraint.degree_prompt"), func(text string) {
+		k, err := strconv.Atoi(text)
+		if err != nil || k < 0 {
+			app.Warn(T("warn.not_a_number", text))
+			return
+		}
+		app.ToggleConstraint(Constraint{Kind: ConstraintMaxDegree, K: k})
+	})
+}
+
+// Offers the constraints, checked if they're on.
+func (app *App) ShowConstraintsDialog() {
+	d := &Dialog{Message: T("constraint.dialog")}
+	for kind, name := range constraintNames {
+		on := slices.IndexFunc(app.Constraints, func(c Constraint) bool { return c.Kind == ConstraintKind(kind) })
+		label := "[ ] " + T(name)
+		action := func() { app.ToggleConstraint(Constraint{Kind: ConstraintKind(kind)}) }
+		switch {
+		case on >= 0:
+			label = "[x] " + app.Constraints[on].String()
+		case kind == int(ConstraintMaxDegree):
+			label, action = "[ ] "+T("constraint.max_degree_any"), app.askMaxDegree
+		}
+		d.Buttons = append(d.Buttons, DialogButton{Label: label, Action: action})
+	}
+	mode := T("constraint.mode_block")
+	if app.ConstraintsWarnOnly {
+		mode = T("constraint.mode_warn")
+	}
+	d.Buttons = append(d.Buttons,
+		DialogButton{Label: mode, Action: func() { app.ConstraintsWarnOnly = !app.ConstraintsWarnOnly; app.ShowConstraintsDialog() }},
+		DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
+
+// Lists the constraints in force at the bottom right of the canvas, above the filter.
+func (app *App) DrawConstraintStatus(screen *ebiten.Image) {
+	if len(app.Constraints) == 0 {
+		return
+	}
+	names := make([]string, len(app.Constraints))
+	for i, c := range app.Constraints {
+		names[i] = c.String()
+	}
+	text := T("constraint.status", strings.Join(names, ", "))
+	printAt(screen, text, screenWidth-textWidth(text)-10, screenHeight-36)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Vertex coordinates:
+
+// The export dialog can save every vertex position as graph-coords.csv (label, x, y, in canvas pixels),
+// for plotting the layout elsewhere. Importing a .csv file (Ctrl+O) does the reverse on the current graph:
+// every vertex whose label is in the file moves there, so a hand-tuned arrangement carries over
+// to a different graph with the same labels. Vertices not in the file stay put.
+
+const exportCoordsFile = "graph-coords.csv"
+
+// Writes label, x, y for every vertex, with a header row.
+func writeCoordinates(buf *bytes.Buffer, g *Graph) error {
+	w := csv.NewWriter(buf)
+	w.Write([]string{"label", "x", "y"})
+	for _, v := range g.Vertices {
+		w.Write([]string{v.Label, strconv.FormatFloat(v.X, 'f', 2, 64), strconv.FormatFloat(v.Y, 'f', 2, 64)})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Saves the vertex coordinates.
+func (app *App) exportCoordinates(path string) {
+	var buf bytes.Buffer
+	err := writeCoordinates(&buf, app.Graph)
+	if err == nil {
+		err = os.WriteFile(path, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		app.Warn(T("warn.write_file", path, err))
+		return
+	}
+	app.Notify(T("info.saved", path))
+}
+
+// Reads label -> position from a coordinates file, a row at a time. A header row is skipped (its x isn't a number).
+func readCoordinates(path string) (map[string]point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	positions := map[string]point{}
+	for n := 0; ; n++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return positions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("line %d: expected label, x, y", n+1)
+		}
+		x, errX := strconv.ParseFloat(rec[1], 64)
+		y, errY := strconv.ParseFloat(rec[2], 64)
+		if errX != nil || errY != nil {
+			if n == 0 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("line %d: bad coordinates %q, %q", n+1, rec[1], rec[2])
+		}
+		positions[rec[0]] = point{x, y}
+	}
+}
+
+// Moves the vertices to the positions saved for their labels.
+func (app *App) applyCoordinates(path string) {
+	positions, err := readCoordinates(path)
+	if err != nil {
+		app.Warn(T("warn.import", path, err))
+		return
+	}
+	app.BeginTransaction(T("undo.coordinates"))
+	defer app.Commit()
+	moved := 0
+	for i, v := range app.Graph.Vertices {
+		if p, ok := positions[v.Label]; ok {
+			app.Do(Action{Kind: ActionMoveVertex, V1: i, X: p.X, Y: p.Y})
+			moved++
+		}
+	}
+	app.Notify(T("coords.applied", moved, len(app.Graph.Vertices), path))
+}
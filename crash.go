@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Crash recovery:
+
+// A panic in an update or a draw (a tool, an algorithm, a rendering bug) doesn't take the drawing
+// down with it. It's recovered at the end of the frame, the whole working state (graph, journal,
+// undo steps, see session.go) is written to recoveryFile, the panic and its stack to crashReportFile,
+// and a dialog offers to save the session or copy the report. Opening recoveryFile like any
+// session puts everything back. While that dialog is open, further panics (a draw bug fails every
+// frame) are recovered without another report, the first state written is kept.
+
+const (
+	recoveryFile    = "recovery.gts"
+	crashReportFile = "crash.txt"
+)
+
+type Crash struct {
+	Report string // Panic, stack and environment, as written to crashReportFile
+	Saved  bool   // recoveryFile was written
+}
+
+// Recovers from a panic in the frame it's deferred in and reports it. Deferred at the top of Update and Draw.
+func (app *App) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if app.Crash != nil {
+		return
+	}
+	report := crashReport(r, debug.Stack())
+	log.Print(report)
+	app.Crash = &Crash{Report: report, Saved: app.writeRecovery()}
+	if err := os.WriteFile(crashReportFile, []byte(report), 0o644); err != nil {
+		log.Println(err)
+	}
+	app.showCrashDialog(r)
+}
+
+// Writes the working state to recoveryFile. Reports whether it worked; the state may be what broke.
+func (app *App) writeRecovery() (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("writing %s: %v", recoveryFile, r)
+			ok = false
+		}
+	}()
+	if err := app.SaveSession(recoveryFile); err != nil {
+		log.Printf("writing %s: %v", recoveryFile, err)
+		return false
+	}
+	return true
+}
+
+// Returns the text of a crash report.
+func crashReport(r any, stack []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "panic: %v\n\n%s\n", r, stack)
+	fmt.Fprintf(&b, "%s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "%s %s\n", info.Main.Path, info.Main.Version)
+	}
+	return b.String()
+}
+
+// Tells what happened and offers to save the session or copy the report.
+func (app *App) showCrashDialog(r any) {
+	message := T("crash.message", r)
+	if app.Crash.Saved {
+		message += " " + T("crash.recovered", recoveryFile)
+	}
+	done := func() { app.Crash = nil }
+	app.ShowDialog(&Dialog{
+		Message: message,
+		Buttons: []DialogButton{
+			{Label: T("crash.save"), Action: func() {
+				done()
+				app.Save()
+			}},
+			{Label: T("crash.copy"), Action: func() {
+				report := app.Crash.Report
+				done()
+				if err := writeClipboard(report); err != nil {
+					app.Warn(T("warn.clipboard", err))
+					return
+				}
+				app.Notify(T("crash.copied", crashReportFile))
+			}},
+			{Label: T("crash.continue"), Action: done},
+		},
+	})
+}
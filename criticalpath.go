@@ -0,0 +1,136 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// Critical paths:
+
+// For project graphs (PERT/CPM): in directed mode, on a graph without directed cycles, Q finds the
+// longest path, with the edge weights as durations. Each vertex is an event: its earliest time is the
+// longest path reaching it, its latest the project length minus the longest path leaving it. Vertices
+// where the two meet have no slack, and longest paths only go through those. One is highlighted and every
+// vertex shows "earliest/latest" under it, until Escape.
+
+type Schedule struct {
+	Earliest []float64
+	Latest   []float64
+	Length   float64 // Of the longest path
+	Path     []int   // A longest path, in order
+}
+
+// Returns the vertices ordered so that every edge goes forward, ok is false if there's a directed cycle.
+// In undirected mode every edge goes both ways, so only graphs without edges have one.
+func (g *Graph) TopologicalOrder() (order []int, ok bool) {
+	n := len(g.Vertices)
+	in := make([]int, n)
+	for i := range n {
+		in[i] = g.InDegree(i)
+	}
+	var ready []int
+	for i := range n {
+		if in[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		v := ready[0]
+		ready = ready[1:]
+		order = append(order, v)
+		for w := range n {
+			if g.AdjMatrix[v][w] == 0 {
+				continue
+			}
+			in[w] -= g.AdjMatrix[v][w]
+			if in[w] == 0 {
+				ready = append(ready, w)
+			}
+		}
+	}
+	return order, len(order) == n
+}
+
+// Returns the earliest and latest times of every vertex and a longest path, ok is false if the graph
+// isn't a directed acyclic graph.
+func (g *Graph) CriticalPath() (s *Schedule, ok bool) {
+	order, ok := g.TopologicalOrder()
+	if !g.Directed || !ok || len(order) == 0 {
+		return nil, false
+	}
+	n := len(order)
+	s = &Schedule{Earliest: make([]float64, n), Latest: make([]float64, n)}
+	for _, v := range order {
+		for w := range n {
+			if g.AdjMatrix[v][w] > 0 {
+				s.Earliest[w] = max(s.Earliest[w], s.Earliest[v]+g.EdgeWeight(v, w))
+			}
+		}
+	}
+	end := order[0]
+	for v, t := range s.Earliest {
+		if t > s.Earliest[end] {
+			end = v
+		}
+	}
+	s.Length = s.Earliest[end]
+	for k := n - 1; k >= 0; k-- {
+		v := order[k]
+		s.Latest[v] = s.Length
+		for w := range n {
+			if g.AdjMatrix[v][w] > 0 {
+				s.Latest[v] = min(s.Latest[v], s.Latest[w]-g.EdgeWeight(v, w))
+			}
+		}
+	}
+
+	// Back from the end, each step along an edge that set the earliest time
+	s.Path = []int{end}
+	for v := end; ; {
+		prev := -1
+		for u := range n {
+			if g.AdjMatrix[u][v] > 0 && s.Earliest[u]+g.EdgeWeight(u, v) == s.Earliest[v] {
+				prev = u
+				break
+			}
+		}
+		if prev < 0 {
+			break
+		}
+		s.Path = append([]int{prev}, s.Path...)
+		v = prev
+	}
+	return s, true
+}
+
+// Reports whether a vertex has no slack.
+func (s *Schedule) Critical(v int) bool {
+	return math.Abs(s.Latest[v]-s.Earliest[v]) < 1e-9
+}
+
+// Highlights the longest path with the earliest and latest times under the vertices.
+func (app *App) ShowCriticalPath() {
+	g := app.Graph
+	if !g.Directed {
+		app.Warn(T("critical.undirected"))
+		return
+	}
+	s, ok := g.CriticalPath()
+	if !ok {
+		app.Warn(T("critical.cycle"))
+		return
+	}
+	step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: map[EdgeKey]bool{}, Labels: map[int]string{}}
+	for v := range g.Vertices {
+		step.Labels[v] = formatMetric(s.Earliest[v]) + "/" + formatMetric(s.Latest[v])
+		if s.Critical(v) {
+			step.Vertices[v] = highlightEdgeColor
+		}
+	}
+	for k := 1; k < len(s.Path); k++ {
+		step.Edges[Edge(s.Path[k-1], s.Path[k])] = true
+	}
+	step.Text = T("critical.text", formatMetric(s.Length), g.pathText(s.Path))
+	app.PlayAnimation(&Animation{Title: T("critical.title"), Steps: []AnimationStep{step}})
+	app.Notify(step.Text)
+}
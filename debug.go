@@ -0,0 +1,100 @@
+package main
+
+import (
+	"image/color"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Debug overlay:
+
+// F12 toggles a panel at the top left of the canvas for tuning big graphs: frame and tick rates,
+// how long updates and draws take, the last hit test and spatial index rebuild (spatial.go), what the
+// graph and the index hold, and the Go heap. Times are smoothed over the last frames. While it's on,
+// the canvas is redrawn every frame (idle.go), so the draw time is always current.
+
+const (
+	debugSmoothing   = 0.1                    // Weight of the newest sample in the running averages
+	debugMemInterval = 500 * time.Millisecond // Reading the memory stats stops the world, so not every frame
+)
+
+type DebugOverlay struct {
+	Update, Draw   time.Duration // Running averages
+	HitTest        time.Duration // Last vertex or edge lookup
+	IndexBuild     time.Duration // Last spatial index rebuild
+	memory         runtime.MemStats
+	memoryReadTime time.Time
+}
+
+// Turns the overlay on or off.
+func (app *App) ToggleDebugOverlay() {
+	if app.Debug != nil {
+		app.Debug = nil
+		return
+	}
+	app.Debug = &DebugOverlay{}
+}
+
+// Adds a sample to a running average.
+func smooth(avg *time.Duration, sample time.Duration) {
+	if *avg == 0 {
+		*avg = sample
+		return
+	}
+	*avg += time.Duration(debugSmoothing * float64(sample-*avg))
+}
+
+// Records how long an update took since start. Deferred at the top of Update.
+func (app *App) timeUpdate(start time.Time) {
+	if app.Debug != nil {
+		smooth(&app.Debug.Update, time.Since(start))
+	}
+}
+
+// Records how long a draw took since start. Deferred at the top of Draw.
+func (app *App) timeDraw(start time.Time) {
+	if app.Debug != nil {
+		smooth(&app.Debug.Draw, time.Since(start))
+	}
+}
+
+// Records how long a hit test took since start.
+func (app *App) timeHitTest(start time.Time) {
+	if app.Debug != nil {
+		app.Debug.HitTest = time.Since(start)
+	}
+}
+
+// Draws the overlay (if on).
+func (app *App) DrawDebugOverlay(screen *ebiten.Image) {
+	d := app.Debug
+	if d == nil {
+		return
+	}
+	if time.Since(d.memoryReadTime) > debugMemInterval {
+		runtime.ReadMemStats(&d.memory)
+		d.memoryReadTime = time.Now()
+	}
+	s := &app.spatial
+	text := T("debug.overlay",
+		ebiten.ActualFPS(), ebiten.ActualTPS(),
+		ms(d.Update), ms(d.Draw), ms(d.HitTest), ms(d.IndexBuild),
+		len(app.Graph.Vertices), app.Graph.EdgeCount(), len(s.segs), len(s.segments),
+		float64(d.memory.HeapAlloc)/(1<<20), d.memory.NumGC, runtime.NumGoroutine())
+	lines := strings.Split(text, "\n")
+	w := 0
+	for _, line := range lines {
+		w = max(w, textWidth(line))
+	}
+	x, y := float32(10), float32(canvasTop())+10
+	fillRect(screen, x, y, float32(w+10), float32(16*len(lines)+6), color.RGBA{0, 0, 0, 200}, true)
+	printAt(screen, text, int(x)+5, int(y)+3)
+}
+
+// Returns a duration in milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Unified Delete tool:
+
+// Removes whatever is closest under the cursor, vertex or edge (loops included).
+// The option bar restricts it to one kind, and so do modifiers while clicking:
+// Shift only deletes vertices, Ctrl only edges.
+
+type DeleteFilter int
+
+const (
+	DeleteBoth DeleteFilter = iota
+	DeleteVerticesOnly
+	DeleteEdgesOnly
+)
+
+var deleteFilterNames = []string{
+	"option.delete_both",
+	"option.delete_vertices",
+	"option.delete_edges",
+}
+
+// Deletes a vertex, asking first if it has many incident edges.
+func (app *App) deleteVertex(i int) {
+	v := app.Graph.Vertices[i]
+	deleteVertex := func() { app.Do(Action{Kind: ActionDeleteVertex, V1: i}) }
+	if deg := app.Graph.Degree(i); deg >= app.Settings.ConfirmDegree {
+		app.Confirm(T("confirm.delete_vertex", v.Label, deg), deleteVertex)
+	} else {
+		deleteVertex()
+	}
+}
+
+// Returns the filter in effect, modifiers win over the option bar.
+func (app *App) deleteFilter() DeleteFilter {
+	switch {
+	case ebiten.IsKeyPressed(ebiten.KeyShift):
+		return DeleteVerticesOnly
+	case ebiten.IsKeyPressed(ebiten.KeyControl):
+		return DeleteEdgesOnly
+	}
+	return app.DeleteFilter
+}
+
+// Deletes the element closest to (x, y), asking which one if that's ambiguous (disambiguate.go).
+func (app *App) DeleteAt(x, y float64) {
+	filter := app.deleteFilter()
+	app.pickHit(x, y, filter != DeleteEdgesOnly, filter != DeleteVerticesOnly, func(h Hit) {
+		if h.Vertex >= 0 {
+			app.deleteVertex(h.Vertex)
+		} else {
+			app.Do(Action{Kind: ActionDeleteEdge, V1: h.V1, V2: h.V2})
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Modal dialogs:
+
+// While a dialog is open it gets all input, the canvas and toolbar are blocked.
+// Enter picks the first button, Escape the last one.
+
+type DialogButton struct {
+	Label  string
+	Action func() // May be nil
+}
+
+type Dialog struct {
+	Message string
+	Buttons []DialogButton
+}
+
+const (
+	dialogMinWidth      = 420
+	dialogHeight        = 120 // With one row of buttons
+	dialogButtonWidth   = 130
+	dialogButtonsPerRow = 5 // More buttons wrap into another row
+)
+
+// Opens a dialog on top of everything else.
+func (app *App) ShowDialog(d *Dialog) {
+	app.Dialog = d
+}
+
+// Asks before doing something destructive.
+// Runs onYes right away if confirmations are turned off in the settings.
+func (app *App) Confirm(message string, onYes func()) {
+	if !app.Settings.Confirmations {
+		onYes()
+		return
+	}
+	app.ShowDialog(&Dialog{
+		Message: message,
+		Buttons: []DialogButton{
+			{Label: T("dialog.yes"), Action: onYes},
+			{Label: T("dialog.yes_dont_ask"), Action: func() {
+				app.Settings.Confirmations = false
+				if err := app.Settings.Save(); err != nil {
+					app.Warn(T("warn.save_settings", err))
+				}
+				onYes()
+			}},
+			{Label: T("dialog.no")},
+		},
+	})
+}
+
+// Width of the dialog box, grows with the message and the number of buttons.
+func (d *Dialog) width() float32 {
+	w := max(dialogMinWidth, textWidth(d.Message)+30, min(len(d.Buttons), dialogButtonsPerRow)*(dialogButtonWidth+10)+30)
+	return float32(w)
+}
+
+// Number of button rows.
+func (d *Dialog) rows() int {
+	return max(1, (len(d.Buttons)+dialogButtonsPerRow-1)/dialogButtonsPerRow)
+}
+
+// Height of the dialog box, grows with the rows of buttons.
+func (d *Dialog) height() float32 {
+	return float32(dialogHeight + (d.rows()-1)*35)
+}
+
+// Position of the dialog box on the screen.
+func (d *Dialog) rect(screenW, screenH int) (x, y float32) {
+	return (float32(screenW) - d.width()) / 2, (float32(screenH) - d.height()) / 2
+}
+
+// Position of a dialog button.
+func (d *Dialog) buttonRect(i int, x, y float32) (bx, by float32) {
+	row, col := i/dialogButtonsPerRow, i%dialogButtonsPerRow
+	inRow := min(dialogButtonsPerRow, len(d.Buttons)-row*dialogButtonsPerRow)
+	total := float32(inRow*dialogButtonWidth + (inRow-1)*10)
+	bx = x + (d.width()-total)/2 + float32(col*(dialogButtonWidth+10))
+	by = y + d.height() - 40 - float32((d.rows()-1-row)*35)
+	return bx, by
+}
+
+// Processes input for the open dialog.
+func (app *App) HandleDialogInput(screenW, screenH int) {
+	d := app.Dialog
+	pick := -1
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		pick = 0
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		pick = len(d.Buttons) - 1
+	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		cx, cy := cursorPosition()
+		x, y := d.rect(screenW, screenH)
+		for i := range d.Buttons {
+			bx, by := d.buttonRect(i, x, y)
+			if float32(cx) >= bx && float32(cx) < bx+dialogButtonWidth && float32(cy) >= by && float32(cy) < by+25 {
+				pick = i
+			}
+		}
+	}
+
+	if pick >= 0 {
+		app.Dialog = nil // Closed first so the action can open another dialog
+		if action := d.Buttons[pick].Action; action != nil {
+			action()
+		}
+	}
+}
+
+// Draws the open dialog (if any).
+func (app *App) DrawDialog(screen *ebiten.Image) {
+	d := app.Dialog
+	if d == nil {
+		return
+	}
+	w, h := logicalSize(screen)
+
+	// Dim the canvas behind the dialog
+	fillRect(screen, 0, 0, float32(w), float32(h), color.RGBA{0, 0, 0, 120}, false)
+
+	x, y := d.rect(w, h)
+	fillRect(screen, x, y, d.width(), d.height(), color.RGBA{40, 40, 40, 255}, true)
+	strokeRect(screen, x, y, d.width(), d.height(), 2, color.RGBA{100, 100, 255, 255}, true)
+	printAt(screen, d.Message, int(x)+15, int(y)+20)
+
+	for i, b := range d.Buttons {
+		bx, by := d.buttonRect(i, x, y)
+		fillRect(screen, bx, by, dialogButtonWidth, 25, color.RGBA{80, 80, 80, 255}, true)
+		strokeRect(screen, bx, by, dialogButtonWidth, 25, 1, color.RGBA{150, 150, 150, 255}, true)
+		printAt(screen, b.Label, int(bx)+(dialogButtonWidth-textWidth(b.Label))/2, int(by)+5)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Directed graphs:
+
+// In directed mode (an option of the Add Edge tool) an edge goes from the vertex clicked first to the
+// one clicked second: AddEdge only counts it in AdjMatrix[v1][v2], it's drawn with an arrowhead at the
+// target, and the graph info gives in- and out-degrees. When edges go both ways between two vertices,
+// each way is drawn as an arc on its own side of the line between them (edges.go), so the arrowheads
+// stay apart, and clicks tell the ways apart: deleting an edge or picking one from the menu of close
+// hits (disambiguate.go) acts on the way clicked. Both ways share the pair's style; each has its own weight (edgeweights.go).
+//
+// Switching to directed mode turns each edge into one going from the lower index to the higher one;
+// switching back makes every edge undirected again. Loops are the same in both.
+// Components, adjacency and the graph classes ignore the directions: the classes are tested on the
+// underlying undirected graph.
+
+const (
+	arrowLength = 10 // Plus twice the edge width
+	arrowSpread = 0.4
+)
+
+// Returns the number of edges between i and j, whichever way they go.
+func (g *Graph) Multiplicity(i, j int) int {
+	if g.Directed && i != j {
+		return g.AdjMatrix[i][j] + g.AdjMatrix[j][i]
+	}
+	return g.AdjMatrix[i][j]
+}
+
+// Returns where the k-th of the edges drawn between i and j starts and ends.
+func (g *Graph) edgeEnds(i, j, k int) (from, to int) {
+	if !g.Directed || k < g.AdjMatrix[i][j] {
+		return i, j
+	}
+	return j, i
+}
+
+// Turns directed mode on or off, converting the edges.
+func (g *Graph) SetDirected(directed bool) {
+	if directed == g.Directed {
+		return
+	}
+	for i := range g.AdjMatrix {
+		for j := i + 1; j < len(g.AdjMatrix); j++ {
+			if directed {
+				g.AdjMatrix[j][i] = 0 // The edge goes from i to j
+			} else {
+				total := g.AdjMatrix[i][j] + g.AdjMatrix[j][i]
+				g.AdjMatrix[i][j], g.AdjMatrix[j][i] = total, total
+			}
+		}
+	}
+	// Weights are per way in directed mode: the edges now go from the lower index, where undirected
+	// weights are keyed already; going back, a pair keeps the weight from the lower index if it has one
+	for k, w := range g.EdgeWeights {
+		if !directed && k.A > k.B {
+			delete(g.EdgeWeights, k)
+			if _, ok := g.EdgeWeights[Edge(k.A, k.B)]; !ok {
+				g.EdgeWeights[Edge(k.A, k.B)] = w
+			}
+		}
+	}
+	g.Directed = directed
+}
+
+// Returns a copy of the graph with every edge undirected.
+func (g *Graph) underlying() *Graph {
+	u := g.Clone()
+	u.SetDirected(false)
+	return u
+}
+
+// Returns the number of edges going into a vertex (column sum of the adjacency matrix).
+func (g *Graph) InDegree(v int) int {
+	deg := 0
+	for _, row := range g.AdjMatrix {
+		deg += row[v]
+	}
+	return deg
+}
+
+// Switches the graph between directed and undirected.
+func (app *App) ToggleDirected() {
+	app.Do(Action{Kind: ActionSetDirected, Directed: !app.Graph.Directed})
+}
+
+// Draws an arrowhead on the rim of the target vertex (radius r), for an edge arriving from (fromX, fromY).
+func drawArrowhead(screen *ebiten.Image, fromX, fromY, toX, toY, r, width float64, clr color.RGBA) {
+	angle := math.Atan2(toY-fromY, toX-fromX)
+	tipX, tipY := toX-r*math.Cos(angle), toY-r*math.Sin(angle)
+	length := arrowLength + 2*width
+	wing := func(side float64) point {
+		return point{tipX - length*math.Cos(angle+side), tipY - length*math.Sin(angle+side)}
+	}
+	strokePolyline(screen, []point{wing(-arrowSpread), {tipX, tipY}, wing(arrowSpread)}, width, clr)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Builds a graph on n vertices with the given edges, directed or not.
+func testGraph(n int, directed bool, edges ...[2]int) *Graph {
+	g := &Graph{Directed: directed}
+	for i := range n {
+		g.AddVertex(0, 0, fmt.Sprintf("V%d", i+1), color.RGBA{}, 10)
+	}
+	for _, e := range edges {
+		g.AddEdge(e[0], e[1])
+	}
+	return g
+}
+
+// The structural analyses ignore directions: they give a digraph the answers of its underlying graph.
+func TestDirectedAnalysesIgnoreDirections(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 2000 {
+		n := 3 + rng.Intn(8)
+		g := testGraph(n, true)
+		for i := range n {
+			for j := range n {
+				if i != j && rng.Float64() < 0.35 {
+					g.AddEdge(i, j)
+				}
+			}
+		}
+		u := g.underlying()
+		if g.IsPlanar() != u.IsPlanar() {
+			t.Fatalf("IsPlanar differs from the underlying graph for %v", g.AdjMatrix)
+		}
+		if !slices.Equal(g.Components(), u.Components()) {
+			t.Fatalf("Components differ from the underlying graph for %v", g.AdjMatrix)
+		}
+		if !slices.Equal(g.Classes(), u.Classes()) {
+			t.Fatalf("Classes differ from the underlying graph for %v", g.AdjMatrix)
+		}
+		for i := range n {
+			g.Vertices[i].Color = color.RGBA{uint8(rng.Intn(3)), 0, 0, 255}
+			g.Vertices[i].Weight = 1 + float64(rng.Intn(5))
+		}
+		u = g.underlying()
+		if !slices.Equal(g.ColoringConflicts(), u.ColoringConflicts()) {
+			t.Fatalf("ColoringConflicts differ from the underlying graph for %v", g.AdjMatrix)
+		}
+		if !slices.Equal(g.WeightedIndependentSet(), u.WeightedIndependentSet()) {
+			t.Fatalf("WeightedIndependentSet differs from the underlying graph for %v", g.AdjMatrix)
+		}
+		if !slices.Equal(g.WeightedVertexCover(), u.WeightedVertexCover()) {
+			t.Fatalf("WeightedVertexCover differs from the underlying graph for %v", g.AdjMatrix)
+		}
+		if !slices.Equal(g.CoreNumbers(), u.CoreNumbers()) {
+			t.Fatalf("CoreNumbers differ from the underlying graph for %v", g.AdjMatrix)
+		}
+		forest := g.SpanningForest()
+		if len(forest) != len(u.SpanningForest()) {
+			t.Fatalf("SpanningForest has %d edges, the underlying graph's %d, for %v", len(forest), len(u.SpanningForest()), g.AdjMatrix)
+		}
+		for _, e := range forest {
+			if g.Multiplicity(e[0], e[1]) == 0 {
+				t.Fatalf("SpanningForest edge %v isn't in %v", e, g.AdjMatrix)
+			}
+		}
+	}
+}
+
+// A directed circuit has to follow the edges: a directed triangle has one, a transitive one doesn't,
+// though every vertex has even degree.
+func TestDirectedEulerian(t *testing.T) {
+	cycle := testGraph(3, true, [2]int{0, 1}, [2]int{1, 2}, [2]int{2, 0})
+	circuit := cycle.EulerianCircuit()
+	if !cycle.IsEulerian() || len(circuit) != 4 {
+		t.Fatalf("directed triangle: Eulerian %v, circuit %v", cycle.IsEulerian(), circuit)
+	}
+	for k := 1; k < len(circuit); k++ {
+		if cycle.AdjMatrix[circuit[k-1]][circuit[k]] == 0 {
+			t.Errorf("circuit %v goes against the edge %d → %d", circuit, circuit[k], circuit[k-1])
+		}
+	}
+	transitive := testGraph(3, true, [2]int{0, 1}, [2]int{1, 2}, [2]int{0, 2})
+	if transitive.IsEulerian() || transitive.EulerianCircuit() != nil {
+		t.Error("transitive triangle is Eulerian")
+	}
+	if unbalanced := transitive.eulerianUnbalanced(); !slices.Equal(unbalanced, []int{0, 2}) {
+		t.Errorf("transitive triangle: unbalanced %v, want [0 2]", unbalanced)
+	}
+}
+
+// A directed cycle is a cycle, and K5 stays non-planar with every edge pointing one way.
+func TestDirectedClasses(t *testing.T) {
+	cycle := testGraph(4, true, [2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 0})
+	if classes := cycle.Classes(); !slices.Contains(classes, T("class.cycle")) {
+		t.Errorf("directed 4-cycle: classes %v, want a cycle", classes)
+	}
+	if !cycle.IsConnected() {
+		t.Error("directed 4-cycle isn't connected")
+	}
+	k5 := testGraph(5, true)
+	for i := range 5 {
+		for j := i + 1; j < 5; j++ {
+			k5.AddEdge(j, i) // From the higher index to the lower
+		}
+	}
+	if k5.IsPlanar() {
+		t.Error("directed K5 is planar")
+	}
+}
+
+// Each way of a directed pair has its own weight, and shortest paths follow the weight of the way taken.
+func TestDirectedWeightsPerWay(t *testing.T) {
+	g := testGraph(3, true, [2]int{0, 1}, [2]int{1, 0}, [2]int{1, 2}, [2]int{0, 2})
+	g.SetEdgeWeight(0, 1, 1)
+	g.SetEdgeWeight(1, 0, 10)
+	g.SetEdgeWeight(1, 2, 1)
+	g.SetEdgeWeight(0, 2, 5)
+	if w := g.EdgeWeight(1, 0); w != 10 {
+		t.Errorf("weight of V2 → V1 = %v, want 10", w)
+	}
+	if _, length, _ := g.ShortestPath(0, 2); length != 2 {
+		t.Errorf("shortest V1 → V3 = %v, want 2", length)
+	}
+	g.SetDirected(false)
+	if w := g.EdgeWeight(1, 0); w != 1 {
+		t.Errorf("undirected weight of V1 - V2 = %v, want 1 (the way from the lower index)", w)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Unsaved changes:
+
+// The drawing is clean when it was last opened, imported or saved (as a session, or as a graph file:
+// TGF, GML, node-link JSON, DOT), and dirty after any edit since. The window title shows the file's
+// name with a star while there are unsaved changes, "graph.json *". Quitting, opening another file or
+// starting from a template, family or generator (Ctrl+N) with unsaved changes first offers to save
+// them; a template starts a new, untitled drawing, so Ctrl+S doesn't write it over the file it came
+// from. Closing a tab (Ctrl+W) and quitting do the same for every document open (documents.go).
+// Undoing back to the saved state still counts as a change.
+
+// Marks the drawing as matching the file at path ("" for a new drawing).
+func (app *App) markSaved(path string) {
+	app.savedRevision, app.moved = app.revision, false
+	app.FileName = ""
+	if path != "" {
+		app.FileName = filepath.Base(path)
+	}
+}
+
+// Reports whether the drawing changed since it was last opened or saved.
+func (app *App) Dirty() bool {
+	return app.revision != app.savedRevision || app.moved
+}
+
+// Returns the name the drawing goes by in the title and prompts.
+func (app *App) documentName() string {
+	if app.FileName == "" {
+		return T("file.untitled")
+	}
+	return app.FileName
+}
+
+// Keeps the window title up to date. Called every update.
+func (app *App) UpdateWindowTitle() {
+	title := app.documentName()
+	if app.Dirty() {
+		title += " *"
+	}
+	title += " - " + T("app.title")
+	if title != app.windowTitle {
+		app.windowTitle = title
+		ebiten.SetWindowTitle(title)
+	}
+}
+
+// Runs then, first offering to save the drawing if it has unsaved changes.
+func (app *App) checkUnsaved(then func()) {
+	if !app.Dirty() {
+		then()
+		return
+	}
+	app.ShowDialog(&Dialog{
+		Message: T("unsaved.prompt", app.documentName()),
+		Buttons: []DialogButton{
+			{Label: T("unsaved.save"), Action: func() { app.saveThen(then) }},
+			{Label: T("unsaved.discard"), Action: then},
+			{Label: T("dialog.cancel")},
+		},
+	})
+}
+
+// Replaces the drawing with what build draws as a new, untitled one, first offering to save unsaved
+// changes. The old drawing stays a Ctrl+Z away. then (may be nil) runs once the new one is in.
+func (app *App) openUntitled(build, then func()) {
+	app.checkUnsaved(func() {
+		build()
+		app.markSaved("")
+		app.SavePath = ""
+		if then != nil {
+			then()
+		}
+	})
+}
+
+// Saves like Ctrl+S, then runs then if it was saved.
+func (app *App) saveThen(then func()) {
+	save := func(path string) {
+		if app.saveAs(path); !app.Dirty() {
+			then()
+		}
+	}
+	if app.SavePath != "" {
+		save(app.SavePath)
+		return
+	}
+	app.saveFile(exportJSONFile, save)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"cmp"
+	"image/color"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Disambiguation:
+
+// A click acts on the closest thing under the cursor, a vertex measured from halfway to its rim
+// (so an edge right next to a big vertex can still be hit). When more than one is about as close
+// (parallel edges running together, a loop over an edge, an edge passing under a vertex, vertices
+// on top of each other) a small menu at the cursor lists them to pick from instead: a click or the
+// number key picks one, Escape or a click elsewhere cancels. Hovering follows the same order, so the
+// tooltip is about what a click would act on.
+
+const (
+	hitAmbiguity   = 4 // Hits no further than this beyond the closest one are ambiguous
+	hitMenuWidth   = 200
+	hitMenuRowSize = 20
+)
+
+// Vertex or edge under the cursor.
+type Hit struct {
+	Vertex int     // -1 for an edge
+	V1, V2 int     // Edge ends, start first for a directed edge
+	Dist   float64 // From the cursor
+}
+
+// Menu of hits to pick from.
+type HitMenu struct {
+	Hits   []Hit
+	X, Y   float32 // Screen position
+	OnPick func(h Hit)
+}
+
+// Returns the vertices and edges at (x, y), closest first (vertices first on a tie).
+func (app *App) hitsAt(x, y float64, vertices, edges bool) []Hit {
+	defer app.timeHitTest(time.Now())
+	s := app.spatialIndex()
+	var hits []Hit
+	if vertices {
+		for _, i := range s.verticesAt(app, x, y) {
+			v := app.Graph.Vertices[i]
+			hits = append(hits, Hit{Vertex: i, Dist: math.Max(0, math.Hypot(v.X-x, v.Y-y)-app.vertexRadius(i)/2)})
+		}
+	}
+	if edges {
+		for ends, d := range s.edgesNear(app, x, y) {
+			hits = append(hits, Hit{Vertex: -1, V1: ends[0], V2: ends[1], Dist: d})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(a.Dist, b.Dist); c != 0 {
+			return c
+		}
+		if (a.Vertex >= 0) != (b.Vertex >= 0) {
+			return cmp.Compare(b.Vertex, a.Vertex) // The vertex first, -1 last
+		}
+		return cmp.Or(cmp.Compare(a.Vertex, b.Vertex), cmp.Compare(a.V1, b.V1), cmp.Compare(a.V2, b.V2))
+	})
+	return hits
+}
+
+// Calls then with the vertex or edge at (x, y), asking in a menu which one if several are about as
+// close. Nothing happens if there's none.
+func (app *App) pickHit(x, y float64, vertices, edges bool, then func(h Hit)) {
+	hits := app.hitsAt(x, y, vertices, edges)
+	if len(hits) == 0 {
+		return
+	}
+	n := 1
+	for n < len(hits) && hits[n].Dist <= hits[0].Dist+hitAmbiguity {
+		n++
+	}
+	if n == 1 {
+		then(hits[0])
+		return
+	}
+	sx, sy := app.toScreen(x, y)
+	app.HitMenu = &HitMenu{Hits: hits[:min(n, 9)], X: float32(sx) + 10, Y: float32(sy) + 10, OnPick: then}
+}
+
+// Reports whether a hit is still in the graph, which may have changed while the menu was open.
+func (app *App) hitValid(h Hit) bool {
+	if h.Vertex >= 0 {
+		return app.Graph.CheckVertices(h.Vertex) == nil
+	}
+	return app.Graph.CheckVertices(h.V1, h.V2) == nil && app.Graph.AdjMatrix[h.V1][h.V2] > 0
+}
+
+// Describes a hit in the menu.
+func (app *App) hitLabel(h Hit) string {
+	g := app.Graph
+	switch {
+	case h.Vertex >= 0:
+		return T("hit.vertex", g.Vertices[h.Vertex].Label)
+	case h.V1 == h.V2:
+		return T("hit.loop", g.Vertices[h.V1].Label)
+	case g.Directed:
+		return T("hit.arc", g.Vertices[h.V1].Label, g.Vertices[h.V2].Label)
+	}
+	return T("hit.edge", g.Vertices[h.V1].Label, g.Vertices[h.V2].Label)
+}
+
+// Position of the menu, kept on screen.
+func (m *HitMenu) rect() (x, y, w, h float32) {
+	w, h = hitMenuWidth, float32(len(m.Hits)*hitMenuRowSize+6)
+	return min(m.X, screenWidth-w), min(m.Y, screenHeight-h), w, h
+}
+
+// Returns the row under the cursor, -1 if it's outside the menu.
+func (m *HitMenu) rowAt(cx, cy int) int {
+	x, y, w, h := m.rect()
+	if float32(cx) < x || float32(cx) >= x+w || float32(cy) < y+3 || float32(cy) >= y+h-3 {
+		return -1
+	}
+	return int((float32(cy) - y - 3) / hitMenuRowSize)
+}
+
+// Processes input for the open menu. Closes it if the graph changed under it.
+func (app *App) HandleHitMenuInput() {
+	m := app.HitMenu
+	for _, h := range m.Hits {
+		if !app.hitValid(h) { // Gone while the menu was open
+			app.HitMenu = nil
+			return
+		}
+	}
+	pick := -1
+	for k := range m.Hits {
+		if inpututil.IsKeyJustPressed(ebiten.Key1 + ebiten.Key(k)) {
+			pick = k
+		}
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if pick = m.rowAt(cursorPosition()); pick < 0 {
+			app.HitMenu = nil // Clicking elsewhere cancels
+			return
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		app.HitMenu = nil
+		return
+	}
+	if pick >= 0 {
+		app.HitMenu = nil // Closed first so picking can open something else
+		m.OnPick(m.Hits[pick])
+	}
+}
+
+// Draws the open menu (if any), with the hovered row and its vertex or edge highlighted.
+func (app *App) DrawHitMenu(screen *ebiten.Image) {
+	m := app.HitMenu
+	if m == nil {
+		return
+	}
+	x, y, w, h := m.rect()
+	hover := m.rowAt(cursorPosition())
+	if hover >= 0 {
+		app.drawHitHighlight(screen, m.Hits[hover])
+	}
+	fillRect(screen, x, y, w, h, color.RGBA{30, 30, 30, 240}, true)
+	strokeRect(screen, x, y, w, h, 1, color.RGBA{200, 200, 200, 255}, true)
+	for k, hit := range m.Hits {
+		ry := y + 3 + float32(k*hitMenuRowSize)
+		if k == hover {
+			fillRect(screen, x+2, ry, w-4, hitMenuRowSize, color.RGBA{80, 80, 120, 255}, true)
+		}
+		printAt(screen, string(rune('1'+k))+". "+app.hitLabel(hit), int(x)+6, int(ry)+3)
+	}
+}
+
+// Marks a vertex or edge on the canvas.
+func (app *App) drawHitHighlight(screen *ebiten.Image, h Hit) {
+	g := app.Graph
+	if h.Vertex >= 0 {
+		v := g.Vertices[h.Vertex]
+		sx, sy := app.toScreen(v.X, v.Y)
+		strokeCircle(screen, float32(sx), float32(sy), float32(app.toScreenLength(app.vertexRadius(h.Vertex)))+6, 2, highlightEdgeColor, true)
+		return
+	}
+	for _, c := range app.hitCurves() {
+		if c.seg.From != h.V1 || c.seg.To != h.V2 {
+			continue
+		}
+		for n := 1; n < len(c.points); n++ {
+			ax, ay := app.toScreen(c.points[n-1].X, c.points[n-1].Y)
+			bx, by := app.toScreen(c.points[n].X, c.points[n].Y)
+			strokeLine(screen, float32(ax), float32(ay), float32(bx), float32(by), 4, highlightEdgeColor, true)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Documents:
+//
+//	Ctrl+PageDown, Ctrl+PageUp: switch to the next or previous tab.
+//	Ctrl+W: close the current tab.
+//
+// Condensing (X) and extracting an ego network (Shift+E) open their result as a new, untitled
+// document next to the one it came from, which stays as it was. While more than one document is open
+// they're listed as tabs at the right end of the option bar; clicking one switches to it.
+// Each document has its own graph, undo steps, journal, selection, view, title, filter, time slider and
+// file; the tool, settings, macro and color mappings are shared. Only the current document lives in the
+// App's fields: switching stashes them in its tab and loads the other one's. Closing a tab, or
+// quitting, offers to save each document with unsaved changes first.
+
+// An open document, as stashed in its tab while another one is current.
+type Document struct {
+	Graph     *Graph
+	History   History
+	Journal   Journal
+	Selection Selection
+	Camera    Camera
+	Title     string
+	Caption   string
+	Filter    *Filter
+	Timeline  *Timeline
+	SavePath  string
+	FileName  string
+	dirty     bool // Had unsaved changes when it was stashed
+}
+
+// Takes the current document's state out of the App.
+func (app *App) stashDocument() *Document {
+	return &Document{
+		Graph: app.Graph, History: app.History, Journal: app.Journal, Selection: app.Selection,
+		Camera: app.Camera, Title: app.Title, Caption: app.Caption, Filter: app.Filter, Timeline: app.Timeline,
+		SavePath: app.SavePath, FileName: app.FileName, dirty: app.Dirty(),
+	}
+}
+
+// Makes d the current document. Everything computed from the graph goes stale with the new revision.
+func (app *App) loadDocument(d *Document) {
+	app.StopAnimation()
+	app.Selected, app.EdgeStart, app.MovingVertex, app.Focused, app.PenLast = nil, nil, nil, nil, nil
+	app.Graph, app.History, app.Journal, app.Selection = d.Graph, d.History, d.Journal, d.Selection
+	app.Camera, app.Title, app.Caption, app.Filter, app.Timeline = d.Camera, d.Title, d.Caption, d.Filter, d.Timeline
+	app.SavePath, app.FileName = d.SavePath, d.FileName
+	app.revision++
+	app.scriptRevision = app.revision // Switching isn't a change for the change scripts
+	app.savedRevision, app.moved = app.revision, false
+	if d.dirty {
+		app.savedRevision--
+	}
+}
+
+// Reports whether the current document can be put aside: not in the middle of a change (e.g. a drag).
+// A layout running is finished first.
+func (app *App) canSwitchDocument() bool {
+	if app.LayoutRun != nil {
+		app.finishLayoutRun()
+	}
+	return app.History.depth == 0
+}
+
+// Opens a copy of the current document as a new, untitled one in a tab of its own, then runs build
+// on it. The document it came from stays as it was.
+func (app *App) openTab(build func()) {
+	if !app.canSwitchDocument() {
+		return
+	}
+	if app.Documents == nil {
+		app.Documents = []*Document{nil} // The current document's tab, filled when it's stashed
+	}
+	app.Documents[app.CurrentDocument] = app.stashDocument()
+	app.Documents = append(app.Documents, &Document{Graph: app.Graph.Clone(), Camera: app.Camera})
+	app.CurrentDocument = len(app.Documents) - 1
+	app.loadDocument(app.Documents[app.CurrentDocument])
+	build()
+	app.History = History{} // Nothing to undo in a new document, the old graph is in its own tab
+	app.Journal = Journal{}
+}
+
+// Switches to the document in tab k.
+func (app *App) switchDocument(k int) {
+	if k == app.CurrentDocument || k < 0 || k >= len(app.Documents) || !app.canSwitchDocument() {
+		return
+	}
+	app.Documents[app.CurrentDocument] = app.stashDocument()
+	app.CurrentDocument = k
+	app.loadDocument(app.Documents[k])
+	app.Notify(T("document.switched", app.documentName()))
+}
+
+// Switches to the next tab, or the previous one, going around.
+func (app *App) cycleDocument(step int) {
+	if n := len(app.Documents); n > 1 {
+		app.switchDocument(((app.CurrentDocument+step)%n + n) % n)
+	}
+}
+
+// Closes the current tab, first offering to save unsaved changes. The last document can't be closed.
+func (app *App) CloseDocument() {
+	if len(app.Documents) < 2 || !app.canSwitchDocument() {
+		return
+	}
+	app.checkUnsaved(func() {
+		k := app.CurrentDocument
+		app.Documents = append(app.Documents[:k], app.Documents[k+1:]...)
+		app.CurrentDocument = min(k, len(app.Documents)-1)
+		app.loadDocument(app.Documents[app.CurrentDocument])
+		if len(app.Documents) == 1 {
+			app.Documents, app.CurrentDocument = nil, 0
+		}
+		app.Notify(T("document.switched", app.documentName()))
+	})
+}
+
+// Runs then once every document from tab k on was offered to be saved, switching to each one
+// with unsaved changes.
+func (app *App) checkUnsavedDocuments(k int, then func()) {
+	for ; k < len(app.Documents); k++ {
+		if k == app.CurrentDocument && app.Dirty() || k != app.CurrentDocument && app.Documents[k].dirty {
+			break
+		}
+	}
+	if k >= len(app.Documents) {
+		app.checkUnsaved(then) // Only one document, or the last one
+		return
+	}
+	if k != app.CurrentDocument {
+		if app.switchDocument(k); app.CurrentDocument != k {
+			return // In the middle of a change, quitting waits
+		}
+	}
+	app.checkUnsaved(func() { app.checkUnsavedDocuments(k+1, then) })
+}
+
+// Returns the name shown on tab k.
+func (app *App) tabName(k int) string {
+	name, dirty := app.documentName(), app.Dirty()
+	if k != app.CurrentDocument {
+		d := app.Documents[k]
+		name, dirty = d.FileName, d.dirty
+		if name == "" {
+			name = T("file.untitled")
+		}
+	}
+	if dirty {
+		name += " *"
+	}
+	return name
+}
+
+// Returns the left edge and width of each tab, right-aligned in the option bar.
+func (app *App) tabLayout() (xs, widths []float32) {
+	x := float32(screenWidth)
+	for k := len(app.Documents) - 1; k >= 0; k-- {
+		w := float32(textWidth(app.tabName(k)) + 2*optionPadding)
+		x -= w + optionPadding
+		xs, widths = append([]float32{x}, xs...), append([]float32{w}, widths...)
+	}
+	return xs, widths
+}
+
+// Switches to the tab clicked at x, if any. Returns whether there was one.
+func (app *App) ClickTabs(x float64) bool {
+	xs, widths := app.tabLayout()
+	for k := range xs {
+		if float32(x) >= xs[k] && float32(x) < xs[k]+widths[k] {
+			app.switchDocument(k)
+			return true
+		}
+	}
+	return false
+}
+
+// Draws the tabs of the open documents in the option bar, the current one highlighted.
+func (app *App) DrawTabs(screen *ebiten.Image) {
+	top := float32(toolbarHeight())
+	xs, widths := app.tabLayout()
+	for k := range xs {
+		if k == app.CurrentDocument {
+			fillRect(screen, xs[k], top+3, widths[k], optionBarHeight-6, color.RGBA{90, 90, 120, 255}, true)
+		}
+		strokeRect(screen, xs[k], top+3, widths[k], optionBarHeight-6, 1, color.RGBA{140, 140, 140, 255}, true)
+		printAt(screen, app.tabName(k), int(xs[k])+optionPadding, int(top)+4)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+// Condensing opens the quotient graph in a new tab and leaves the graph it came from alone.
+func TestCondenseOpensTab(t *testing.T) {
+	app := &App{Graph: testGraph(4, false, [2]int{0, 1}, [2]int{2, 3})}
+	app.markSaved("graph.json")
+	app.SavePath = "graph.json"
+	app.Condense()
+
+	if len(app.Documents) != 2 || app.CurrentDocument != 1 {
+		t.Fatalf("%d documents, current %d, want 2 and 1", len(app.Documents), app.CurrentDocument)
+	}
+	if n := len(app.Graph.Vertices); n != 2 {
+		t.Errorf("quotient graph has %d vertices, want 2", n)
+	}
+	if app.SavePath != "" || app.FileName != "" || !app.Dirty() || len(app.History.steps) > 0 {
+		t.Errorf("quotient graph: path %q, name %q, dirty %v, %d undo steps; want a new unsaved document",
+			app.SavePath, app.FileName, app.Dirty(), len(app.History.steps))
+	}
+
+	app.cycleDocument(1)
+	if app.CurrentDocument != 0 || len(app.Graph.Vertices) != 4 || app.Graph.EdgeCount() != 2 {
+		t.Errorf("first tab: current %d, %d vertices, %d edges; want 0, 4 and 2",
+			app.CurrentDocument, len(app.Graph.Vertices), app.Graph.EdgeCount())
+	}
+	if app.SavePath != "graph.json" || app.Dirty() {
+		t.Errorf("first tab: path %q, dirty %v; want it saved to graph.json", app.SavePath, app.Dirty())
+	}
+
+	app.CloseDocument() // Saved, so it closes without asking
+	if app.Documents != nil || app.Dialog != nil || len(app.Graph.Vertices) != 2 || !app.Dirty() {
+		t.Errorf("after closing: %d documents, dialog %v, %d vertices, dirty %v; want the quotient graph alone",
+			len(app.Documents), app.Dialog != nil, len(app.Graph.Vertices), app.Dirty())
+	}
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Graphviz DOT:
+
+// What dot, neato and most graph libraries read and write:
+//
+//	digraph G {
+//	  node [shape=circle];
+//	  a [label="Alice", pos="10,20!", fillcolor="#ff0000"];
+//	  a -> b -> {c d} [weight=3];
+//	}
+//
+// Node and edge default attributes apply to the statements after them (within their subgraph),
+// subgraphs are flattened, and an edge to a subgraph goes to each of its nodes. "label" is the label,
+// "pos" ("x,y", y up) the position, and "fillcolor" (or "color") sets the vertex color when it's "#rrggbb".
+// Everything else is kept as attributes. A digraph switches to directed mode. Nodes without a position
+// go on a circle (L lays them out). Ports and graph attributes are ignored.
+// Written files number the nodes from 0, with the label, position and color as attributes, and one
+// edge statement per parallel edge.
+
+var errDOT = errors.New("bad DOT")
+
+// Splits DOT into IDs, edge operators and punctuation. Quoted strings keep their quotes and HTML
+// strings their angle brackets, so they can't be mistaken for keywords.
+func dotTokens(data []byte) ([]string, error) {
+	var tokens []string
+	s := string(data)
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#' || strings.HasPrefix(s[i:], "//"): // Comment to the end of the line
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated comment", errDOT)
+			}
+			i += end + 4
+		case strings.HasPrefix(s[i:], "--") || strings.HasPrefix(s[i:], "->"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.ContainsRune("{}[];,=:", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("%w: unterminated string", errDOT)
+			}
+			tokens = append(tokens, s[i:end+1])
+			i = end + 1
+		case c == '<':
+			depth, end := 0, i
+			for ; end < len(s); end++ {
+				if s[end] == '<' {
+					depth++
+				} else if s[end] == '>' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("%w: unterminated HTML string", errDOT)
+			}
+			tokens = append(tokens, s[i:end+1])
+			i = end + 1
+		default:
+			start := i
+			for i < len(s) && (s[i] == '_' || s[i] == '.' || s[i] >= 0x80 || unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) ||
+				(i == start && s[i] == '-')) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("%w: unexpected %q", errDOT, c)
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// Text of an ID token: quoted strings without their quotes and escapes, HTML strings without their brackets.
+func dotText(token string) string {
+	switch {
+	case strings.HasPrefix(token, `"`):
+		s := token[1 : len(token)-1]
+		s = strings.ReplaceAll(s, "\\\n", "") // Line continuation
+		return strings.ReplaceAll(s, `\"`, `"`)
+	case strings.HasPrefix(token, "<"):
+		return token[1 : len(token)-1]
+	}
+	return token
+}
+
+type dotParser struct {
+	tokens       []string
+	pos          int
+	ig           *ImportedGraph
+	index        map[string]int // Node ID -> vertex
+	nodeDefaults map[string]string
+	edgeDefaults map[string]string
+}
+
+// Returns the next token without taking it, "" at the end.
+func (p *dotParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// Takes the next token.
+func (p *dotParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// Reports whether a token is the keyword (keywords are case-insensitive and never quoted).
+func dotKeyword(token, keyword string) bool {
+	return strings.EqualFold(token, keyword)
+}
+
+// Takes an ID, failing on punctuation or the end.
+func (p *dotParser) id() (string, error) {
+	t := p.next()
+	if t == "" || strings.Contains("{}[];,=:", t) || t == "--" || t == "->" {
+		return "", fmt.Errorf("%w: expected an ID, got %q", errDOT, t)
+	}
+	return dotText(t), nil
+}
+
+// Parses attribute lists, [a=1, b=2][c=3], into attrs.
+func (p *dotParser) attrLists(attrs map[string]string) error {
+	for p.peek() == "[" {
+		p.next()
+		for p.peek() != "]" {
+			key, err := p.id()
+			if err != nil {
+				return err
+			}
+			value := "true"
+			if p.peek() == "=" {
+				p.next()
+				if value, err = p.id(); err != nil {
+					return err
+				}
+			}
+			attrs[key] = value
+			if t := p.peek(); t == "," || t == ";" {
+				p.next()
+			}
+		}
+		p.next()
+	}
+	return nil
+}
+
+// Returns the vertex of a node ID, adding it with the node defaults if it's new.
+func (p *dotParser) node(id string) int {
+	if i, ok := p.index[id]; ok {
+		return i
+	}
+	i := len(p.ig.Vertices)
+	p.index[id] = i
+	p.ig.Vertices = append(p.ig.Vertices, ImportedVertex{Label: id, Attrs: map[string]string{}})
+	p.setNodeAttrs(i, p.nodeDefaults)
+	return i
+}
+
+// Applies DOT attributes to a vertex.
+func (p *dotParser) setNodeAttrs(i int, attrs map[string]string) {
+	v := &p.ig.Vertices[i]
+	for key, value := range attrs {
+		switch key {
+		case "label":
+			if value != `\N` { // The node name, which it already is
+				v.Label = value
+			}
+		case "pos":
+			var x, y float64
+			if _, err := fmt.Sscanf(strings.TrimSuffix(value, "!"), "%g,%g", &x, &y); err == nil {
+				v.X, v.Y, v.HasPos = x, -y, true // DOT has y up
+			} else {
+				v.Attrs[key] = value
+			}
+		case "style":
+			if value != "filled" { // Vertices always are
+				v.Attrs[key] = value
+			}
+		case "fillcolor", "color":
+			if c := parseHexColor(value); c != nil && (key == "fillcolor" || attrs["fillcolor"] == "") {
+				v.Color = c
+			} else {
+				v.Attrs[key] = value
+			}
+		default:
+			v.Attrs[key] = value
+		}
+	}
+}
+
+// Parses a node ID (its port is dropped) or a subgraph, returns the vertices it stands for.
+func (p *dotParser) operand() ([]int, error) {
+	if t := p.peek(); t == "{" || dotKeyword(t, "subgraph") {
+		return p.subgraph()
+	}
+	id, err := p.id()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == ":" { // Port and compass point
+		p.next()
+		if _, err := p.id(); err != nil {
+			return nil, err
+		}
+	}
+	return []int{p.node(id)}, nil
+}
+
+// Parses a subgraph, with its own defaults, returns the vertices in it.
+func (p *dotParser) subgraph() ([]int, error) {
+	if dotKeyword(p.peek(), "subgraph") {
+		p.next()
+		if p.peek() != "{" {
+			if _, err := p.id(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.next() != "{" {
+		return nil, fmt.Errorf("%w: expected {", errDOT)
+	}
+	nodeDefaults, edgeDefaults := maps.Clone(p.nodeDefaults), maps.Clone(p.edgeDefaults)
+	defer func() { p.nodeDefaults, p.edgeDefaults = nodeDefaults, edgeDefaults }()
+	var vertices []int
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, fmt.Errorf("%w: missing }", errDOT)
+		}
+		vs, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vs {
+			if !slices.Contains(vertices, v) {
+				vertices = append(vertices, v)
+			}
+		}
+	}
+	p.next()
+	return vertices, nil
+}
+
+// Parses one statement, returns the vertices it mentions.
+func (p *dotParser) statement() ([]int, error) {
+	defer func() {
+		if p.peek() == ";" {
+			p.next()
+		}
+	}()
+	t := p.peek()
+	for keyword, defaults := range map[string]map[string]string{"graph": nil, "node": p.nodeDefaults, "edge": p.edgeDefaults} {
+		if dotKeyword(t, keyword) && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "[" {
+			p.next()
+			if defaults == nil {
+				defaults = map[string]string{} // Graph attributes are dropped
+			}
+			return nil, p.attrLists(defaults)
+		}
+	}
+	if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "=" { // Graph attribute
+		p.pos += 2
+		_, err := p.id()
+		return nil, err
+	}
+
+	first, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := [][]int{first}
+	for p.peek() == "--" || p.peek() == "->" {
+		p.next()
+		vs, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, vs)
+	}
+	attrs := map[string]string{}
+	if len(operands) == 1 {
+		if err := p.attrLists(attrs); err != nil {
+			return nil, err
+		}
+		if !(dotKeyword(t, "subgraph") || t == "{") {
+			p.setNodeAttrs(first[0], attrs)
+		}
+		return first, nil
+	}
+
+	maps.Copy(attrs, p.edgeDefaults)
+	if err := p.attrLists(attrs); err != nil {
+		return nil, err
+	}
+	var mentioned []int
+	for k := 1; k < len(operands); k++ {
+		for _, a := range operands[k-1] {
+			for _, b := range operands[k] {
+				p.ig.Edges = append(p.ig.Edges, ImportedEdge{A: a, B: b, Attrs: maps.Clone(attrs)})
+			}
+		}
+	}
+	for _, vs := range operands {
+		mentioned = append(mentioned, vs...)
+	}
+	return mentioned, nil
+}
+
+// Parses DOT.
+func parseDOT(data []byte) (*ImportedGraph, error) {
+	tokens, err := dotTokens(data)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotParser{tokens: tokens, ig: &ImportedGraph{}, index: map[string]int{}, nodeDefaults: map[string]string{}, edgeDefaults: map[string]string{}}
+	if dotKeyword(p.peek(), "strict") {
+		p.next()
+	}
+	switch kind := p.next(); {
+	case dotKeyword(kind, "digraph"):
+		p.ig.Directed = true
+	case !dotKeyword(kind, "graph"):
+		return nil, fmt.Errorf("%w: expected graph or digraph, got %q", errDOT, kind)
+	}
+	if p.peek() != "{" {
+		if _, err := p.id(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.subgraph(); err != nil {
+		return nil, err
+	}
+	return p.ig, nil
+}
+
+// Quotes a DOT ID.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Writes DOT attributes, sorted by name.
+func writeDOTAttrs(buf *bytes.Buffer, attrs map[string]string) {
+	keys := slices.Sorted(maps.Keys(attrs))
+	for n, k := range keys {
+		if n > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "%s=%s", dotQuote(k), dotQuote(attrs[k]))
+	}
+}
+
+// Writes the graph as DOT with labels, positions, colors and attributes.
+func writeDOT(buf *bytes.Buffer, g *Graph) {
+	kind, op := "graph", "--"
+	if g.Directed {
+		kind, op = "digraph", "->"
+	}
+	fmt.Fprintf(buf, "%s G {\n", kind)
+	for i, v := range g.Vertices {
+		attrs := maps.Clone(v.Attrs)
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		attrs["label"] = v.Label
+		attrs["pos"] = fmt.Sprintf("%.1f,%.1f!", v.X, -v.Y)
+		attrs["style"], attrs["fillcolor"] = "filled", svgColor(v.Color)
+		fmt.Fprintf(buf, "  %d [", i)
+		writeDOTAttrs(buf, attrs)
+		fmt.Fprintln(buf, "];")
+	}
+	for i := range g.Vertices {
+		for j := range g.Vertices {
+			if j < i && !g.Directed { // Written from the lower index only
+				continue
+			}
+			attrs := maps.Clone(g.EdgeAttrs[Edge(i, j)])
+			if attrs == nil {
+				attrs = map[string]string{}
+			}
+			if w, ok := g.weightOf(i, j); ok {
+				attrs["weight"] = strconv.FormatFloat(w, 'g', -1, 64)
+			}
+			if style, ok := g.EdgeStyles[Edge(i, j)]; ok {
+				attrs["color"], attrs["penwidth"] = svgColor(style.Color), strconv.FormatFloat(style.Width, 'g', -1, 64)
+			}
+			for range g.AdjMatrix[i][j] {
+				fmt.Fprintf(buf, "  %d %s %d", i, op, j)
+				if len(attrs) > 0 {
+					buf.WriteString(" [")
+					writeDOTAttrs(buf, attrs)
+					buf.WriteString("]")
+				}
+				fmt.Fprintln(buf, ";")
+			}
+		}
+	}
+	fmt.Fprintln(buf, "}")
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Edge geometry and hit testing:
+
+// Drawing and hit testing share these, so a curve is clicked where it's drawn.
+
+const (
+	edgeHitDistance   = 10                     // How close a click has to be to an edge
+	doubleClickTime   = 400 * time.Millisecond // Max time between the clicks of a double click
+	doubleClickRadius = 5                      // Max cursor movement between the clicks of a double click
+	curvedEdgeBend    = 0.2                    // How far a curved single edge's control point is off its middle, per length
+)
+
+// Returns the control point of the k-th of count parallel edges between v1 and v2.
+func parallelEdgeControl(v1, v2 Vertex, k, count int) (cx, cy float64) {
+	offset := float64(20 * (k - count/2)) // Offset for parallel edges
+	return (v1.X+v2.X)/2 + offset, (v1.Y+v2.Y)/2 - offset
+}
+
+// Returns the control point of an edge drawn as an arc, bulging to the same side of the way it goes
+// (from the first vertex to the second) by bend times its length.
+func curvedEdgeControl(from, to Vertex, bend float64) (cx, cy float64) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	return (from.X+to.X)/2 + bend*dy, (from.Y+to.Y)/2 - bend*dx
+}
+
+// Returns the control point of the k-th of the count edges drawn between i and j (not a loop).
+// In directed mode, when edges go both ways, each way is drawn on its own side of the line between
+// the two vertices, the edges going the same way fanning out further one after the other, so the
+// arcs and their arrowheads don't overlap.
+func (g *Graph) edgeControl(i, j, k, count int) (cx, cy float64) {
+	if !g.Directed || g.AdjMatrix[i][j] == 0 || g.AdjMatrix[j][i] == 0 {
+		return parallelEdgeControl(g.Vertices[i], g.Vertices[j], k, count)
+	}
+	from, to := g.edgeEnds(i, j, k)
+	if from != i {
+		k -= g.AdjMatrix[i][j] // Counted among the edges going the same way
+	}
+	return curvedEdgeControl(g.Vertices[from], g.Vertices[to], curvedEdgeBend*float64(k+1))
+}
+
+// Returns the control point of the edge between i and j if it's a single edge drawn as an arc.
+func (app *App) curvedEdge(i, j int) (cx, cy float64, ok bool) {
+	g := app.Graph
+	if i == j || g.Multiplicity(i, j) != 1 || !app.edgeStyle(i, j).Curved {
+		return 0, 0, false
+	}
+	from, to := g.edgeEnds(i, j, 0)
+	cx, cy = curvedEdgeControl(g.Vertices[from], g.Vertices[to], curvedEdgeBend)
+	return cx, cy, true
+}
+
+// Returns the two control points of the k-th of count loops at (x, y).
+func loopControls(x, y float64, k, count int) (cxLeft, cyLeft, cxRight, cyRight float64) {
+	// Find angle to middle of loop
+	angleOffset := float64(k) * (2 * math.Pi / float64(count))
+	// Find angle to either side (for 2 Brezier control points)
+	angleLeft := angleOffset - math.Pi/10
+	angleRight := angleOffset + math.Pi/10
+	// Find control points
+	cxLeft = x + 60*math.Cos(angleLeft)
+	cyLeft = y + 60*math.Sin(angleLeft)
+	cxRight = x + 60*math.Cos(angleRight)
+	cyRight = y + 60*math.Sin(angleRight)
+	return cxLeft, cyLeft, cxRight, cyRight
+}
+
+// Points along the quadratic Bézier curve from a to b with control point c, as drawn.
+func quadraticPoints(a, c, b point) []point {
+	points := make([]point, curveSegments+1)
+	for n := range points {
+		t := float64(n) / curveSegments
+		points[n] = point{(1-t)*(1-t)*a.X + 2*(1-t)*t*c.X + t*t*b.X, (1-t)*(1-t)*a.Y + 2*(1-t)*t*c.Y + t*t*b.Y}
+	}
+	return points
+}
+
+// Points along the cubic Bézier curve from a to b with control points c1 and c2, as drawn.
+func cubicPoints(a, c1, c2, b point) []point {
+	points := make([]point, curveSegments+1)
+	for n := range points {
+		t := float64(n) / curveSegments
+		points[n] = point{
+			(1-t)*(1-t)*(1-t)*a.X + 3*(1-t)*(1-t)*t*c1.X + 3*(1-t)*t*t*c2.X + t*t*t*b.X,
+			(1-t)*(1-t)*(1-t)*a.Y + 3*(1-t)*(1-t)*t*c1.Y + 3*(1-t)*t*t*c2.Y + t*t*t*b.Y,
+		}
+	}
+	return points
+}
+
+// Finds the edge (or loop) drawn closest to (x, y), through the spatial index (spatial.go).
+// Returns its end vertices (start first, for a directed edge) and the distance, ok is false if none is within edgeHitDistance.
+func (app *App) NearestEdge(x, y float64) (v1, v2 int, dist float64, ok bool) {
+	defer app.timeHitTest(time.Now())
+	seg, dist, ok := app.spatialIndex().nearestEdge(app, x, y)
+	return seg.From, seg.To, dist, ok
+}
+
+// Finds an edge (or loop) drawn near (x, y).
+// Returns its end vertices, ok is false if there is none.
+func (app *App) EdgeAt(x, y float64) (v1, v2 int, ok bool) {
+	v1, v2, _, ok = app.NearestEdge(x, y)
+	return v1, v2, ok
+}
+
+// Splits an edge into two by inserting a new vertex at (x, y).
+// A weighted edge's halves get half its weight each, so paths through it are as long as before.
+func (app *App) SubdivideEdge(v1, v2 int, x, y float64) {
+	app.BeginTransaction(T("undo.subdivide"))
+	defer app.Commit()
+	w, weighted := app.Graph.weightOf(v1, v2)
+	if !app.Do(Action{Kind: ActionDeleteEdge, V1: v1, V2: v2}) {
+		return
+	}
+	mid := len(app.Graph.Vertices)
+	app.AddVertexAt(x, y)
+	app.Do(Action{Kind: ActionAddEdge, V1: v1, V2: mid})
+	app.Do(Action{Kind: ActionAddEdge, V1: mid, V2: v2})
+	if weighted {
+		app.Do(Action{Kind: ActionWeightEdge, V1: v1, V2: mid, Weight: w / 2})
+		app.Do(Action{Kind: ActionWeightEdge, V1: mid, V2: v2, Weight: w / 2})
+	}
+}
+
+// Reports whether the current left click is the second click of a double click.
+func (app *App) isDoubleClick(x, y float64) bool {
+	double := time.Since(app.LastClickTime) < doubleClickTime &&
+		math.Hypot(x-app.lastClickX, y-app.lastClickY) < doubleClickRadius
+	app.LastClickTime, app.lastClickX, app.lastClickY = time.Now(), x, y
+	if double {
+		app.LastClickTime = time.Time{} // A triple click isn't two double clicks
+	}
+	return double
+}
+
+// Shape of an edge as drawn, for exports.
+type edgeCurve struct {
+	A, B    int
+	Cubic   bool     // Cubic Bézier through P[1], P[2], otherwise a quadratic one through P[1]
+	Line    bool     // Straight line, control points unused
+	P       [4]point // Start, controls, end
+	Label   string   // Multiplicity label ("×k") for collapsed edges, drawn at LabelAt
+	LabelAt point
+}
+
+// Returns the shapes of all edges, the same way DrawEdges draws them.
+func (app *App) edgeCurves() []edgeCurve {
+	g := app.Graph
+	var curves []edgeCurve
+	for i, v1 := range g.Vertices {
+		for j := i; j < len(g.Vertices); j++ {
+			v2 := g.Vertices[j]
+			count := g.Multiplicity(i, j)
+			if count == 0 || !app.edgeShown(i, j) {
+				continue
+			}
+			a, b := point{v1.X, v1.Y}, point{v2.X, v2.Y}
+			if app.collapsed(count) { // One edge (or loop) with a "×k" label
+				c := edgeCurve{A: i, B: j, Line: true, P: [4]point{a, a, b, b}, Label: "×" + strconv.Itoa(count), LabelAt: point{(a.X + b.X) / 2, (a.Y + b.Y) / 2}}
+				if i == j {
+					cxLeft, cyLeft, cxRight, cyRight := loopControls(a.X, a.Y, 0, 1)
+					c.Line, c.Cubic = false, true
+					c.P = [4]point{a, {cxLeft, cyLeft}, {cxRight, cyRight}, a}
+					c.LabelAt = point{a.X + 50, a.Y} // Tip of the loop
+				}
+				curves = append(curves, c)
+				continue
+			}
+			switch {
+			case i == j:
+				for k := 0; k < count; k++ {
+					cxLeft, cyLeft, cxRight, cyRight := loopControls(a.X, a.Y, k, count)
+					curves = append(curves, edgeCurve{A: i, B: j, Cubic: true, P: [4]point{a, {cxLeft, cyLeft}, {cxRight, cyRight}, a}})
+				}
+			case count == 1:
+				if cx, cy, ok := app.curvedEdge(i, j); ok {
+					curves = append(curves, edgeCurve{A: i, B: j, P: [4]point{a, {cx, cy}, {cx, cy}, b}})
+				} else {
+					curves = append(curves, edgeCurve{A: i, B: j, Line: true, P: [4]point{a, a, b, b}})
+				}
+			default:
+				for k := 0; k < count; k++ {
+					cx, cy := g.edgeControl(i, j, k, count)
+					curves = append(curves, edgeCurve{A: i, B: j, P: [4]point{a, {cx, cy}, {cx, cy}, b}})
+				}
+			}
+		}
+	}
+	return curves
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Edge weights:
+
+// Edges carry a weight, 1 unless set. Like styles and times, the weight is per vertex pair, so
+// parallel edges share it; in directed mode it's per way, so a→b and b→a can weigh differently
+// (shortest paths and the critical path follow the edges' own weights). The Set Weight tool asks for
+// the weight of the edge clicked; set weights are drawn at the middle of the edge.
+
+// Returns the key of the weight of the edges from v1 to v2: the pair in either order, in directed
+// mode the ordered pair (so unlike other per-edge keys, A can be the larger index).
+func (g *Graph) weightKey(v1, v2 int) EdgeKey {
+	if g.Directed {
+		return EdgeKey{v1, v2}
+	}
+	return Edge(v1, v2)
+}
+
+// Returns the weight set on the edges from v1 to v2, ok is false if none is.
+func (g *Graph) weightOf(v1, v2 int) (w float64, ok bool) {
+	w, ok = g.EdgeWeights[g.weightKey(v1, v2)]
+	return w, ok
+}
+
+// Returns the weight of the edges from v1 to v2.
+func (g *Graph) EdgeWeight(v1, v2 int) float64 {
+	if w, ok := g.weightOf(v1, v2); ok {
+		return w
+	}
+	return 1
+}
+
+// Sets the weight of the edges from v1 to v2 (between them, if undirected).
+func (g *Graph) SetEdgeWeight(v1, v2 int, w float64) error {
+	if err := g.CheckVertices(v1, v2); err != nil {
+		return err
+	}
+	if g.AdjMatrix[v1][v2] <= 0 {
+		return fmt.Errorf("%w: %s - %s", ErrNoEdge, g.Vertices[v1].Label, g.Vertices[v2].Label)
+	}
+	if g.EdgeWeights == nil {
+		g.EdgeWeights = map[EdgeKey]float64{}
+	}
+	g.EdgeWeights[g.weightKey(v1, v2)] = w
+	return nil
+}
+
+// Asks for the weight of the edges between two vertices.
+func (app *App) askEdgeWeight(v1, v2 int) {
+	g := app.Graph
+	app.Notify(T("edge_weight.prompt", g.Vertices[v1].Label, g.Vertices[v2].Label, formatMetric(g.EdgeWeight(v1, v2))))
+	a, b := g.Vertices[v1], g.Vertices[v2]
+	x, y := app.toScreen((a.X+b.X)/2, (a.Y+b.Y)/2)
+	app.OpenTextInput(&TextInput{X: x, Y: y, OnCommit: func(text string) {
+		w, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(w) || math.IsInf(w, 0) {
+			app.Warn(T("warn.not_a_number", text))
+			return
+		}
+		app.Do(Action{Kind: ActionWeightEdge, V1: v1, V2: v2, Weight: w})
+	}})
+}
+
+// Draws the set edge weights at the middle of their edges (at the tip of loops).
+func (app *App) DrawEdgeWeights(screen *ebiten.Image) {
+	g := app.Graph
+	for key, w := range g.EdgeWeights {
+		if !app.edgeShown(key.A, key.B) {
+			continue
+		}
+		a, b := g.Vertices[key.A], g.Vertices[key.B]
+		x, y := (a.X+b.X)/2, (a.Y+b.Y)/2
+		if cx, cy, ok := app.curvedEdge(key.A, key.B); ok { // Middle of the arc
+			x, y = (x+cx)/2, (y+cy)/2
+		} else if g.Directed && key.A != key.B && g.AdjMatrix[key.B][key.A] > 0 { // Middle of this way's arc
+			cx, cy := g.edgeControl(key.A, key.B, 0, g.Multiplicity(key.A, key.B))
+			x, y = (x+cx)/2, (y+cy)/2
+		}
+		if key.A == key.B {
+			x += 50 // Tip of the first loop
+		}
+		if app.collapsed(g.Multiplicity(key.A, key.B)) {
+			y -= 18 // Above the multiplicity label
+		}
+		text := formatMetric(w)
+		tw := float32(textWidth(text) + 6)
+		fillRect(screen, float32(x)-tw/2, float32(y)-9, tw, 18, color.RGBA{30, 30, 30, 200}, true)
+		printAt(screen, text, int(x)-textWidth(text)/2, int(y)-8)
+	}
+}
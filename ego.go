@@ -0,0 +1,57 @@
+package main
+
+import "strconv"
+
+// Ego networks:
+
+// Shift+E extracts the ego network of the selected vertex: it asks for a radius k, selects every
+// vertex within k edges of it (edges read both ways), then extracts the selection like E does,
+// keeping the edges between those vertices, as a new, untitled document in a tab of its own
+// (documents.go). The whole graph stays as it was in its tab.
+
+// Returns the vertices within k edges of v, edges read both ways, in index order.
+func (g *Graph) Neighborhood(v, k int) []int {
+	dist := map[int]int{v: 0}
+	queue := []int{v}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if dist[u] == k {
+			continue
+		}
+		for w := range g.Vertices {
+			if _, seen := dist[w]; !seen && g.Multiplicity(u, w) > 0 {
+				dist[w] = dist[u] + 1
+				queue = append(queue, w)
+			}
+		}
+	}
+	var ball []int
+	for w := range g.Vertices {
+		if _, ok := dist[w]; ok {
+			ball = append(ball, w)
+		}
+	}
+	return ball
+}
+
+// Asks for a radius, then extracts the neighborhood of the selected vertex.
+func (app *App) ExtractEgoNetwork() {
+	if len(app.Selection.Vertices) != 1 {
+		app.Warn(T("ego.no_vertex"))
+		return
+	}
+	center := app.Selection.sortedVertices()[0]
+	app.askPredicate(T("ego.prompt", app.Graph.Vertices[center].Label), func(text string) {
+		k, err := strconv.Atoi(text)
+		if err != nil || k < 0 {
+			app.Warn(T("warn.not_a_number", text))
+			return
+		}
+		in := toSet(app.Graph.Neighborhood(center, k))
+		app.openTab(func() {
+			app.SelectWhere(func(i int) bool { return in[i] })
+			app.ExtractSelection()
+		})
+	})
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Eulerian trails:
+//
+//	V: look for an Eulerian circuit or trail and walk it edge by edge.
+//
+// A walk using every edge exactly once exists when the vertices with edges are all connected and the
+// degrees are balanced: for a circuit, back where it started, every degree even; for a trail, exactly
+// two odd degrees, and it goes from one of them to the other. In directed mode the walk follows the
+// edge directions: a circuit needs every in-degree equal to the out-degree, a trail one vertex with one
+// more edge out (where it starts) and one with one more in (where it ends); connected still ignores the
+// directions. Loops and parallel edges are walked like any other edge. The walk is played as an
+// animation, one step per edge: the edges walked so far are highlighted, the vertex reached stands out
+// and the edges build up in the log panel. If there's no such walk, the vertices at fault are
+// highlighted and the message says why.
+
+// Returns the vertices whose degrees keep the graph from having an Eulerian circuit: those of odd
+// degree (a loop counts twice), in directed mode those with in-degree different from out-degree.
+func (g *Graph) eulerianUnbalanced() []int {
+	var unbalanced []int
+	for i := range g.Vertices {
+		if g.Directed && g.Degree(i) != g.InDegree(i) || !g.Directed && (g.Degree(i)+g.AdjMatrix[i][i])%2 != 0 {
+			unbalanced = append(unbalanced, i)
+		}
+	}
+	return unbalanced
+}
+
+// Returns where a walk using every edge once has to start, -1 if the degrees don't allow one or there
+// are no edges. closed reports whether the walk ends where it started.
+func (g *Graph) eulerianStart() (start int, closed bool) {
+	unbalanced := g.eulerianUnbalanced()
+	switch {
+	case len(unbalanced) == 0:
+		for i := range g.Vertices {
+			if g.Degree(i) > 0 {
+				return i, true
+			}
+		}
+		return -1, false
+	case len(unbalanced) != 2:
+		return -1, false
+	case !g.Directed:
+		return unbalanced[0], false
+	}
+	// One more out than in at the start, one more in than out at the end
+	a, b := unbalanced[0], unbalanced[1]
+	if g.Degree(b)-g.InDegree(b) == 1 {
+		a, b = b, a
+	}
+	if g.Degree(a)-g.InDegree(a) != 1 || g.InDegree(b)-g.Degree(b) != 1 {
+		return -1, false
+	}
+	return a, false
+}
+
+// Returns a walk using every edge exactly once, as a vertex sequence, along the edge directions in
+// directed mode. nil if there is none or the graph has no edges. closed reports whether it's a circuit
+// (first = last). Hierholzer's algorithm.
+func (g *Graph) EulerianTrail() (trail []int, closed bool) {
+	start, closed := g.eulerianStart()
+	if start < 0 {
+		return nil, false
+	}
+	left := make([][]int, len(g.Vertices)) // Edges not walked yet
+	edges := 0
+	for i, row := range g.AdjMatrix {
+		left[i] = slices.Clone(row)
+		for j, count := range row {
+			if g.Directed || j >= i {
+				edges += count
+			}
+		}
+	}
+	stack := []int{start}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		next := slices.IndexFunc(left[v], func(count int) bool { return count > 0 })
+		if next < 0 {
+			trail = append(trail, v)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		left[v][next]--
+		if !g.Directed && next != v {
+			left[next][v]--
+		}
+		stack = append(stack, next)
+	}
+	if len(trail) != edges+1 { // Some edges are out of reach
+		return nil, false
+	}
+	slices.Reverse(trail) // Built from the end
+	return trail, closed
+}
+
+// Looks for an Eulerian circuit or trail and animates it, or shows why there's none.
+func (app *App) ShowEulerianTrail() {
+	g := app.Graph
+	trail, closed := g.EulerianTrail()
+	if trail == nil {
+		app.explainNoEulerianTrail()
+		return
+	}
+	first, last := g.Vertices[trail[0]].Label, g.Vertices[trail[len(trail)-1]].Label
+	title := T("euler.trail", first, last)
+	if closed {
+		title = T("euler.circuit", first)
+	}
+	arrow := " - "
+	if g.Directed {
+		arrow = " → "
+	}
+	visitedColor, currentColor := app.PaletteColor(2), highlightEdgeColor
+	a := &Animation{Title: title}
+	walked := map[EdgeKey]bool{}
+	for k, v := range trail {
+		text, line := T("euler.start", g.Vertices[v].Label), g.Vertices[v].Label
+		if k > 0 {
+			u := trail[k-1]
+			walked[Edge(u, v)] = true
+			edge := g.Vertices[u].Label + arrow + g.Vertices[v].Label
+			text, line = T("euler.step", k, len(trail)-1, edge), fmt.Sprintf("%d. %s", k, edge)
+		}
+		step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: maps.Clone(walked), Text: text}
+		for _, w := range trail[:k] {
+			step.Vertices[w] = visitedColor
+		}
+		step.Vertices[v] = currentColor
+		a.Steps = append(a.Steps, step)
+		a.Log = append(a.Log, line)
+	}
+	app.PlayAnimation(a)
+	app.Notify(title)
+}
+
+// Highlights what keeps the graph from having an Eulerian trail and says what it is.
+func (app *App) explainNoEulerianTrail() {
+	g := app.Graph
+	if g.EdgeCount() == 0 {
+		app.Notify(T("euler.no_edges"))
+		return
+	}
+	step := AnimationStep{Vertices: map[int]color.RGBA{}}
+	if start, _ := g.eulerianStart(); start >= 0 {
+		// The degrees are fine, the edges aren't all connected: mark those out of reach of the start
+		reached := map[int]bool{start: true}
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for w := range g.Vertices {
+				if g.Multiplicity(v, w) > 0 && !reached[w] {
+					reached[w] = true
+					queue = append(queue, w)
+				}
+			}
+		}
+		for i := range g.Vertices {
+			if !reached[i] && g.Degree(i)+g.InDegree(i) > 0 {
+				step.Vertices[i] = highlightEdgeColor
+			}
+		}
+		step.Text = T("euler.apart")
+	} else {
+		unbalanced := g.eulerianUnbalanced()
+		labels := make([]string, len(unbalanced))
+		for k, i := range unbalanced {
+			step.Vertices[i] = highlightEdgeColor
+			labels[k] = g.Vertices[i].Label
+		}
+		step.Text = T("euler.odd", len(unbalanced), strings.Join(labels, ", "))
+		if g.Directed {
+			step.Text = T("euler.unbalanced", len(unbalanced), strings.Join(labels, ", "))
+		}
+	}
+	app.PlayAnimation(&Animation{Title: T("euler.none"), Steps: []AnimationStep{step}})
+	app.Warn(step.Text)
+}
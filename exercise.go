@@ -0,0 +1,214 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Exercises:
+
+// An exercise is a task with goals the graph is checked against while it's being drawn,
+// e.g. "draw a 3-regular graph on 8 vertices". F2 picks one, the panel at the top right
+// shows every goal as met or not (with the reason), F2 again with an exercise open closes it.
+//
+// Exercises are JSON files: the built-in ones in exercises/ (embedded), plus any in
+// graph-tool/exercises/ in the user config directory, next to the settings:
+//
+//	{
+//	  "Title": "Properly 3-color the Petersen graph",
+//	  "Template": "template.petersen",
+//	  "Goals": [{"Check": "proper_coloring", "Value": 3}]
+//	}
+//
+// Title may be a locale key. Template (optional) names the template the exercise starts from.
+// Checks: vertices, edges, regular, min_degree, max_degree (Value is the number),
+// proper_coloring (at most Value colors), connected, simple, tree, bipartite.
+
+//go:embed exercises/*.json
+var exerciseFiles embed.FS
+
+type Exercise struct {
+	Title    string
+	Template string
+	Goals    []Goal
+}
+
+type Goal struct {
+	Check string
+	Value int
+}
+
+// Result of checking one goal.
+type GoalResult struct {
+	Goal   string // What's asked
+	Met    bool
+	Reason string // Why it isn't met (empty if it is)
+}
+
+// Reads the built-in exercises and the user's own.
+func loadExercises() []Exercise {
+	var exercises []Exercise
+	parse := func(data []byte) {
+		var e Exercise
+		if json.Unmarshal(data, &e) == nil && len(e.Goals) > 0 {
+			exercises = append(exercises, e)
+		}
+	}
+	entries, _ := exerciseFiles.ReadDir("exercises")
+	for _, entry := range entries {
+		data, _ := exerciseFiles.ReadFile("exercises/" + entry.Name())
+		parse(data)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths, _ := filepath.Glob(filepath.Join(dir, "graph-tool", "exercises", "*.json"))
+		for _, path := range paths {
+			if data, err := os.ReadFile(path); err == nil {
+				parse(data)
+			}
+		}
+	}
+	return exercises
+}
+
+// Checks one goal against the graph.
+func checkGoal(g *Graph, goal Goal) GoalResult {
+	r := GoalResult{Goal: T("goal." + goal.Check), Met: true}
+	if strings.Contains(r.Goal, "%d") { // Goals with a number
+		r.Goal = T("goal."+goal.Check, goal.Value)
+	}
+	fail := func(reason string) {
+		r.Met, r.Reason = false, reason
+	}
+	n := len(g.Vertices)
+
+	switch goal.Check {
+	case "vertices":
+		if n != goal.Value {
+			fail(T("goal.has_vertices", n))
+		}
+	case "edges":
+		if m := g.EdgeCount(); m != goal.Value {
+			fail(T("goal.has_edges", m))
+		}
+	case "regular", "min_degree", "max_degree":
+		for i, v := range g.Vertices {
+			d := g.Degree(i)
+			if (goal.Check == "regular" && d != goal.Value) || (goal.Check == "min_degree" && d < goal.Value) || (goal.Check == "max_degree" && d > goal.Value) {
+				fail(T("goal.vertex_degree", v.Label, d))
+				break
+			}
+		}
+	case "proper_coloring":
+		if classes := g.ColorClassCount(); classes > goal.Value {
+			fail(T("goal.uses_colors", classes))
+			break
+		}
+		if conflicts := g.ColoringConflicts(); len(conflicts) > 0 {
+			fail(T("goal.same_color", g.Vertices[conflicts[0].A].Label, g.Vertices[conflicts[0].B].Label))
+		}
+	case "connected":
+		if !g.IsConnected() {
+			fail(T("goal.components", g.ComponentCount()))
+		}
+	case "simple":
+		if !g.IsSimple() {
+			fail(T("goal.not_simple"))
+		}
+	case "tree":
+		if !g.IsConnected() || !g.IsSimple() || g.EdgeCount() != n-1 {
+			fail(T("goal.not_tree"))
+		}
+	case "bipartite":
+		if _, ok := g.Bipartition(); !ok {
+			fail(T("goal.odd_cycle"))
+		}
+	default:
+		fail(T("goal.unknown", goal.Check))
+	}
+	return r
+}
+
+// Checks every goal of the open exercise. Results are kept until the next edit.
+func (app *App) exerciseResults() []GoalResult {
+	if app.Exercise == nil {
+		return nil
+	}
+	if app.exerciseChecked != app.revision || app.exerciseResultsCache == nil {
+		app.exerciseResultsCache = nil
+		for _, goal := range app.Exercise.Goals {
+			app.exerciseResultsCache = append(app.exerciseResultsCache, checkGoal(app.Graph, goal))
+		}
+		app.exerciseChecked = app.revision
+	}
+	return app.exerciseResultsCache
+}
+
+// Opens an exercise, starting from its template if it has one.
+func (app *App) StartExercise(e Exercise) {
+	app.Exercise = &e
+	app.exerciseResultsCache = nil
+	for _, t := range templates {
+		if t.Name == e.Template {
+			app.LoadTemplate(t, nil)
+		}
+	}
+	app.Announce(T(e.Title))
+}
+
+// Picks an exercise, or closes the open one.
+func (app *App) ShowExerciseDialog() {
+	if app.Exercise != nil {
+		app.Exercise = nil
+		return
+	}
+	d := &Dialog{Message: T("exercise.dialog")}
+	for _, e := range loadExercises() {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T(e.Title), Action: func() { app.StartExercise(e) }})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
+
+// Draws the exercise panel at the top right of the canvas.
+func (app *App) DrawExercise(screen *ebiten.Image) {
+	results := app.exerciseResults()
+	if results == nil {
+		return
+	}
+	done := true
+	lines := []string{T(app.Exercise.Title)}
+	for _, r := range results {
+		mark := "[x] "
+		if !r.Met {
+			mark, done = "[ ] ", false
+		}
+		line := mark + r.Goal
+		if r.Reason != "" {
+			line += " (" + r.Reason + ")"
+		}
+		lines = append(lines, line)
+	}
+	if done {
+		lines = append(lines, T("exercise.done"))
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, textWidth(line))
+	}
+	w, h := float32(width+10), float32(16*len(lines)+6)
+	x, y := float32(screenWidth)-w-10, float32(canvasTop())+10
+	border := color.RGBA{200, 200, 200, 255}
+	if done {
+		border = color.RGBA{0, 200, 0, 255}
+	}
+	fillRect(screen, x, y, w, h, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, w, h, 1, border, true)
+	printAt(screen, strings.Join(lines, "\n"), int(x)+5, int(y)+3)
+}
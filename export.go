@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Image export:
+
+// Ctrl+E saves the drawing as PNG, SVG or TikZ, graph.png, graph.svg or graph.tex unless named otherwise
+// in the file dialog (filedialog.go).
+// graph.tgf, graph.gml, graph.dot and graph.json save the graph itself, for other graph tools (tgf.go, gml.go, dot.go, nodelink.go),
+// graph-coords.csv just the positions (coords.go).
+// Exports show the drawing as colored and sized on the canvas (mappings included),
+// with the title, caption and legends, but without the toolbars or the selection.
+
+const (
+	exportPNGFile  = "graph.png"
+	exportSVGFile  = "graph.svg"
+	exportTikZFile = "graph.tex"
+	exportTGFFile  = "graph.tgf"
+	exportGMLFile  = "graph.gml"
+	exportJSONFile = "graph.json"
+	exportDOTFile  = "graph.dot"
+)
+
+// Draws the graph with title, caption and legends, everything an export shows.
+func (app *App) DrawScene(screen *ebiten.Image) {
+	app.DrawGraph(screen)
+	app.drawSceneText(screen)
+}
+
+// Draws the edges and vertices, in world space.
+func (app *App) DrawGraph(screen *ebiten.Image) {
+	app.DrawEdges(screen)
+	app.DrawEdgeWeights(screen)
+
+	mapped := app.mappedColors()
+	for _, i := range app.drawOrder() {
+		v := app.Graph.Vertices[i]
+		if !app.vertexShown(i) {
+			continue
+		}
+		fillCircle(screen, float32(v.X), float32(v.Y), float32(app.vertexRadius(i)), app.vertexColor(i, mapped), true)
+		r := app.labelRect(i)
+		printAt(screen, v.Label, int(r.X), int(r.Y))
+		if app.View.ShowDegrees {
+			app.drawDegreeBadge(screen, i)
+		}
+		switch {
+		case app.drawStepLabel(screen, i): // Takes the spot under the vertex
+		case app.View.ColoringMode:
+			app.drawColorClass(screen, i)
+		case v.Weight != 1:
+			app.drawWeight(screen, i)
+		}
+	}
+}
+
+// Draws the title, animation status and legends, in screen space.
+func (app *App) drawSceneText(screen *ebiten.Image) {
+	app.DrawTitle(screen)
+	app.DrawAnimationStatus(screen)
+	app.DrawAnimationLog(screen)
+	app.DrawLegend(screen)
+}
+
+// Color every vertex is exported with.
+func (app *App) exportVertexColors() []color.RGBA {
+	if mapped := app.mappedColors(); mapped != nil {
+		return mapped
+	}
+	colors := make([]color.RGBA, len(app.Graph.Vertices))
+	for i, v := range app.Graph.Vertices {
+		colors[i] = v.Color
+	}
+	return colors
+}
+
+// Saves the scene as a PNG image.
+func (app *App) exportPNG(path string) error {
+	selection := app.Selection
+	app.Selection = Selection{}
+	defer func() { app.Selection = selection }()
+
+	img := ebiten.NewImage(screenWidth, screenHeight)
+	defer img.Deallocate()
+	img.Fill(color.Black)
+	app.DrawScene(img)
+
+	pix := make([]byte, 4*screenWidth*screenHeight)
+	img.ReadPixels(pix)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, &image.RGBA{Pix: pix, Stride: 4 * screenWidth, Rect: image.Rect(0, 0, screenWidth, screenHeight)}); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Formats a color for SVG, without its opacity.
+func svgColor(c color.RGBA) string {
+	c = opaque(c)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Escapes text for SVG.
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// Path data of an edge.
+func (c edgeCurve) svgPath() string {
+	p := c.P
+	switch {
+	case c.Line:
+		return fmt.Sprintf("M %.1f %.1f L %.1f %.1f", p[0].X, p[0].Y, p[3].X, p[3].Y)
+	case c.Cubic:
+		return fmt.Sprintf("M %.1f %.1f C %.1f %.1f %.1f %.1f %.1f %.1f", p[0].X, p[0].Y, p[1].X, p[1].Y, p[2].X, p[2].Y, p[3].X, p[3].Y)
+	}
+	return fmt.Sprintf("M %.1f %.1f Q %.1f %.1f %.1f %.1f", p[0].X, p[0].Y, p[1].X, p[1].Y, p[3].X, p[3].Y)
+}
+
+// Writes the scene as SVG.
+func (app *App) writeSVG(buf *bytes.Buffer) {
+	g := app.Graph
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`+"\n",
+		screenWidth, screenHeight, screenWidth, screenHeight)
+	fmt.Fprintf(buf, `<rect width="100%%" height="100%%" fill="black"/>`+"\n")
+
+	for _, c := range app.edgeCurves() {
+		s := app.edgeStyle(c.A, c.B)
+		width := s.Width
+		if !c.Line && c.Label == "" {
+			width = 1 // Curves are drawn thin on the canvas too
+		}
+		fmt.Fprintf(buf, `<path d="%s" fill="none" %s stroke-width="%.1f"/>`+"\n", c.svgPath(), svgPaint("stroke", s.Color), width)
+		if c.Label != "" {
+			w := float64(textWidth(c.Label) + 6)
+			fmt.Fprintf(buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="18" fill="#1e1e1e" stroke="%s"/>`+"\n", c.LabelAt.X-w/2, c.LabelAt.Y-9, w, svgColor(s.Color))
+			fmt.Fprintf(buf, `<text x="%.1f" y="%.1f" fill="white" text-anchor="middle">%s</text>`+"\n", c.LabelAt.X, c.LabelAt.Y+4, svgEscape(c.Label))
+		}
+	}
+
+	colors := app.exportVertexColors()
+	for _, i := range app.drawOrder() {
+		v := g.Vertices[i]
+		if !app.vertexShown(i) {
+			continue
+		}
+		fmt.Fprintf(buf, `<circle cx="%.1f" cy="%.1f" r="%.1f" %s/>`+"\n", v.X, v.Y, app.vertexRadius(i), svgPaint("fill", colors[i]))
+		r := app.labelRect(i)
+		fmt.Fprintf(buf, `<text x="%.1f" y="%.1f" fill="white">%s</text>`+"\n", r.X, r.Y+labelHeight, svgEscape(v.Label))
+	}
+
+	if app.Title != "" {
+		fmt.Fprintf(buf, `<text x="10" y="%.1f" fill="white" font-size="16">%s</text>`+"\n", canvasTop()+18, svgEscape(app.Title))
+	}
+	if app.Caption != "" {
+		fmt.Fprintf(buf, `<text x="%d" y="%d" fill="white" text-anchor="middle">%s</text>`+"\n", screenWidth/2, screenHeight-12, svgEscape(app.Caption))
+	}
+
+	legends := app.legends()
+	xs, ys := legendLayout(legends, screenWidth, screenHeight)
+	for n, l := range legends {
+		w, h := l.size()
+		x, y := xs[n], ys[n]
+		fmt.Fprintf(buf, `<g transform="translate(%.1f %.1f)">`+"\n", x, y)
+		fmt.Fprintf(buf, `<rect width="%.1f" height="%.1f" fill="#1e1e1e" stroke="#c8c8c8"/>`+"\n", w, h)
+		fmt.Fprintf(buf, `<text x="5" y="15" fill="white">%s</text>`+"\n", svgEscape(l.Title))
+		if !l.Range {
+			for k, e := range l.Entries {
+				ey := 22 + 16*float64(k)
+				if e.Color.A > 0 {
+					fmt.Fprintf(buf, `<rect x="8" y="%.1f" width="12" height="12" fill="%s"/>`+"\n", ey+2, svgColor(e.Color))
+				}
+				fmt.Fprintf(buf, `<text x="26" y="%.1f" fill="white">%s</text>`+"\n", ey+12, svgEscape(e.Label))
+			}
+		} else {
+			lo, hi := l.Entries[0], l.Entries[1]
+			if lo.Radius > 0 {
+				cy := 20 + hi.Radius
+				fmt.Fprintf(buf, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="white"/>`+"\n", 8+lo.Radius, cy, lo.Radius)
+				fmt.Fprintf(buf, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="white"/>`+"\n", w-8-hi.Radius, cy, hi.Radius)
+			} else {
+				fmt.Fprintf(buf, `<linearGradient id="gradient%d">`, n)
+				for s, c := range gradientStops {
+					fmt.Fprintf(buf, `<stop offset="%.2f" stop-color="%s"/>`, float64(s)/float64(len(gradientStops)-1), svgColor(c))
+				}
+				fmt.Fprintf(buf, "</linearGradient>\n")
+				fmt.Fprintf(buf, `<rect x="8" y="22" width="%.1f" height="12" fill="url(#gradient%d)"/>`+"\n", w-16, n)
+			}
+			fmt.Fprintf(buf, `<text x="8" y="%.1f" fill="white">%s</text>`+"\n", h-8, svgEscape(lo.Label))
+			fmt.Fprintf(buf, `<text x="%.1f" y="%.1f" fill="white" text-anchor="end">%s</text>`+"\n", w-8, h-8, svgEscape(hi.Label))
+		}
+		fmt.Fprintf(buf, "</g>\n")
+	}
+	fmt.Fprintf(buf, "</svg>\n")
+}
+
+// Name of a color in the TikZ output.
+func tikzColor(c color.RGBA) string {
+	c = opaque(c)
+	return fmt.Sprintf("c%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Escapes text for LaTeX.
+func texEscape(s string) string {
+	return strings.NewReplacer(`\`, `\textbackslash{}`, "&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`,
+		"_", `\_`, "{", `\{`, "}", `\}`, "~", `\textasciitilde{}`, "^", `\textasciicircum{}`, "×", `$\times$`).Replace(s)
+}
+
+// Writes the scene as a TikZ picture, on white paper: black text, same coordinates as the canvas (in pt).
+func (app *App) writeTikZ(buf *bytes.Buffer) {
+	g := app.Graph
+	colors := app.exportVertexColors()
+
+	// Colors first, TikZ needs them defined
+	defined := map[color.RGBA]bool{}
+	define := func(c color.RGBA) {
+		c = opaque(c)
+		if !defined[c] {
+			defined[c] = true
+			fmt.Fprintf(buf, "\\definecolor{%s}{RGB}{%d,%d,%d}\n", tikzColor(c), c.R, c.G, c.B)
+		}
+	}
+	for _, c := range colors {
+		define(c)
+	}
+	for _, c := range app.edgeCurves() {
+		define(app.edgeStyle(c.A, c.B).Color)
+	}
+	legends := app.legends()
+	for _, l := range legends {
+		for _, e := range l.Entries {
+			define(e.Color)
+		}
+	}
+	if len(legends) > 0 {
+		for _, c := range gradientStops {
+			define(c)
+		}
+	}
+
+	fmt.Fprintf(buf, "\\begin{tikzpicture}[x=1pt, y=-1pt, font=\\scriptsize]\n")
+	for _, c := range app.edgeCurves() {
+		s := app.edgeStyle(c.A, c.B)
+		width := s.Width
+		if !c.Line && c.Label == "" {
+			width = 1
+		}
+		p := c.P
+		fmt.Fprintf(buf, "\\draw[%s%s, line width=%.1fpt] (%.1f,%.1f)", tikzColor(s.Color), tikzOpacity(s.Color), width, p[0].X, p[0].Y)
+		switch {
+		case c.Line:
+			fmt.Fprintf(buf, " -- ")
+		case c.Cubic:
+			fmt.Fprintf(buf, " .. controls (%.1f,%.1f) and (%.1f,%.1f) .. ", p[1].X, p[1].Y, p[2].X, p[2].Y)
+		default: // Quadratic, as the equivalent cubic
+			c1 := point{p[0].X + 2*(p[1].X-p[0].X)/3, p[0].Y + 2*(p[1].Y-p[0].Y)/3}
+			c2 := point{p[3].X + 2*(p[1].X-p[3].X)/3, p[3].Y + 2*(p[1].Y-p[3].Y)/3}
+			fmt.Fprintf(buf, " .. controls (%.1f,%.1f) and (%.1f,%.1f) .. ", c1.X, c1.Y, c2.X, c2.Y)
+		}
+		fmt.Fprintf(buf, "(%.1f,%.1f);\n", p[3].X, p[3].Y)
+		if c.Label != "" {
+			fmt.Fprintf(buf, "\\node[fill=white, draw=%s, inner sep=1pt] at (%.1f,%.1f) {%s};\n", tikzColor(s.Color), c.LabelAt.X, c.LabelAt.Y, texEscape(c.Label))
+		}
+	}
+	for _, i := range app.drawOrder() {
+		v := g.Vertices[i]
+		if !app.vertexShown(i) {
+			continue
+		}
+		fmt.Fprintf(buf, "\\fill[%s%s] (%.1f,%.1f) circle[radius=%.1fpt];\n", tikzColor(colors[i]), tikzOpacity(colors[i]), v.X, v.Y, app.vertexRadius(i))
+		if v.LabelOffset == nil && app.View.LabelPlacement == LabelInside {
+			fmt.Fprintf(buf, "\\node at (%.1f,%.1f) {%s};\n", v.X, v.Y, texEscape(v.Label))
+		} else {
+			r := app.labelRect(i)
+			fmt.Fprintf(buf, "\\node at (%.1f,%.1f) {%s};\n", r.X+r.W/2, r.Y+r.H/2, texEscape(v.Label))
+		}
+	}
+
+	if app.Title != "" {
+		fmt.Fprintf(buf, "\\node[anchor=north west, font=\\bfseries] at (10,%.1f) {%s};\n", canvasTop()+6, texEscape(app.Title))
+	}
+	if app.Caption != "" {
+		fmt.Fprintf(buf, "\\node[anchor=south] at (%d,%d) {%s};\n", screenWidth/2, screenHeight-8, texEscape(app.Caption))
+	}
+
+	xs, ys := legendLayout(legends, screenWidth, screenHeight)
+	for n, l := range legends {
+		w, h := l.size()
+		x, y := xs[n], ys[n]
+		fmt.Fprintf(buf, "\\draw (%.1f,%.1f) rectangle (%.1f,%.1f);\n", x, y, x+w, y+h)
+		fmt.Fprintf(buf, "\\node[anchor=north west] at (%.1f,%.1f) {%s};\n", x+3, y+3, texEscape(l.Title))
+		if !l.Range {
+			for k, e := range l.Entries {
+				ey := y + 22 + 16*float64(k)
+				if e.Color.A > 0 {
+					fmt.Fprintf(buf, "\\fill[%s] (%.1f,%.1f) rectangle (%.1f,%.1f);\n", tikzColor(e.Color), x+8, ey+2, x+20, ey+14)
+				}
+				fmt.Fprintf(buf, "\\node[anchor=west] at (%.1f,%.1f) {%s};\n", x+24, ey+8, texEscape(e.Label))
+			}
+			continue
+		}
+		lo, hi := l.Entries[0], l.Entries[1]
+		if lo.Radius > 0 {
+			fmt.Fprintf(buf, "\\draw (%.1f,%.1f) circle[radius=%.1fpt];\n", x+8+lo.Radius, y+20+hi.Radius, lo.Radius)
+			fmt.Fprintf(buf, "\\draw (%.1f,%.1f) circle[radius=%.1fpt];\n", x+w-8-hi.Radius, y+20+hi.Radius, hi.Radius)
+		} else {
+			segment := (w - 16) / float64(len(gradientStops)-1)
+			for s := 0; s+1 < len(gradientStops); s++ {
+				sx := x + 8 + segment*float64(s)
+				fmt.Fprintf(buf, "\\shade[left color=%s, right color=%s] (%.1f,%.1f) rectangle (%.1f,%.1f);\n",
+					tikzColor(gradientStops[s]), tikzColor(gradientStops[s+1]), sx, y+22, sx+segment, y+34)
+			}
+		}
+		fmt.Fprintf(buf, "\\node[anchor=south west] at (%.1f,%.1f) {%s};\n", x+6, y+h-4, texEscape(lo.Label))
+		fmt.Fprintf(buf, "\\node[anchor=south east] at (%.1f,%.1f) {%s};\n", x+w-6, y+h-4, texEscape(hi.Label))
+	}
+	fmt.Fprintf(buf, "\\end{tikzpicture}\n")
+}
+
+// Writes an export file in the format its extension names, in any case, and reports how it went.
+// Other extensions are refused. Graph files that open again become what Ctrl+S saves to.
+func (app *App) export(path string) {
+	var err error
+	var buf bytes.Buffer
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".png":
+		err = app.exportPNG(path)
+	case ".svg":
+		app.writeSVG(&buf)
+	case ".tex":
+		app.writeTikZ(&buf)
+	case ".tgf":
+		writeTGF(&buf, app.Graph)
+	case ".gml":
+		writeGML(&buf, app.Graph)
+	case ".dot":
+		writeDOT(&buf, app.Graph)
+	case ".json":
+		err = writeNodeLink(&buf, app.Graph)
+	default:
+		err = fmt.Errorf("%w: %s", errUnknownFormat, filepath.Ext(path))
+	}
+	if err == nil && ext != ".png" {
+		err = os.WriteFile(path, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		app.Warn(T("warn.write_file", path, err))
+		return
+	}
+	if canSave(path) { // A graph file, which can be opened again and Ctrl+S saves to
+		app.markSaved(path)
+		app.SavePath = path
+	}
+	app.Notify(T("info.saved", path))
+}
+
+// Asks which format to export.
+// While an animation runs, every step can be saved too.
+func (app *App) ShowExportDialog() {
+	d := &Dialog{
+		Message: T("export.dialog"),
+		Buttons: []DialogButton{
+			{Label: "PNG", Action: func() { app.saveFile(exportPNGFile, app.export) }},
+			{Label: "SVG", Action: func() { app.saveFile(exportSVGFile, app.export) }},
+			{Label: "TikZ", Action: func() { app.saveFile(exportTikZFile, app.export) }},
+			{Label: "TGF", Action: func() { app.saveFile(exportTGFFile, app.export) }},
+			{Label: "GML", Action: func() { app.saveFile(exportGMLFile, app.export) }},
+			{Label: "JSON", Action: func() { app.saveFile(exportJSONFile, app.export) }},
+			{Label: "DOT", Action: func() { app.saveFile(exportDOTFile, app.export) }},
+			{Label: T("export.coords"), Action: func() { app.saveFile(exportCoordsFile, app.exportCoordinates) }},
+			{Label: T("export.session"), Action: app.exportSession},
+		},
+	}
+	if app.Animation != nil {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T("export.steps"), Action: app.exportAnimationSteps})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
@@ -0,0 +1,504 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Vertex expressions:
+
+// A small expression language over the vertices, for computed attributes ("weight * degree")
+// and filters ("degree > 3 && color != red"). An expression is evaluated for one vertex at a time.
+//
+//	Values:    numbers, 'text' or "text", true, false, color names (red, blue, ...)
+//	Names:     index, label, color, x, y, random (a fixed number in [0, 1) per vertex index), the metrics (degree, component, strong_component, core, closeness, betweenness, weight)
+//	           and the vertex attribute columns, computed ones included
+//	Operators: + - * / %, == != < <= > >=, && || ! (also and, or, not), parentheses
+//
+// Colors compare by their nearest named color, so "color == red" matches any reddish vertex.
+// Unset number attributes are NaN: every comparison with them is false.
+
+var (
+	errExprSyntax = errors.New("syntax error")
+	errExprType   = errors.New("wrong kind of value")
+)
+
+var namedColors = []struct {
+	Name  string
+	Color color.RGBA
+}{
+	{"red", color.RGBA{230, 25, 25, 255}}, {"orange", color.RGBA{245, 130, 20, 255}},
+	{"yellow", color.RGBA{240, 220, 30, 255}}, {"green", color.RGBA{40, 180, 60, 255}},
+	{"cyan", color.RGBA{40, 200, 220, 255}}, {"blue", color.RGBA{30, 80, 220, 255}},
+	{"purple", color.RGBA{140, 50, 180, 255}}, {"magenta", color.RGBA{230, 50, 200, 255}},
+	{"pink", color.RGBA{250, 170, 190, 255}}, {"brown", color.RGBA{140, 90, 40, 255}},
+	{"black", color.RGBA{0, 0, 0, 255}}, {"white", color.RGBA{255, 255, 255, 255}},
+	{"gray", color.RGBA{128, 128, 128, 255}}, {"grey", color.RGBA{128, 128, 128, 255}},
+}
+
+// Returns the name of the closest named color.
+func colorName(c color.RGBA) string {
+	c = opaque(c)
+	best, bestDist := "", math.Inf(1)
+	for _, n := range namedColors {
+		dr, dg, db := float64(c.R)-float64(n.Color.R), float64(c.G)-float64(n.Color.G), float64(c.B)-float64(n.Color.B)
+		if d := dr*dr + dg*dg + db*db; d < bestDist {
+			best, bestDist = n.Name, d
+		}
+	}
+	return best
+}
+
+// Returns a pseudo-random number in [0, 1) for a vertex index, the same every time (splitmix64).
+func vertexRandom(i int) float64 {
+	z := uint64(i) + 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
+
+// Evaluated expression, for vertex i. Values are float64, string, bool or color.RGBA.
+type Expr func(env *exprEnv, i int) (any, error)
+
+// What names in expressions refer to. Whole metrics and computed columns are evaluated
+// the first time an expression asks for them, then kept for the other vertices.
+type exprEnv struct {
+	g         *Graph
+	metrics   map[string][]float64
+	computed  map[string][]any
+	computing map[string]bool // Computed columns being evaluated, to catch ones referring to themselves
+}
+
+func newExprEnv(g *Graph) *exprEnv {
+	return &exprEnv{g: g, metrics: map[string][]float64{}, computed: map[string][]any{}, computing: map[string]bool{}}
+}
+
+// Returns the value of a name for vertex i, ok is false if there's no such name.
+func (env *exprEnv) lookup(name string, i int) (value any, ok bool, err error) {
+	v := env.g.Vertices[i]
+	switch name {
+	case "index":
+		return float64(i), true, nil
+	case "random":
+		return vertexRandom(i), true, nil
+	case "label":
+		return v.Label, true, nil
+	case "color":
+		return v.Color, true, nil
+	case "x":
+		return v.X, true, nil
+	case "y":
+		return v.Y, true, nil
+	case "true", "false":
+		return name == "true", true, nil
+	}
+	for _, m := range metrics {
+		if metricID(m) == name {
+			if env.metrics[name] == nil {
+				env.metrics[name] = m.Values(env.g)
+			}
+			return env.metrics[name][i], true, nil
+		}
+	}
+	c, ok := env.g.Schema.Column(name, false)
+	if !ok {
+		return nil, false, nil
+	}
+	if c.Expr != "" {
+		values, err := env.computedColumn(c)
+		if err != nil {
+			return nil, true, err
+		}
+		return values[i], true, nil
+	}
+	text := v.Attrs[name]
+	switch c.Type {
+	case AttrNumber:
+		return c.Number(text), true, nil
+	case AttrBool:
+		if f := c.Number(text); !math.IsNaN(f) {
+			return f == 1, true, nil
+		}
+		return false, true, nil // Unset flags are off
+	case AttrColor:
+		if clr := parseHexColor(strings.TrimSpace(text)); clr != nil {
+			return *clr, true, nil
+		}
+		return text, true, nil
+	}
+	return text, true, nil
+}
+
+// Evaluates a computed column for every vertex.
+func (env *exprEnv) computedColumn(c AttrColumn) ([]any, error) {
+	if values, ok := env.computed[c.Name]; ok {
+		return values, nil
+	}
+	if env.computing[c.Name] {
+		return nil, fmt.Errorf("%s refers to itself", c.Name)
+	}
+	env.computing[c.Name] = true
+	defer delete(env.computing, c.Name)
+
+	expr, err := ParseExpr(c.Expr)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(env.g.Vertices))
+	for i := range values {
+		if values[i], err = expr(env, i); err != nil {
+			return nil, err
+		}
+	}
+	env.computed[c.Name] = values
+	return values, nil
+}
+
+// Formats an expression value as attribute text.
+func exprText(value any) string {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case color.RGBA:
+		return svgColor(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// Returns the attribute type values of an expression result have.
+func exprType(value any) AttrType {
+	switch value.(type) {
+	case float64:
+		return AttrNumber
+	case bool:
+		return AttrBool
+	case color.RGBA:
+		return AttrColor
+	}
+	return AttrString
+}
+
+// Parsing:
+
+var exprPairs = []string{"==", "!=", "<=", ">=", "&&", "||"} // Two-character operators
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+// Splits an expression into tokens: numbers, names, quoted strings and operators.
+func tokenizeExpr(s string) ([]string, error) {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+		case r == '\'' || r == '"':
+			i++
+			for i < len(runes) && runes[i] != r {
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("%w: unclosed string", errExprSyntax)
+			}
+			i++
+		case i+1 < len(runes) && slices.Contains(exprPairs, string(runes[i:i+2])):
+			i += 2
+		case strings.ContainsRune("+-*/%<>!()", r):
+			i++
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q", errExprSyntax, r)
+		}
+		tokens = append(tokens, string(runes[start:i]))
+	}
+	return tokens, nil
+}
+
+// Parses an expression.
+func ParseExpr(s string) (Expr, error) {
+	tokens, err := tokenizeExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w at %q", errExprSyntax, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// Returns the next token, "" at the end.
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// Consumes the next token if it's one of ops, and returns it.
+func (p *exprParser) accept(ops ...string) string {
+	t := p.peek()
+	for _, op := range ops {
+		if strings.EqualFold(t, op) {
+			p.pos++
+			return op
+		}
+	}
+	return ""
+}
+
+// Parses operands separated by operators of the same precedence, left to right.
+func (p *exprParser) binary(operand func() (Expr, error), ops ...string) (Expr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.accept(ops...)
+		if op == "" {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr(canonicalOp(op), left, right)
+	}
+}
+
+// Spells the word operators as symbols.
+func canonicalOp(op string) string {
+	switch op {
+	case "and":
+		return "&&"
+	case "or":
+		return "||"
+	case "not":
+		return "!"
+	}
+	return op
+}
+
+func (p *exprParser) or() (Expr, error)  { return p.binary(p.and, "||", "or") }
+func (p *exprParser) and() (Expr, error) { return p.binary(p.comparison, "&&", "and") }
+func (p *exprParser) sum() (Expr, error) { return p.binary(p.term, "+", "-") }
+func (p *exprParser) term() (Expr, error) {
+	return p.binary(p.unary, "*", "/", "%")
+}
+
+// Comparisons don't chain: "a < b < c" is an error.
+func (p *exprParser) comparison() (Expr, error) {
+	left, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if op == "" {
+		return left, nil
+	}
+	right, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	return binaryExpr(op, left, right), nil
+}
+
+func (p *exprParser) unary() (Expr, error) {
+	op := p.accept("!", "not", "-")
+	if op == "" {
+		return p.primary()
+	}
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	op = canonicalOp(op)
+	return func(env *exprEnv, i int) (any, error) {
+		v, err := operand(env, i)
+		if err != nil {
+			return nil, err
+		}
+		switch x := v.(type) {
+		case bool:
+			if op == "!" {
+				return !x, nil
+			}
+		case float64:
+			if op == "-" {
+				return -x, nil
+			}
+		}
+		return nil, fmt.Errorf("%w for %s", errExprType, op)
+	}, nil
+}
+
+func (p *exprParser) primary() (Expr, error) {
+	t := p.peek()
+	if t == "" {
+		return nil, fmt.Errorf("%w: unexpected end", errExprSyntax)
+	}
+	p.pos++
+	switch r := []rune(t)[0]; {
+	case t == "(":
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept(")") == "" {
+			return nil, fmt.Errorf("%w: missing )", errExprSyntax)
+		}
+		return inner, nil
+	case unicode.IsDigit(r) || r == '.':
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad number %q", errExprSyntax, t)
+		}
+		return func(*exprEnv, int) (any, error) { return f, nil }, nil
+	case r == '\'' || r == '"':
+		s := t[1 : len(t)-1]
+		return func(*exprEnv, int) (any, error) { return s, nil }, nil
+	case unicode.IsLetter(r) || r == '_':
+		return func(env *exprEnv, i int) (any, error) {
+			v, ok, err := env.lookup(t, i)
+			if err != nil || ok {
+				return v, err
+			}
+			for _, n := range namedColors {
+				if n.Name == strings.ToLower(t) {
+					return n.Color, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown name %q", t)
+		}, nil
+	}
+	return nil, fmt.Errorf("%w at %q", errExprSyntax, t)
+}
+
+// Returns the expression applying a binary operator.
+func binaryExpr(op string, left, right Expr) Expr {
+	return func(env *exprEnv, i int) (any, error) {
+		a, err := left(env, i)
+		if err != nil {
+			return nil, err
+		}
+		if ab, ok := a.(bool); ok && (op == "&&" || op == "||") && ab == (op == "||") {
+			return ab, nil // Short circuit
+		}
+		b, err := right(env, i)
+		if err != nil {
+			return nil, err
+		}
+		return applyOp(op, a, b)
+	}
+}
+
+// Applies a binary operator to two values.
+func applyOp(op string, a, b any) (any, error) {
+	// Colors compare by name, with each other or with text ("red")
+	if ca, ok := a.(color.RGBA); ok {
+		a = colorName(ca)
+	}
+	if cb, ok := b.(color.RGBA); ok {
+		b = colorName(cb)
+	}
+
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			break
+		}
+		switch op {
+		case "+":
+			return x + y, nil
+		case "-":
+			return x - y, nil
+		case "*":
+			return x * y, nil
+		case "/":
+			return x / y, nil
+		case "%":
+			return math.Mod(x, y), nil
+		case "==":
+			return x == y, nil
+		case "!=":
+			return x != y, nil
+		case "<":
+			return x < y, nil
+		case "<=":
+			return x <= y, nil
+		case ">":
+			return x > y, nil
+		case ">=":
+			return x >= y, nil
+		}
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			break
+		}
+		switch op {
+		case "+":
+			return x + y, nil
+		case "==":
+			return strings.EqualFold(x, y), nil
+		case "!=":
+			return !strings.EqualFold(x, y), nil
+		case "<":
+			return x < y, nil
+		case "<=":
+			return x <= y, nil
+		case ">":
+			return x > y, nil
+		case ">=":
+			return x >= y, nil
+		}
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			break
+		}
+		switch op {
+		case "&&":
+			return x && y, nil
+		case "||":
+			return x || y, nil
+		case "==":
+			return x == y, nil
+		case "!=":
+			return x != y, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", errExprType, op)
+}
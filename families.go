@@ -0,0 +1,203 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Named graphs:
+
+// "Named graph..." among the templates (Ctrl+N) builds the standard families from their parameters:
+// complete graphs K_n, complete bipartite graphs K_{m,n}, cycles C_n, paths P_n, wheels W_n (a hub and
+// n rim vertices) and hypercubes Q_d. Each gets the layout it's usually drawn with: K_n and C_n on a
+// circle, K_{m,n} in two columns, P_n in a row, W_n with its hub in the middle, and Q_d projected so
+// that each dimension is a direction of its own (the square, the cube in perspective, and so on).
+
+const (
+	familyMaxVertices  = 100
+	familyMaxDimension = 6 // Q_6 has 64 vertices and 192 edges, past that it's a hairball
+	familySpacing      = 90
+)
+
+type Family struct {
+	Name    string // Locale key
+	Prompt  string // Locale key of the parameter prompt
+	Default string // Parameters filled in
+	Params  int    // How many numbers Build takes
+	// Returns the graph for the parameters laid out around (cx, cy), ok is false if there's no such graph
+	Build func(p []int, cx, cy float64) (positions []point, edges [][2]int, ok bool)
+}
+
+// Edges of a complete graph on the vertices from..to-1.
+func completeEdges(from, to int) [][2]int {
+	var edges [][2]int
+	for i := from; i < to; i++ {
+		for j := i + 1; j < to; j++ {
+			edges = append(edges, [2]int{i, j})
+		}
+	}
+	return edges
+}
+
+// Edges of a cycle through the vertices from..to-1 in order.
+func cycleEdges(from, to int) [][2]int {
+	var edges [][2]int
+	for i := from; i < to; i++ {
+		next := i + 1
+		if next == to {
+			next = from
+		}
+		edges = append(edges, [2]int{i, next})
+	}
+	return edges
+}
+
+// Radius of a circle with n vertices familySpacing apart, at least r, as much as fits the canvas.
+func circleRadius(n int, r float64) float64 {
+	return math.Min(math.Max(r, familySpacing*float64(n)/(2*math.Pi)), (screenHeight-canvasTop())/2-40)
+}
+
+var families = []Family{
+	{Name: "family.complete", Prompt: "family.complete_prompt", Default: "6", Params: 1, Build: func(p []int, cx, cy float64) ([]point, [][2]int, bool) {
+		n := p[0]
+		if n < 1 {
+			return nil, nil, false
+		}
+		return circle(n, cx, cy, circleRadius(n, 150)), completeEdges(0, n), true
+	}},
+	{Name: "family.bipartite", Prompt: "family.bipartite_prompt", Default: "3 3", Params: 2, Build: func(p []int, cx, cy float64) ([]point, [][2]int, bool) {
+		m, n := p[0], p[1]
+		if m < 1 || n < 1 {
+			return nil, nil, false
+		}
+		spacing := math.Min(familySpacing, (screenHeight-canvasTop()-80)/float64(max(m, n)))
+		column := func(k int, x float64) []point {
+			positions := make([]point, k)
+			for i := range positions {
+				positions[i] = point{x, cy + spacing*(float64(i)-float64(k-1)/2)}
+			}
+			return positions
+		}
+		var edges [][2]int
+		for i := range m {
+			for j := range n {
+				edges = append(edges, [2]int{i, m + j})
+			}
+		}
+		return append(column(m, cx-150), column(n, cx+150)...), edges, true
+	}},
+	{Name: "family.cycle", Prompt: "family.cycle_prompt", Default: "6", Params: 1, Build: func(p []int, cx, cy float64) ([]point, [][2]int, bool) {
+		n := p[0]
+		if n < 3 {
+			return nil, nil, false
+		}
+		return circle(n, cx, cy, circleRadius(n, 150)), cycleEdges(0, n), true
+	}},
+	{Name: "family.path", Prompt: "family.path_prompt", Default: "6", Params: 1, Build: func(p []int, cx, cy float64) ([]point, [][2]int, bool) {
+		n := p[0]
+		if n < 1 {
+			return nil, nil, false
+		}
+		spacing := math.Min(familySpacing, float64(screenWidth-80)/float64(max(n-1, 1)))
+		positions := make([]point, n)
+		var edges [][2]int
+		for i := range positions {
+			positions[i] = point{cx + spacing*(float64(i)-float64(n-1)/2), cy}
+			if i > 0 {
+				edges = append(edges, [2]int{i - 1, i})
+			}
+		}
+		return positions, edges, true
+	}},
+	{Name: "family.wheel", Prompt: "family.wheel_prompt", Default: "6", Params: 1, Build: func(p []int, cx, cy float64) ([]point, [][2]int, bool) {
+		n := p[0] // Rim vertices
+		if n < 3 {
+			return nil, nil, false
+		}
+		positions := append([]point{{cx, cy}}, circle(n, cx, cy, circleRadius(n, 150))...)
+		edges := cycleEdges(1, n+1)
+		for i := 1; i <= n; i++ {
+			edges = append(edges, [2]int{0, i})
+		}
+		return positions, edges, true
+	}},
+	{Name: "family.hypercube", Prompt: "family.hypercube_prompt", Default: "3", Params: 1, Build: func(p []int, cx, cy float64) ([]point, [][2]int, bool) {
+		d := p[0]
+		if d < 0 || d > familyMaxDimension {
+			return nil, nil, false
+		}
+		// Bit k of a vertex moves it along direction k, the directions spread over half a turn
+		// and shortening a little each, so no two vertices land on the same spot
+		n := 1 << d
+		dirs := make([]point, d)
+		for k := range dirs {
+			angle := math.Pi * float64(k) / float64(d)
+			length := 160 * math.Pow(0.8, float64(k)) * 4 / float64(d+2)
+			dirs[k] = point{length * math.Cos(angle), length * math.Sin(angle)}
+		}
+		positions := make([]point, n)
+		var edges [][2]int
+		for v := range n {
+			var x, y float64
+			for k, dir := range dirs {
+				if v&(1<<k) != 0 {
+					x, y = x+dir.X, y+dir.Y
+				} else {
+					x, y = x-dir.X, y-dir.Y
+				}
+				if w := v ^ (1 << k); w > v {
+					edges = append(edges, [2]int{v, w})
+				}
+			}
+			positions[v] = point{cx + x/2, cy + y/2}
+		}
+		return positions, edges, true
+	}},
+}
+
+// Asks for a family's parameters, then replaces the drawing with its graph.
+func (app *App) askFamily(f Family) {
+	app.Notify(T(f.Prompt))
+	app.OpenTextInput(&TextInput{Text: f.Default, X: screenWidth/2 - 30, Y: screenHeight / 2, OnCommit: func(text string) {
+		fields := strings.Fields(text)
+		if len(fields) != f.Params {
+			app.Warn(T("warn.family_params", T(f.Prompt)))
+			return
+		}
+		params := make([]int, len(fields))
+		for i, field := range fields {
+			k, err := strconv.Atoi(field)
+			if err != nil {
+				app.Warn(T("warn.not_a_number", field))
+				return
+			}
+			if k > familyMaxVertices {
+				app.Warn(T("warn.family_size", familyMaxVertices))
+				return
+			}
+			params[i] = k
+		}
+		cx, cy := float64(screenWidth)/2, (canvasTop()+screenHeight)/2
+		positions, edges, ok := f.Build(params, cx, cy)
+		if !ok {
+			app.Warn(T("warn.family_params", T(f.Prompt)))
+			return
+		}
+		if len(positions) > familyMaxVertices {
+			app.Warn(T("warn.family_size", familyMaxVertices))
+			return
+		}
+		app.LoadTemplate(Template{Name: f.Name, Build: func(float64, float64) ([]point, [][2]int) { return positions, edges }}, nil)
+	}})
+}
+
+// Asks which named graph to build.
+func (app *App) ShowFamilyDialog() {
+	d := &Dialog{Message: T("family.dialog")}
+	for _, f := range families {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T(f.Name), Action: func() { app.askFamily(f) }})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// File dialogs:
+
+// Opening, saving and exporting ask for the file in the system's file dialog: zenity or kdialog on
+// Linux, AppleScript on macOS, Windows Forms through PowerShell on Windows. Ebiten has none, so like
+// the clipboard this goes through command line tools. The dialog runs in the background and the path
+// comes back to the game loop (mutations.go), so the window keeps drawing meanwhile. Without any of
+// the tools (or in a browser build) a text field asks for the path instead, filled in with the usual
+// file name; Ctrl+Shift+O always asks that way, for typing a path or a Neo4j URL.
+
+// Command showing an open or save dialog that prints the path picked, nil if there's no tool for it.
+func fileDialogCommand(save bool, title, name string) []string {
+	var args []string
+	switch runtime.GOOS {
+	case "windows":
+		kind := "OpenFileDialog"
+		if save {
+			kind = "SaveFileDialog"
+		}
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := fmt.Sprintf("Add-Type -AssemblyName System.Windows.Forms; $d = New-Object System.Windows.Forms.%s; $d.Title = %s; $d.FileName = %s; if ($d.ShowDialog() -eq 'OK') { $d.FileName }",
+			kind, quote(title), quote(name))
+		args = []string{"powershell", "-NoProfile", "-STA", "-Command", script}
+	case "darwin":
+		quote := func(s string) string { return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"` }
+		script := fmt.Sprintf("POSIX path of (choose file with prompt %s)", quote(title))
+		if save {
+			script = fmt.Sprintf("POSIX path of (choose file name with prompt %s default name %s)", quote(title), quote(name))
+		}
+		args = []string{"osascript", "-e", script}
+	case "js", "wasip1":
+		return nil
+	default:
+		if _, err := exec.LookPath("zenity"); err == nil {
+			args = []string{"zenity", "--file-selection", "--title=" + title}
+			if save {
+				args = append(args, "--save", "--confirm-overwrite", "--filename="+name)
+			}
+		} else if save {
+			args = []string{"kdialog", "--title", title, "--getsavefilename", name}
+		} else {
+			args = []string{"kdialog", "--title", title, "--getopenfilename", "."}
+		}
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil
+	}
+	return args
+}
+
+// Asks for a file to open, or to save to starting from name, then calls then with the path picked.
+// Nothing happens if the dialog is canceled.
+func (app *App) chooseFile(save bool, name string, then func(path string)) {
+	title := T("file.open")
+	if save {
+		title = T("file.save")
+	}
+	args := fileDialogCommand(save, title, name)
+	if args == nil {
+		app.askPath(name, then)
+		return
+	}
+	go func() {
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		path := strings.TrimSpace(string(out))
+		var exit *exec.ExitError
+		if err != nil && !errors.As(err, &exit) { // Canceling exits with an error code, that's fine
+			log.Printf("%s: %v", args[0], err)
+		}
+		if path != "" {
+			app.Post(func(app *App) { then(path) })
+		}
+	}()
+}
+
+// Asks for a path in a text field, filled in with name.
+func (app *App) askPath(name string, then func(path string)) {
+	app.Notify(T("file.path_prompt"))
+	app.OpenTextInput(&TextInput{Text: name, X: screenWidth/2 - 30, Y: screenHeight / 2, OnCommit: func(path string) {
+		if path = strings.TrimSpace(path); path != "" {
+			then(path)
+		}
+	}})
+}
+
+// Asks for a file to open.
+func (app *App) openFile(then func(path string)) {
+	app.chooseFile(false, "", then)
+}
+
+// Asks where to save a file, starting from name. The path handed on ends in name's extension,
+// added if the one picked is different, so it's written in the format asked for.
+func (app *App) saveFile(name string, then func(path string)) {
+	ext := filepath.Ext(name)
+	app.chooseFile(true, name, func(path string) {
+		if !strings.EqualFold(filepath.Ext(path), ext) {
+			path += ext
+		}
+		then(path)
+	})
+}
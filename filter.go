@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Vertex filter:
+
+// Ctrl+F opens the filter bar at the top of the canvas. A query is an expression (expr.go) that's true
+// for the vertices to keep: "degree > 3 && color != red", "component == 2", "label == 'A' or weight >= 2".
+// The other vertices and their edges are hidden, or dimmed (Shift+Ctrl+F switches),
+// and an empty query shows everything again. Up and Down in the bar go through the queries used
+// this session. Like the time slider it's only a view, hidden vertices can't be clicked or hovered.
+
+const maxSavedFilters = 20
+
+type Filter struct {
+	Query    string
+	Dim      bool // Dim the other vertices instead of hiding them
+	expr     Expr
+	revision int    // Revision the matches were computed at
+	keep     []bool // Vertices matching the query
+}
+
+// Matches a filter query against every vertex. Evaluation errors count as not matching.
+func (f *Filter) matches(g *Graph) ([]bool, error) {
+	env := newExprEnv(g)
+	keep := make([]bool, len(g.Vertices))
+	var firstErr error
+	for i := range g.Vertices {
+		v, err := f.expr(env, i)
+		if err == nil {
+			if b, ok := v.(bool); ok {
+				keep[i] = b
+				continue
+			}
+			err = fmt.Errorf("%w: the query isn't true or false", errExprType)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return keep, firstErr
+}
+
+// Reports whether vertex i is filtered out.
+func (app *App) filteredOut(i int) bool {
+	f := app.Filter
+	if f == nil {
+		return false
+	}
+	if f.keep == nil || f.revision != app.revision || len(f.keep) != len(app.Graph.Vertices) {
+		f.keep, _ = f.matches(app.Graph)
+		f.revision = app.revision
+	}
+	return !f.keep[i]
+}
+
+// Applies a query, or removes the filter if it's empty.
+func (app *App) SetFilter(query string) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		app.Filter = nil
+		app.Notify(T("filter.off"))
+		return
+	}
+	expr, err := ParseExpr(query)
+	if err != nil {
+		app.Warn(T("warn.expr", query, err))
+		return
+	}
+	f := &Filter{Query: query, expr: expr, revision: app.revision}
+	if app.Filter != nil {
+		f.Dim = app.Filter.Dim
+	}
+	if f.keep, err = f.matches(app.Graph); err != nil {
+		app.Warn(T("warn.expr", query, err))
+		return
+	}
+	app.Filter = f
+
+	app.SavedFilters = slices.DeleteFunc(app.SavedFilters, func(q string) bool { return q == query })
+	app.SavedFilters = append(app.SavedFilters, query)
+	if len(app.SavedFilters) > maxSavedFilters {
+		app.SavedFilters = app.SavedFilters[1:]
+	}
+	msg := T("filter.on", query, app.filterCount(), len(app.Graph.Vertices))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Number of vertices the filter keeps.
+func (app *App) filterCount() int {
+	n := 0
+	for i := range app.Graph.Vertices {
+		if !app.filteredOut(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// Opens the filter bar, filled with the current query.
+func (app *App) OpenFilterBar() {
+	t := &TextInput{X: 60, Y: canvasTop() + 4, OnCommit: app.SetFilter, History: app.SavedFilters}
+	if app.Filter != nil {
+		t.Text = app.Filter.Query
+	}
+	app.Notify(T("filter.prompt"))
+	app.OpenTextInput(t)
+}
+
+// Switches between hiding and dimming the filtered out vertices.
+func (app *App) ToggleFilterDim() {
+	if app.Filter == nil {
+		return
+	}
+	app.toggleView(&app.Filter.Dim, "filter.dim")
+}
+
+// Draws the active query and how many vertices it keeps, at the bottom right of the canvas.
+func (app *App) DrawFilterStatus(screen *ebiten.Image) {
+	if app.Filter == nil {
+		return
+	}
+	text := T("filter.status", app.Filter.Query, app.filterCount(), len(app.Graph.Vertices))
+	printAt(screen, text, screenWidth-textWidth(text)-10, screenHeight-20)
+}
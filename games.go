@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Graph games:
+
+// G starts a game for two players taking turns at the same mouse, played on the current drawing
+// (G again ends it). A panel at the bottom left says whose turn it is and what to click, and who won.
+//
+//   - Shannon switching: after picking two terminals, Short fixes an edge each turn and Cut removes one.
+//     Short wins by linking the terminals with fixed edges, Cut by separating them.
+//   - Cop and robber: each picks a starting vertex, then they take turns moving to a neighbor or staying put.
+//     The cop wins by landing on the robber, the robber by escaping for as many rounds as there are vertices
+//     (on a graph where one cop can win, it never takes that long).
+//   - Sprouts: a move joins two spots (or a spot to itself) through a new spot. Spots with three
+//     edges are dead, and whoever can't move loses. Edges are straight, so unlike on paper they may cross.
+//
+// Shannon and cop and robber don't change the graph; sprouts moves are edits and can be undone.
+
+type GameKind int
+
+const (
+	GameShannon GameKind = iota
+	GameCops
+	GameSprouts
+)
+
+// Locale keys of the games, and of their two players.
+var (
+	gameNames   = []string{"game.shannon", "game.cops", "game.sprouts"}
+	gamePlayers = [][2]string{{"game.short", "game.cut"}, {"game.cop", "game.robber"}, {"game.player1", "game.player2"}}
+)
+
+const sproutLives = 3 // Edge ends a spot takes before it's dead
+
+type Game struct {
+	Kind   GameKind
+	Turn   int // Player to move, 0 or 1 (Short, the cop or player 1 go first)
+	Moves  int
+	Winner int // -1 while playing
+
+	Terminals []int            // Shannon: the vertices Short wants to link
+	Fixed     map[EdgeKey]bool // Shannon: edges taken by Short
+	Cut       map[EdgeKey]bool // Shannon: edges removed by Cut
+	Cop       int              // Cops: positions, -1 until placed
+	Robber    int
+	From      int // Sprouts: first spot of the move being made, -1 if none
+}
+
+// Name of a player of the game.
+func (gm *Game) player(p int) string {
+	return T(gamePlayers[gm.Kind][p])
+}
+
+// Starts a game on the current drawing.
+func (app *App) StartGame(kind GameKind) {
+	g := app.Graph
+	switch {
+	case kind == GameShannon && g.EdgeCount() == 0:
+		app.Warn(T("game.need_edges"))
+		return
+	case kind != GameSprouts && len(g.Vertices) < 2:
+		app.Warn(T("game.need_vertices"))
+		return
+	}
+	app.Game = &Game{Kind: kind, Winner: -1, Fixed: map[EdgeKey]bool{}, Cut: map[EdgeKey]bool{}, Cop: -1, Robber: -1, From: -1}
+	app.Selection.Clear()
+	app.EdgeStart, app.PenLast = nil, nil
+	if kind == GameSprouts {
+		app.checkSprouts()
+	}
+	app.Notify(T(gameNames[kind]))
+	app.Announce(app.gamePrompt())
+}
+
+// Ends the game.
+func (app *App) StopGame() {
+	app.Game = nil
+	app.Notify(T("game.stopped"))
+}
+
+// Asks which game to play, or ends the one being played.
+func (app *App) ShowGameDialog() {
+	if app.Game != nil {
+		app.StopGame()
+		return
+	}
+	d := &Dialog{Message: T("game.dialog")}
+	for kind, name := range gameNames {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T(name), Action: func() { app.StartGame(GameKind(kind)) }})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
+
+// Says what the player to move should do, or who won.
+func (app *App) gamePrompt() string {
+	gm := app.Game
+	if gm.Winner >= 0 {
+		return T("game.won", gm.player(gm.Winner), gm.Moves)
+	}
+	who := gm.player(gm.Turn)
+	switch gm.Kind {
+	case GameShannon:
+		if len(gm.Terminals) < 2 {
+			return T("game.prompt_terminals", len(gm.Terminals)+1)
+		}
+		if gm.Turn == 0 {
+			return T("game.prompt_fix", who)
+		}
+		return T("game.prompt_cut", who)
+	case GameCops:
+		if gm.Cop < 0 || gm.Robber < 0 {
+			return T("game.prompt_place", who)
+		}
+		return T("game.prompt_move", who, gm.Moves/2+1, len(app.Graph.Vertices))
+	}
+	return T("game.prompt_sprout", who)
+}
+
+// Passes the turn to the other player, or ends the game if the one who moved won.
+func (app *App) endTurn(won bool) {
+	gm := app.Game
+	gm.Moves++
+	if won {
+		gm.Winner = gm.Turn
+	} else {
+		gm.Turn ^= 1
+	}
+	msg := app.gamePrompt()
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Makes the move a click at (x, y) stands for. Called instead of the tools while a game is on.
+func (app *App) GameClick(x, y float64) {
+	gm := app.Game
+	for _, i := range append([]int{gm.Cop, gm.Robber, gm.From}, gm.Terminals...) {
+		if i >= len(app.Graph.Vertices) { // Deleted (or undone) since
+			app.StopGame()
+			return
+		}
+	}
+	switch gm.Kind {
+	case GameShannon:
+		app.shannonClick(x, y)
+	case GameCops:
+		app.copsClick(app.VertexAt(x, y))
+	case GameSprouts:
+		app.sproutsClick(app.VertexAt(x, y))
+	}
+}
+
+// Shannon switching:
+
+// Reports whether a and b are linked by edges passing a test.
+func (g *Graph) linked(a, b int, use func(e EdgeKey) bool) bool {
+	seen := make([]bool, len(g.Vertices))
+	seen[a] = true
+	stack := []int{a}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if v == b {
+			return true
+		}
+		for w, count := range g.AdjMatrix[v] {
+			if count > 0 && !seen[w] && use(Edge(v, w)) {
+				seen[w] = true
+				stack = append(stack, w)
+			}
+		}
+	}
+	return false
+}
+
+func (app *App) shannonClick(x, y float64) {
+	gm, g := app.Game, app.Graph
+	if len(gm.Terminals) < 2 {
+		if i := app.VertexAt(x, y); i >= 0 && (len(gm.Terminals) == 0 || gm.Terminals[0] != i) {
+			gm.Terminals = append(gm.Terminals, i)
+			if len(gm.Terminals) == 2 && !g.linked(gm.Terminals[0], gm.Terminals[1], func(EdgeKey) bool { return true }) {
+				gm.Turn = 1 // Nothing to play for, Cut has won already
+				app.endTurn(true)
+				return
+			}
+			app.Announce(app.gamePrompt())
+		}
+		return
+	}
+	i, j, ok := app.EdgeAt(x, y)
+	if !ok || i == j {
+		return
+	}
+	e := Edge(i, j)
+	if gm.Fixed[e] || gm.Cut[e] {
+		app.Warn(T("game.taken"))
+		return
+	}
+	a, b := gm.Terminals[0], gm.Terminals[1]
+	if gm.Turn == 0 {
+		gm.Fixed[e] = true
+		app.endTurn(g.linked(a, b, func(e EdgeKey) bool { return gm.Fixed[e] }))
+	} else {
+		gm.Cut[e] = true
+		app.endTurn(!g.linked(a, b, func(e EdgeKey) bool { return !gm.Cut[e] }))
+	}
+}
+
+// Cop and robber:
+
+func (app *App) copsClick(i int) {
+	gm, g := app.Game, app.Graph
+	if i < 0 {
+		return
+	}
+	pos := &gm.Cop
+	if gm.Turn == 1 {
+		pos = &gm.Robber
+	}
+	if *pos >= 0 && i != *pos && g.AdjMatrix[*pos][i] == 0 {
+		app.Warn(T("game.not_adjacent"))
+		return
+	}
+	*pos = i
+	if gm.Cop >= 0 && gm.Cop == gm.Robber {
+		gm.Turn = 0 // A robber walking into the cop is caught too
+		app.endTurn(true)
+		return
+	}
+	escaped := gm.Turn == 1 && gm.Moves/2+1 >= len(g.Vertices) // Placing counts as the first round
+	app.endTurn(escaped)
+}
+
+// Sprouts:
+
+// Edge ends a spot can still take.
+func (g *Graph) sproutLives(i int) int {
+	return sproutLives - g.Degree(i) - g.AdjMatrix[i][i] // A loop takes two
+}
+
+// Reports whether any move is left.
+func (g *Graph) sproutMoveLeft() bool {
+	alive := 0
+	for i := range g.Vertices {
+		switch lives := g.sproutLives(i); {
+		case lives >= 2:
+			return true
+		case lives == 1:
+			alive++
+		}
+	}
+	return alive >= 2
+}
+
+// The player to move loses if there's no move left.
+func (app *App) checkSprouts() {
+	if gm := app.Game; !app.Graph.sproutMoveLeft() {
+		gm.Turn ^= 1
+		gm.Winner = gm.Turn
+	}
+}
+
+func (app *App) sproutsClick(i int) {
+	gm, g := app.Game, app.Graph
+	if i < 0 {
+		gm.From = -1
+		return
+	}
+	if gm.From < 0 {
+		if g.sproutLives(i) <= 0 {
+			app.Warn(T("game.dead_spot", g.Vertices[i].Label))
+			return
+		}
+		gm.From = i
+		return
+	}
+	from := gm.From
+	gm.From = -1
+	if (from == i && g.sproutLives(i) < 2) || g.sproutLives(i) <= 0 {
+		app.Warn(T("game.dead_spot", g.Vertices[i].Label))
+		return
+	}
+
+	a, b := g.Vertices[from], g.Vertices[i]
+	x, y := (a.X+b.X)/2, (a.Y+b.Y)/2
+	if from == i {
+		y -= 3 * a.Radius // On a loop above the spot
+	}
+	app.BeginTransaction(T("undo.sprout"))
+	app.Do(Action{Kind: ActionAddVertex, X: x, Y: y, Label: fmt.Sprintf("V%d", len(g.Vertices)+1), Color: app.defaultVertexColor(), Radius: app.Settings.Defaults.VertexRadius})
+	n := len(app.Graph.Vertices) - 1
+	app.Do(Action{Kind: ActionAddEdge, V1: from, V2: n})
+	app.Do(Action{Kind: ActionAddEdge, V1: n, V2: i})
+	app.Commit()
+	app.endTurn(!app.Graph.sproutMoveLeft()) // Normal play: making the last move wins
+}
+
+// Drawing functions:
+
+// Draws the marks of the game on the graph.
+func (app *App) drawGameMarks(screen *ebiten.Image) {
+	gm, g := app.Game, app.Graph
+	if gm == nil {
+		return
+	}
+	ring := func(i int, clr color.RGBA, mark string) {
+		if g.CheckVertices(i) != nil {
+			return
+		}
+		v := g.Vertices[i]
+		x, y := app.toScreen(v.X, v.Y)
+		r := app.toScreenLength(app.vertexRadius(i)) + 5
+		strokeCircle(screen, float32(x), float32(y), float32(r), 3, clr, true)
+		printAt(screen, mark, int(x+r), int(y-r-12))
+	}
+	switch gm.Kind {
+	case GameShannon:
+		for e := range gm.Fixed {
+			if g.CheckVertices(e.A, e.B) == nil {
+				x1, y1 := app.toScreen(g.Vertices[e.A].X, g.Vertices[e.A].Y)
+				x2, y2 := app.toScreen(g.Vertices[e.B].X, g.Vertices[e.B].Y)
+				strokeLine(screen, float32(x1), float32(y1), float32(x2), float32(y2), 6, color.RGBA{0, 200, 0, 255}, true)
+			}
+		}
+		for e := range gm.Cut {
+			if g.CheckVertices(e.A, e.B) == nil {
+				x, y := app.toScreen((g.Vertices[e.A].X+g.Vertices[e.B].X)/2, (g.Vertices[e.A].Y+g.Vertices[e.B].Y)/2)
+				strokeLine(screen, float32(x-6), float32(y-6), float32(x+6), float32(y+6), 3, conflictColor, true)
+				strokeLine(screen, float32(x-6), float32(y+6), float32(x+6), float32(y-6), 3, conflictColor, true)
+			}
+		}
+		for _, i := range gm.Terminals {
+			ring(i, color.RGBA{255, 215, 0, 255}, "")
+		}
+	case GameCops:
+		ring(gm.Cop, color.RGBA{60, 120, 255, 255}, T("game.cop"))
+		ring(gm.Robber, conflictColor, T("game.robber"))
+	case GameSprouts:
+		for i, v := range g.Vertices {
+			x, y := app.toScreen(v.X, v.Y)
+			printAt(screen, fmt.Sprint(max(0, g.sproutLives(i))), int(x-3), int(y+app.toScreenLength(app.vertexRadius(i))+2))
+		}
+		ring(gm.From, color.RGBA{100, 100, 255, 255}, "")
+	}
+}
+
+// Draws the turn panel at the bottom left of the canvas.
+func (app *App) DrawGameStatus(screen *ebiten.Image) {
+	if app.Game == nil {
+		return
+	}
+	text := app.gamePrompt()
+	border := color.RGBA{100, 100, 255, 255}
+	if app.Game.Winner >= 0 {
+		border = color.RGBA{0, 200, 0, 255}
+	}
+	x, y := float32(10), float32(screenHeight-52)
+	w := float32(min(textWidth(text)+10, screenWidth-20))
+	fillRect(screen, x, y, w, 22, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, w, 22, 2, border, true)
+	printAt(screen, text, int(x)+5, int(y)+3)
+}
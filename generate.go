@@ -0,0 +1,192 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Random graph generators:
+
+// Ctrl+G (or "Generate..." among the templates) makes a random graph from parameters typed in:
+//
+//	G(n, p)          every pair joined with probability p (Erdős–Rényi)
+//	G(n, m)          m edges picked uniformly among all pairs
+//	Barabási–Albert  each new vertex joined to m earlier ones picked by degree (preferential attachment)
+//	k-regular        n vertices of degree k, n·k even
+//
+// The graph replaces the drawing like a template (one undo step), starting on a circle, then the
+// Shift+L layout spreads it out (layout.go).
+
+const (
+	generateMaxVertices = 1000 // Beyond that the layout and the matrix get slow
+	regularAttempts     = 100  // Random pairings tried before giving up on a k-regular graph
+)
+
+type Generator struct {
+	Name    string // Locale key
+	Prompt  string // Locale key of the parameter prompt
+	Default string // Parameters filled in
+	// Returns the edges of a graph on n vertices, ok is false if the parameters can't make one
+	Build func(n int, param float64) (edges [][2]int, ok bool)
+}
+
+var generators = []Generator{
+	{Name: "generate.gnp", Prompt: "generate.gnp_prompt", Default: "30 0.1", Build: func(n int, p float64) ([][2]int, bool) {
+		if p < 0 || p > 1 {
+			return nil, false
+		}
+		var edges [][2]int
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				if rand.Float64() < p {
+					edges = append(edges, [2]int{i, j})
+				}
+			}
+		}
+		return edges, true
+	}},
+	{Name: "generate.gnm", Prompt: "generate.gnm_prompt", Default: "30 45", Build: func(n int, m float64) ([][2]int, bool) {
+		pairs := n * (n - 1) / 2
+		if m < 0 || int(m) > pairs {
+			return nil, false
+		}
+		chosen := map[[2]int]bool{}
+		edges := make([][2]int, 0, int(m))
+		for len(edges) < int(m) {
+			i, j := rand.Intn(n), rand.Intn(n)
+			if i == j {
+				continue
+			}
+			e := [2]int{min(i, j), max(i, j)}
+			if !chosen[e] {
+				chosen[e] = true
+				edges = append(edges, e)
+			}
+		}
+		return edges, true
+	}},
+	{Name: "generate.barabasi_albert", Prompt: "generate.barabasi_albert_prompt", Default: "50 2", Build: func(n int, param float64) ([][2]int, bool) {
+		m := int(param)
+		if m < 1 || m >= n {
+			return nil, false
+		}
+		// The first m vertices start unconnected, the next one joins them all. After that, targets
+		// are drawn from the ends of the edges so far, each vertex as often as its degree.
+		var edges [][2]int
+		var ends []int
+		for v := m; v < n; v++ {
+			targets := map[int]bool{}
+			for len(targets) < m {
+				if v == m {
+					targets[len(targets)] = true
+				} else {
+					targets[ends[rand.Intn(len(ends))]] = true
+				}
+			}
+			for t := range targets {
+				edges = append(edges, [2]int{t, v})
+				ends = append(ends, t, v)
+			}
+		}
+		return edges, true
+	}},
+	{Name: "generate.regular", Prompt: "generate.regular_prompt", Default: "20 3", Build: func(n int, param float64) ([][2]int, bool) {
+		k := int(param)
+		if k < 0 || k >= n || n*k%2 != 0 {
+			return nil, false
+		}
+		for range regularAttempts {
+			if edges, ok := randomPairing(n, k); ok {
+				return edges, true
+			}
+		}
+		return nil, false
+	}},
+}
+
+// Tries to join n vertices into a simple k-regular graph by pairing up their k stubs at random.
+// Pairs that would make a loop or a parallel edge are redrawn a few times; ok is false if it got stuck.
+func randomPairing(n, k int) (edges [][2]int, ok bool) {
+	stubs := make([]int, 0, n*k)
+	for v := range n {
+		for range k {
+			stubs = append(stubs, v)
+		}
+	}
+	joined := map[[2]int]bool{}
+	for len(stubs) > 0 {
+		paired := false
+		for range len(stubs) {
+			a, b := rand.Intn(len(stubs)), rand.Intn(len(stubs))
+			i, j := stubs[a], stubs[b]
+			e := [2]int{min(i, j), max(i, j)}
+			if a == b || i == j || joined[e] {
+				continue
+			}
+			joined[e] = true
+			edges = append(edges, e)
+			// Drop both stubs, the higher index first so the other doesn't move
+			for _, s := range []int{max(a, b), min(a, b)} {
+				stubs[s] = stubs[len(stubs)-1]
+				stubs = stubs[:len(stubs)-1]
+			}
+			paired = true
+			break
+		}
+		if !paired {
+			return nil, false
+		}
+	}
+	return edges, true
+}
+
+// Asks for a generator's parameters, then replaces the drawing with a graph from it.
+func (app *App) askGenerator(gen Generator) {
+	app.Notify(T(gen.Prompt))
+	app.OpenTextInput(&TextInput{Text: gen.Default, X: screenWidth/2 - 30, Y: screenHeight / 2, OnCommit: func(text string) {
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			app.Warn(T("warn.generate_params", T(gen.Prompt)))
+			return
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			app.Warn(T("warn.not_a_number", fields[0]))
+			return
+		}
+		param, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			app.Warn(T("warn.not_a_number", fields[1]))
+			return
+		}
+		if n < 1 || n > generateMaxVertices {
+			app.Warn(T("warn.generate_size", generateMaxVertices))
+			return
+		}
+		edges, ok := gen.Build(n, param)
+		if !ok {
+			app.Warn(T("warn.generate_params", T(gen.Prompt)))
+			return
+		}
+		t := Template{Name: gen.Name, Build: func(cx, cy float64) ([]point, [][2]int) {
+			r := math.Min(float64(screenHeight-canvasTop())/2-40, layoutSpacing*float64(n)/(2*math.Pi))
+			return circle(n, cx, cy, r), edges
+		}}
+		app.LoadTemplate(t, func() {
+			app.Notify(T("generate.done", T(gen.Name), n, len(edges)))
+			app.ToggleLayoutRun()
+		})
+	}})
+}
+
+// Asks which kind of random graph to generate.
+func (app *App) ShowGenerateDialog() {
+	d := &Dialog{Message: T("generate.dialog")}
+	for _, gen := range generators {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T(gen.Name), Action: func() { app.askGenerator(gen) }})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
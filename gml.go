@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GML:
+
+// Graph Modelling Language, as written by yEd, Gephi, networkx and igraph:
+//
+//	graph [
+//	  node [ id 1 label "A" graphics [ x 10.0 y 20.0 fill "#FF0000" ] ]
+//	  node [ id 2 label "B" ]
+//	  edge [ source 1 target 2 weight 3 ]
+//	]
+//
+// Positions and fill colors come from the graphics block, other plain node and edge keys are kept as attributes.
+// Written files use the same layout, with the edge weights and the attributes whose names are valid
+// GML keys, typed by the schema.
+// "directed 1" in the graph block makes the edges go from source to target, both ways.
+
+var errGML = errors.New("bad GML")
+
+// A parsed GML value: a number or string, or a list of key-value pairs.
+type gmlValue struct {
+	Text string
+	List []gmlPair // nil for scalars
+}
+
+type gmlPair struct {
+	Key   string
+	Value gmlValue
+}
+
+// Returns the first value with a key.
+func (v gmlValue) get(key string) (gmlValue, bool) {
+	for _, p := range v.List {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return gmlValue{}, false
+}
+
+// Splits GML into keys, values and brackets. Strings keep their quotes, so they can't be mistaken for keys.
+func gmlTokens(data []byte) ([]string, error) {
+	var tokens []string
+	s := string(data)
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#': // Comment to the end of the line
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '[' || c == ']':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated string", errGML)
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && s[i] != '[' && s[i] != ']' {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// Parses key-value pairs until a closing bracket (or the end at the top level).
+func gmlList(tokens []string, pos *int, top bool) ([]gmlPair, error) {
+	list := []gmlPair{}
+	for *pos < len(tokens) {
+		key := tokens[*pos]
+		if key == "]" {
+			if top {
+				return nil, fmt.Errorf("%w: unexpected ]", errGML)
+			}
+			*pos++
+			return list, nil
+		}
+		*pos++
+		if *pos >= len(tokens) {
+			return nil, fmt.Errorf("%w: %s has no value", errGML, key)
+		}
+		value := tokens[*pos]
+		*pos++
+		p := gmlPair{Key: key}
+		if value == "[" {
+			sub, err := gmlList(tokens, pos, false)
+			if err != nil {
+				return nil, err
+			}
+			p.Value.List = sub
+		} else {
+			p.Value.Text = strings.Trim(value, `"`)
+		}
+		list = append(list, p)
+	}
+	if !top {
+		return nil, fmt.Errorf("%w: missing ]", errGML)
+	}
+	return list, nil
+}
+
+// Parses GML.
+func parseGML(data []byte) (*ImportedGraph, error) {
+	tokens, err := gmlTokens(data)
+	if err != nil {
+		return nil, err
+	}
+	pos := 0
+	top, err := gmlList(tokens, &pos, true)
+	if err != nil {
+		return nil, err
+	}
+	graph, ok := gmlValue{List: top}.get("graph")
+	if !ok || graph.List == nil {
+		return nil, fmt.Errorf("%w: no graph block", errGML)
+	}
+
+	ig := &ImportedGraph{}
+	index := map[string]int{}
+	for _, p := range graph.List {
+		switch p.Key {
+		case "directed":
+			ig.Directed = p.Value.Text == "1"
+		case "node":
+			id, _ := p.Value.get("id")
+			v := ImportedVertex{Label: id.Text, Attrs: map[string]string{}}
+			for _, q := range p.Value.List {
+				switch {
+				case q.Key == "id":
+				case q.Key == "label":
+					v.Label = q.Value.Text
+				case q.Key == "graphics":
+					x, okX := q.Value.get("x")
+					y, okY := q.Value.get("y")
+					if okX && okY {
+						v.X, _ = strconv.ParseFloat(x.Text, 64)
+						v.Y, _ = strconv.ParseFloat(y.Text, 64)
+						v.HasPos = true
+					}
+					if fill, ok := q.Value.get("fill"); ok {
+						v.Color = parseHexColor(fill.Text)
+					}
+				case q.Value.List == nil:
+					v.Attrs[q.Key] = q.Value.Text
+				}
+			}
+			index[id.Text] = len(ig.Vertices)
+			ig.Vertices = append(ig.Vertices, v)
+		case "edge":
+			source, _ := p.Value.get("source")
+			target, _ := p.Value.get("target")
+			a, okA := index[source.Text]
+			b, okB := index[target.Text]
+			if !okA || !okB {
+				return nil, fmt.Errorf("%w: edge %s - %s", ErrNoVertex, source.Text, target.Text)
+			}
+			e := ImportedEdge{A: a, B: b, Attrs: map[string]string{}}
+			for _, q := range p.Value.List {
+				if q.Key != "source" && q.Key != "target" && q.Value.List == nil {
+					e.Attrs[q.Key] = q.Value.Text
+				}
+			}
+			ig.Edges = append(ig.Edges, e)
+		}
+	}
+	return ig, nil
+}
+
+// Reports whether a name can be a GML key (a letter, then letters and digits).
+func gmlKey(name string) bool {
+	for i, c := range name {
+		if !(c < unicode.MaxASCII && (unicode.IsLetter(c) || (i > 0 && unicode.IsDigit(c)))) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// Formats a GML value: numbers as they are, anything else quoted (GML strings can't hold quotes).
+func gmlText(s string) string {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
+
+// Formats an attribute value with its column type (schema.go): numbers and flags (1 or 0) bare, the rest quoted.
+func gmlAttr(s *Schema, edge bool, name, value string) string {
+	c, ok := s.Column(name, edge)
+	if !ok {
+		return gmlText(value)
+	}
+	if f := c.Number(value); !math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return `"` + strings.ReplaceAll(value, `"`, "'") + `"` // Text is quoted even if it looks like a number
+}
+
+// Writes attributes in name order, skipping the ones GML can't name or that are written anyway.
+func writeGMLAttrs(buf *bytes.Buffer, attrs map[string]string, s *Schema, edge bool, skip ...string) {
+	var keys []string
+	for k := range attrs {
+		if gmlKey(k) && !slices.Contains(skip, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, " %s %s", k, gmlAttr(s, edge, k, attrs[k]))
+	}
+}
+
+// Writes the graph as GML with positions, colors and attributes.
+func writeGML(buf *bytes.Buffer, g *Graph) {
+	fmt.Fprintln(buf, "graph [")
+	directed := 0
+	if g.Directed {
+		directed = 1
+	}
+	fmt.Fprintln(buf, "  directed", directed)
+	for i, v := range g.Vertices {
+		fmt.Fprintf(buf, "  node [ id %d label %s graphics [ x %.1f y %.1f fill \"%s\" ]", i, gmlText(v.Label), v.X, v.Y, svgColor(v.Color))
+		writeGMLAttrs(buf, v.Attrs, &g.Schema, false, "id", "label", "graphics")
+		fmt.Fprintln(buf, " ]")
+	}
+	for i := range g.Vertices {
+		for j := range g.Vertices {
+			if j < i && !g.Directed { // Written from the lower index only
+				continue
+			}
+			for range g.AdjMatrix[i][j] {
+				fmt.Fprintf(buf, "  edge [ source %d target %d", i, j)
+				if w, ok := g.weightOf(i, j); ok {
+					fmt.Fprintf(buf, " weight %s", strconv.FormatFloat(w, 'g', -1, 64))
+				}
+				writeGMLAttrs(buf, g.EdgeAttrs[Edge(i, j)], &g.Schema, true, "source", "target", "weight")
+				fmt.Fprintln(buf, " ]")
+			}
+		}
+	}
+	fmt.Fprintln(buf, "]")
+}
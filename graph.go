@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+)
+
+// Vertex and graph info:
+
+// Edges stored via adjacency matrix.
+// Vertex drawing info in it's own struct.
+// Vertices are tracked by index, in adjacency matrix and vertex slice.
+
+type Vertex struct {
+	X, Y   float64
+	Label  string
+	Color  color.RGBA
+	Radius float64
+	Weight float64           // For vertex-weighted algorithms (weights.go), 1 unless set
+	Active *Interval         // When the vertex exists (temporal.go), nil for always
+	Attrs  map[string]string // Imported data (import.go), kept as text
+	Layer  int               // Drawing order (zorder.go), higher layers are drawn on top
+
+	LabelOffset *point // Label's top left corner from the center when dragged somewhere (labels.go), nil to place it automatically
+}
+
+type Graph struct {
+	Vertices    []Vertex
+	AdjMatrix   [][]int
+	EdgeStyles  map[EdgeKey]EdgeStyle         // Edges without an entry use the default style
+	EdgeTimes   map[EdgeKey]Interval          // When the edges exist (temporal.go), always if missing
+	EdgeAttrs   map[EdgeKey]map[string]string // Imported edge data, parallel edges share it
+	EdgeWeights map[EdgeKey]float64           // Edge weights (edgeweights.go, keyed by weightKey), 1 if missing
+	Schema      Schema                        // Types of the vertex and edge attributes (schema.go)
+	Directed    bool                          // AdjMatrix[i][j] only counts the edges from i to j (directed.go)
+}
+
+// Identifies the edges between two vertices (all parallel copies share it).
+// A is always the smaller index, so both directions map to the same key.
+type EdgeKey struct {
+	A, B int
+}
+
+// Returns the key of the edges between v1 and v2.
+func Edge(v1, v2 int) EdgeKey {
+	return EdgeKey{min(v1, v2), max(v1, v2)}
+}
+
+// Per-edge drawing info.
+type EdgeStyle struct {
+	Color  color.RGBA
+	Width  float64
+	Curved bool // Drawn as an arc even when it's the only edge between its ends (edges.go)
+}
+
+// Errors returned by graph mutations.
+// Callers are expected to show these to the user, not crash.
+var (
+	ErrNoVertex = errors.New("no such vertex")
+	ErrNoEdge   = errors.New("no such edge")
+)
+
+// Checks that all indices refer to existing vertices.
+// Every mutation goes through here, so a stale index held by a tool can't corrupt the matrix.
+func (g *Graph) CheckVertices(indices ...int) error {
+	for _, i := range indices {
+		if i < 0 || i >= len(g.Vertices) {
+			return fmt.Errorf("%w: index %d (graph has %d vertices)", ErrNoVertex, i, len(g.Vertices))
+		}
+	}
+	return nil
+}
+
+// Adds a vertex to the graph.
+func (g *Graph) AddVertex(x, y float64, label string, clr color.RGBA, radius float64) {
+	g.Vertices = append(g.Vertices, Vertex{X: x, Y: y, Label: label, Color: clr, Radius: radius, Weight: 1})
+	// Expand adjacency matrix:
+	for i := range g.AdjMatrix {
+		g.AdjMatrix[i] = append(g.AdjMatrix[i], 0)
+	}
+	g.AdjMatrix = append(g.AdjMatrix, make([]int, len(g.Vertices)))
+}
+
+// Removes a vertex (and its edges) from the graph.
+// Uses some fun slice indexing.
+func (g *Graph) DeleteVertex(index int) error {
+	if err := g.CheckVertices(index); err != nil {
+		return err
+	}
+	g.Vertices = append(g.Vertices[:index], g.Vertices[index+1:]...)
+	g.AdjMatrix = append(g.AdjMatrix[:index], g.AdjMatrix[index+1:]...)
+	for i := range g.AdjMatrix {
+		g.AdjMatrix[i] = append(g.AdjMatrix[i][:index], g.AdjMatrix[i][index+1:]...)
+	}
+
+	// Edge styles, times and weights are keyed by index too
+	g.EdgeStyles = withoutVertex(g.EdgeStyles, index)
+	g.EdgeTimes = withoutVertex(g.EdgeTimes, index)
+	g.EdgeAttrs = withoutVertex(g.EdgeAttrs, index)
+	g.EdgeWeights = withoutVertex(g.EdgeWeights, index)
+	return nil
+}
+
+// Returns a copy of per-edge data with the edges of a deleted vertex dropped and later indices shifted down.
+func withoutVertex[T any](m map[EdgeKey]T, index int) map[EdgeKey]T {
+	shifted := map[EdgeKey]T{}
+	for k, v := range m {
+		if k.A == index || k.B == index {
+			continue
+		}
+		if k.A > index {
+			k.A--
+		}
+		if k.B > index {
+			k.B--
+		}
+		shifted[k] = v
+	}
+	return shifted
+}
+
+// Renumbers the vertices: order lists the old indices in their new order. Edges and their data
+// follow their ends.
+func (g *Graph) Reorder(order []int) error {
+	n := len(g.Vertices)
+	position := make([]int, n) // New index of each old one, -1 until seen
+	for i := range position {
+		position[i] = -1
+	}
+	for k, old := range order {
+		if err := g.CheckVertices(old); err != nil {
+			return err
+		}
+		if position[old] >= 0 {
+			return fmt.Errorf("vertex %s is twice in the new order", g.Vertices[old].Label)
+		}
+		position[old] = k
+	}
+	if len(order) != n {
+		return fmt.Errorf("the new order has %d of the %d vertices", len(order), n)
+	}
+
+	vertices, matrix := make([]Vertex, n), make([][]int, n)
+	for k, old := range order {
+		vertices[k] = g.Vertices[old]
+		matrix[k] = make([]int, n)
+		for l, other := range order {
+			matrix[k][l] = g.AdjMatrix[old][other]
+		}
+	}
+	g.Vertices, g.AdjMatrix = vertices, matrix
+	g.EdgeStyles = renumbered(g.EdgeStyles, position, false)
+	g.EdgeTimes = renumbered(g.EdgeTimes, position, false)
+	g.EdgeAttrs = renumbered(g.EdgeAttrs, position, false)
+	g.EdgeWeights = renumbered(g.EdgeWeights, position, g.Directed)
+	return nil
+}
+
+// Returns a copy of per-edge data with each end i renumbered to position[i], the ends kept in order
+// if ordered (directed weights) and the smaller first otherwise.
+func renumbered[T any](m map[EdgeKey]T, position []int, ordered bool) map[EdgeKey]T {
+	moved := map[EdgeKey]T{}
+	for k, v := range m {
+		k = EdgeKey{position[k.A], position[k.B]}
+		if !ordered {
+			k = Edge(k.A, k.B)
+		}
+		moved[k] = v
+	}
+	return moved
+}
+
+// Removes an edge.
+func (g *Graph) DeleteEdge(v1, v2 int) error {
+	if err := g.CheckVertices(v1, v2); err != nil {
+		return err
+	}
+	if g.AdjMatrix[v1][v2] <= 0 {
+		return fmt.Errorf("%w: %s - %s", ErrNoEdge, g.Vertices[v1].Label, g.Vertices[v2].Label)
+	}
+
+	g.AdjMatrix[v1][v2]--
+	if v1 != v2 && !g.Directed { // Loops are only counted once
+		g.AdjMatrix[v2][v1]--
+	}
+	if g.Multiplicity(v1, v2) == 0 {
+		delete(g.EdgeStyles, Edge(v1, v2))
+		delete(g.EdgeTimes, Edge(v1, v2))
+		delete(g.EdgeAttrs, Edge(v1, v2))
+	}
+	if g.AdjMatrix[v1][v2] == 0 { // The last edge that way
+		delete(g.EdgeWeights, g.weightKey(v1, v2))
+	}
+	return nil
+}
+
+// Adds an edge between two vertices (allows parallel edges and loops - using Brezier curves).
+func (g *Graph) AddEdge(v1, v2 int) error {
+	if err := g.CheckVertices(v1, v2); err != nil {
+		return err
+	}
+
+	g.AdjMatrix[v1][v2]++
+	if v1 != v2 && !g.Directed { // Only count loops once
+		g.AdjMatrix[v2][v1]++
+	}
+	return nil
+}
+
+// Sets the style of the edges between two vertices.
+func (g *Graph) SetEdgeStyle(v1, v2 int, s EdgeStyle) error {
+	if err := g.CheckVertices(v1, v2); err != nil {
+		return err
+	}
+	if g.Multiplicity(v1, v2) <= 0 {
+		return fmt.Errorf("%w: %s - %s", ErrNoEdge, g.Vertices[v1].Label, g.Vertices[v2].Label)
+	}
+	if g.EdgeStyles == nil {
+		g.EdgeStyles = map[EdgeKey]EdgeStyle{}
+	}
+	g.EdgeStyles[Edge(v1, v2)] = s
+	return nil
+}
+
+// Returns the degree of a vertex (row sum of the adjacency matrix, same as Print Info).
+// In a directed graph that's the out-degree.
+func (g *Graph) Degree(v int) int {
+	deg := 0
+	for _, count := range g.AdjMatrix[v] {
+		deg += count
+	}
+	return deg
+}
+
+// Removes all vertices and edges.
+func (g *Graph) Clear() {
+	g.Vertices = []Vertex{}
+	g.AdjMatrix = [][]int{}
+	g.EdgeStyles = nil
+	g.EdgeTimes = nil
+	g.EdgeAttrs = nil
+	g.EdgeWeights = nil
+	g.Schema = Schema{}
+}
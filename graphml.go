@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GraphML:
+
+// The XML format of yEd, Gephi, Cytoscape, networkx and igraph:
+//
+//	<graphml>
+//	  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+//	  <graph edgedefault="undirected">
+//	    <node id="n0"><data key="d0">Alice</data></node>
+//	    <edge source="n0" target="n1"/>
+//	  </graph>
+//	</graphml>
+//
+// The file is read token by token, so even big ones aren't held in memory as text. Data keys named
+// "label" or "name" give the label, "x" and "y" the position and "color" the color (#rrggbb); yEd's
+// shape nodes give theirs through Geometry, Fill and NodeLabel. Other data is kept as attributes,
+// under the key's name, keys' defaults included. Nested graphs are flattened, hyperedges and ports
+// ignored. edgedefault="directed" switches to directed mode.
+
+var errGraphML = errors.New("bad GraphML")
+
+type graphMLKey struct {
+	Name    string
+	For     string // node, edge, all, ...
+	Default *string
+}
+
+// Returns the value of an XML attribute, "" if it isn't there.
+func xmlAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Parses GraphML from r.
+func parseGraphML(r io.Reader) (*ImportedGraph, error) {
+	d := xml.NewDecoder(r)
+	ig := &ImportedGraph{}
+	keys := map[string]*graphMLKey{}
+	index := map[string]int{}
+	type pendingEdge struct {
+		source, target string
+		attrs          map[string]string
+	}
+	var edges []pendingEdge
+
+	type openNode struct {
+		id string
+		v  *ImportedVertex
+	}
+	var nodes []openNode               // Nodes being read, nested ones last
+	var edgeAttrs map[string]string    // Edge being read
+	var key *graphMLKey                // Key being read (for its default)
+	var dataKey string                 // Data element being read
+	var text strings.Builder           // Its text
+	inLabel, inDefault := false, false // Inside a yEd NodeLabel, a key's default
+	seenGraphML := false
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var vertex *ImportedVertex
+			if len(nodes) > 0 {
+				vertex = nodes[len(nodes)-1].v
+			}
+			switch t.Name.Local {
+			case "graphml":
+				seenGraphML = true
+			case "key":
+				key = &graphMLKey{Name: xmlAttr(t, "attr.name"), For: xmlAttr(t, "for")}
+				if key.Name == "" {
+					key.Name = xmlAttr(t, "id")
+				}
+				keys[xmlAttr(t, "id")] = key
+			case "default":
+				inDefault = true
+				text.Reset()
+			case "graph":
+				if xmlAttr(t, "edgedefault") == "directed" {
+					ig.Directed = true
+				}
+			case "node":
+				id := xmlAttr(t, "id")
+				nodes = append(nodes, openNode{id: id, v: &ImportedVertex{Label: id, Attrs: map[string]string{}}})
+			case "edge":
+				edgeAttrs = map[string]string{}
+				edges = append(edges, pendingEdge{source: xmlAttr(t, "source"), target: xmlAttr(t, "target"), attrs: edgeAttrs})
+			case "data":
+				dataKey = xmlAttr(t, "key")
+				text.Reset()
+			case "Geometry": // yEd
+				if vertex != nil {
+					x, errX := strconv.ParseFloat(xmlAttr(t, "x"), 64)
+					y, errY := strconv.ParseFloat(xmlAttr(t, "y"), 64)
+					w, _ := strconv.ParseFloat(xmlAttr(t, "width"), 64)
+					h, _ := strconv.ParseFloat(xmlAttr(t, "height"), 64)
+					if errX == nil && errY == nil {
+						vertex.X, vertex.Y, vertex.HasPos = x+w/2, y+h/2, true // yEd gives the top left corner
+					}
+				}
+			case "Fill": // yEd
+				if vertex != nil {
+					if c := parseHexColor(xmlAttr(t, "color")); c != nil {
+						vertex.Color = c
+					}
+				}
+			case "NodeLabel": // yEd
+				inLabel = true
+				text.Reset()
+			}
+		case xml.CharData:
+			if dataKey != "" || inLabel || inDefault {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			var vertex *ImportedVertex
+			if len(nodes) > 0 {
+				vertex = nodes[len(nodes)-1].v
+			}
+			switch t.Name.Local {
+			case "default":
+				if key != nil {
+					value := strings.TrimSpace(text.String())
+					key.Default = &value
+				}
+				inDefault = false
+			case "key":
+				key = nil
+			case "NodeLabel":
+				if label := strings.TrimSpace(text.String()); vertex != nil && label != "" {
+					vertex.Label = label
+				}
+				inLabel = false
+			case "data":
+				value := strings.TrimSpace(text.String())
+				name := dataKey
+				if k, ok := keys[dataKey]; ok {
+					name = k.Name
+				}
+				switch {
+				case edgeAttrs != nil:
+					if value != "" {
+						edgeAttrs[name] = value
+					}
+				case vertex != nil && value != "":
+					setGraphMLData(vertex, name, value)
+				}
+				dataKey = ""
+			case "node":
+				if vertex == nil {
+					continue
+				}
+				// Defaults for the node keys it has no data for
+				for _, k := range keys {
+					if k.Default != nil && (k.For == "node" || k.For == "all") {
+						if _, ok := vertex.Attrs[k.Name]; !ok && *k.Default != "" {
+							setGraphMLData(vertex, k.Name, *k.Default)
+						}
+					}
+				}
+				index[nodes[len(nodes)-1].id] = len(ig.Vertices)
+				ig.Vertices = append(ig.Vertices, *vertex)
+				nodes = nodes[:len(nodes)-1]
+			case "edge":
+				for _, k := range keys {
+					if k.Default != nil && (k.For == "edge" || k.For == "all") {
+						if _, ok := edgeAttrs[k.Name]; !ok && *k.Default != "" {
+							edgeAttrs[k.Name] = *k.Default
+						}
+					}
+				}
+				edgeAttrs = nil
+			}
+		}
+	}
+	if !seenGraphML {
+		return nil, fmt.Errorf("%w: no graphml element", errGraphML)
+	}
+
+	for _, e := range edges {
+		a, okA := index[e.source]
+		b, okB := index[e.target]
+		if !okA || !okB {
+			return nil, fmt.Errorf("%w: edge %s - %s", ErrNoVertex, e.source, e.target)
+		}
+		ig.Edges = append(ig.Edges, ImportedEdge{A: a, B: b, Attrs: e.attrs})
+	}
+	return ig, nil
+}
+
+// Applies a node's data value.
+func setGraphMLData(v *ImportedVertex, name, value string) {
+	switch name {
+	case "label", "name":
+		v.Label = value
+	case "x", "y":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			v.Attrs[name] = value
+			return
+		}
+		if name == "x" {
+			v.X = f
+		} else {
+			v.Y = f
+		}
+		v.HasPos = true // Both come together in practice; a lone one leaves the other at 0
+	case "color":
+		if c := parseHexColor(value); c != nil {
+			v.Color = c
+			return
+		}
+		v.Attrs[name] = value
+	default:
+		v.Attrs[name] = value
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"image/color"
+	"slices"
+	"sync/atomic"
+)
+
+// Hamiltonian cycles and paths:
+//
+//	Shift+V: look for a Hamiltonian cycle or path.
+//
+// A Hamiltonian cycle goes through every vertex exactly once and back to where it started, a path
+// just through every vertex once. The search backtracks, trying next the neighbor with the fewest
+// ways on (so dead ends show up early), and follows the edge directions in directed mode. It runs in
+// the background behind a dialog whose Cancel button stops it; since it's exponential in the worst
+// case it's only offered up to hamiltonianMaxVertices vertices. What it finds is highlighted with the
+// vertices numbered in order, until Escape. Loops and parallel edges don't matter, and an undirected
+// cycle needs at least three vertices.
+
+const (
+	hamiltonianMaxVertices = 30
+	hamiltonianCheckEvery  = 4096 // Search steps between looks at the stop flag
+)
+
+// Looks for a Hamiltonian cycle, or path, by backtracking. Returns the vertices in order (nil if
+// there's none) and whether the search ran to the end, false if it was stopped.
+func (g *Graph) hamiltonian(cycle bool, stop *atomic.Bool) (order []int, finished bool) {
+	n := len(g.Vertices)
+	if n == 0 || cycle && (n < 2 || n < 3 && !g.Directed) {
+		return nil, true
+	}
+	out := make([][]int, n) // Neighbors along the edges, loops left out
+	for v := range n {
+		for w := range n {
+			if v != w && g.AdjMatrix[v][w] > 0 {
+				out[v] = append(out[v], w)
+			}
+		}
+	}
+	visited := make([]bool, n)
+	path := make([]int, 0, n)
+	steps, stopped := 0, false
+	// Number of unvisited vertices reachable from v in one step
+	options := func(v int) int {
+		count := 0
+		for _, w := range out[v] {
+			if !visited[w] {
+				count++
+			}
+		}
+		return count
+	}
+	var extend func(v int) bool
+	extend = func(v int) bool {
+		if steps++; steps%hamiltonianCheckEvery == 0 && stop.Load() {
+			stopped = true
+		}
+		if stopped {
+			return false
+		}
+		visited[v] = true
+		path = append(path, v)
+		if len(path) == n {
+			if !cycle || g.AdjMatrix[v][path[0]] > 0 {
+				return true
+			}
+		} else {
+			next := slices.DeleteFunc(slices.Clone(out[v]), func(w int) bool { return visited[w] })
+			slices.SortStableFunc(next, func(a, b int) int { return cmp.Compare(options(a), options(b)) })
+			for _, w := range next {
+				if extend(w) {
+					return true
+				}
+			}
+		}
+		visited[v] = false
+		path = path[:len(path)-1]
+		return false
+	}
+	for start := range n {
+		if extend(start) {
+			return path, true
+		}
+		if cycle || stopped { // A cycle goes through the first vertex anyway
+			break
+		}
+	}
+	return nil, !stopped
+}
+
+// Asks whether to look for a Hamiltonian cycle or path.
+func (app *App) ShowHamiltonianDialog() {
+	n := len(app.Graph.Vertices)
+	if n == 0 {
+		return
+	}
+	if n > hamiltonianMaxVertices {
+		app.Warn(T("hamilton.too_big", n, hamiltonianMaxVertices))
+		return
+	}
+	app.ShowDialog(&Dialog{Message: T("hamilton.dialog"), Buttons: []DialogButton{
+		{Label: T("hamilton.cycle"), Action: func() { app.FindHamiltonian(true) }},
+		{Label: T("hamilton.path"), Action: func() { app.FindHamiltonian(false) }},
+		{Label: T("dialog.cancel")},
+	}})
+}
+
+// Searches for a Hamiltonian cycle or path in the background, with a dialog to cancel it, then shows
+// what it found. If the graph was edited meanwhile the search starts over on the new one.
+func (app *App) FindHamiltonian(cycle bool) {
+	g, revision := app.Graph.Clone(), app.revision
+	stop := &atomic.Bool{}
+	searching := &Dialog{Message: T("hamilton.searching"), Buttons: []DialogButton{
+		{Label: T("dialog.cancel"), Action: func() { stop.Store(true) }},
+	}}
+	app.ShowDialog(searching)
+	go func() {
+		order, finished := g.hamiltonian(cycle, stop)
+		app.Post(func(app *App) {
+			if app.Dialog == searching {
+				app.Dialog = nil
+			}
+			switch {
+			case !finished:
+				app.Notify(T("hamilton.canceled"))
+			case app.revision != revision: // It would describe another graph, so search the current one
+				if n := len(app.Graph.Vertices); n == 0 || n > hamiltonianMaxVertices {
+					app.Notify(T("hamilton.changed"))
+				} else {
+					app.Notify(T("hamilton.again"))
+					app.FindHamiltonian(cycle)
+				}
+			default:
+				app.showHamiltonian(order, cycle)
+			}
+		})
+	}()
+}
+
+// Highlights a Hamiltonian cycle or path with its vertices numbered, or reports there's none.
+func (app *App) showHamiltonian(order []int, cycle bool) {
+	g := app.Graph
+	if order == nil {
+		msg := T("hamilton.no_path")
+		if cycle {
+			msg = T("hamilton.no_cycle")
+		}
+		app.Notify(msg)
+		app.Announce(msg)
+		return
+	}
+	step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: map[EdgeKey]bool{}, Labels: map[int]string{}}
+	for k, v := range order {
+		step.Vertices[v] = highlightEdgeColor
+		step.Labels[v] = fmt.Sprint(k + 1)
+		if k > 0 {
+			step.Edges[Edge(order[k-1], v)] = true
+		}
+	}
+	title, walk := T("hamilton.path_title"), order
+	if cycle {
+		step.Edges[Edge(order[len(order)-1], order[0])] = true
+		title, walk = T("hamilton.cycle_title"), append(slices.Clone(order), order[0])
+	}
+	step.Text = g.pathText(walk)
+	app.PlayAnimation(&Animation{Title: title, Steps: []AnimationStep{step}})
+	app.Notify(title + ": " + step.Text)
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"cmp"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Adjacency heatmap:
+//
+//	Z:       toggle the heatmap panel.
+//	Shift+Z: next vertex order (also a click on the panel's heading).
+//
+// A panel at the bottom right draws the adjacency matrix as a grid of cells, row i column j for the
+// edges from i to j, shaded by the edge weight where one is set and by the number of edges otherwise,
+// on the gradient of coloring by a metric (colormap.go) from the smallest value to the largest. Rows
+// and columns can be in vertex order, by degree (highest first) or clustered: breadth first from a
+// vertex of lowest degree, neighbors by degree (reverse Cuthill–McKee), which keeps linked vertices
+// close so that groups show up as blocks along the diagonal. Hovering a cell gives its two vertices,
+// the edges and their weight, and marks the vertices on the canvas. Graphs over heatmapMaxVertices
+// vertices are only summed up.
+//
+// Dragging a row up or down, or a column sideways, moves its vertex there: the vertices are renumbered
+// in the new order (one undo step) and the rows and columns go back to vertex order, so what was shown
+// is kept. When the rows aren't in vertex order, a button under the grid renumbers the vertices the
+// way they are, e.g. to keep the clustered order. Exports list the vertices by number, so both carry
+// over to the adjacency matrix, edge lists and files written afterwards.
+
+type HeatmapOrder int
+
+const (
+	HeatmapByIndex HeatmapOrder = iota
+	HeatmapByDegree
+	HeatmapClustered
+)
+
+// Locale keys of the orders, in cycling order.
+var heatmapOrderNames = []string{"heatmap.by_index", "heatmap.by_degree", "heatmap.clustered"}
+
+const (
+	heatmapSize        = 260 // Side of the grid
+	heatmapMaxVertices = 200
+)
+
+// Vertex order and panel position, recomputed after edits.
+type heatmapCache struct {
+	revision int
+	valid    bool
+	orderBy  HeatmapOrder
+	order    []int // Vertex of each row and column
+	heading  nodeBox
+	grid     nodeBox
+	apply    nodeBox      // Renumbering button, empty when the rows are in vertex order
+	drag     *heatmapDrag // Row or column being dragged
+}
+
+// A press on the grid, dragged to move a row or a column.
+type heatmapDrag struct {
+	row, col int     // Cell pressed
+	x, y     float64 // Where
+}
+
+// Returns the vertices in the order of the rows and columns.
+func (app *App) heatmapOrder() []int {
+	c := &app.heatmap
+	if !c.valid || c.revision != app.revision || c.orderBy != app.View.HeatmapOrder {
+		*c = heatmapCache{revision: app.revision, valid: true, orderBy: app.View.HeatmapOrder}
+		switch g := app.Graph; app.View.HeatmapOrder {
+		case HeatmapByDegree:
+			c.order = g.degreeOrder()
+		case HeatmapClustered:
+			c.order = g.cuthillMcKeeOrder()
+		default:
+			c.order = make([]int, len(g.Vertices))
+			for i := range c.order {
+				c.order[i] = i
+			}
+		}
+	}
+	return c.order
+}
+
+// Returns the number of edges at a vertex, both ways in directed mode.
+func (g *Graph) totalDegree(v int) int {
+	if g.Directed {
+		return g.Degree(v) + g.InDegree(v)
+	}
+	return g.Degree(v)
+}
+
+// Returns the vertices by degree, highest first, ties in index order.
+func (g *Graph) degreeOrder() []int {
+	order := make([]int, len(g.Vertices))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(g.totalDegree(b), g.totalDegree(a)) })
+	return order
+}
+
+// Returns the reverse Cuthill–McKee order: each component breadth first from a vertex of lowest
+// degree, neighbors taken by increasing degree, the whole reversed. Edges are read both ways.
+func (g *Graph) cuthillMcKeeOrder() []int {
+	n := len(g.Vertices)
+	byDegree := g.degreeOrder()
+	slices.Reverse(byDegree) // Lowest first
+	seen := make([]bool, n)
+	order := make([]int, 0, n)
+	for _, start := range byDegree {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		order = append(order, start)
+		for k := len(order) - 1; k < len(order); k++ {
+			v := order[k]
+			for _, w := range byDegree {
+				if !seen[w] && w != v && g.Multiplicity(v, w) > 0 {
+					seen[w] = true
+					order = append(order, w)
+				}
+			}
+		}
+	}
+	slices.Reverse(order)
+	return order
+}
+
+// Value a cell is shaded by: the weight of the edges from i to j if set, else their number.
+func (g *Graph) heatValue(i, j int) float64 {
+	count := g.AdjMatrix[i][j]
+	if count == 0 {
+		return 0
+	}
+	if w, ok := g.weightOf(i, j); ok {
+		return w
+	}
+	return float64(count)
+}
+
+// Turns the heatmap panel on or off.
+func (app *App) ToggleHeatmap() {
+	app.toggleView(&app.View.ShowHeatmap, "view.heatmap")
+}
+
+// Switches the rows and columns to the next order.
+func (app *App) CycleHeatmapOrder() {
+	app.View.HeatmapOrder = (app.View.HeatmapOrder + 1) % HeatmapOrder(len(heatmapOrderNames))
+	msg := T("heatmap.order", T(heatmapOrderNames[app.View.HeatmapOrder]))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Takes a click on the panel: the heading cycles the order. Reports whether the click was on the panel.
+func (app *App) ClickHeatmap(x, y float64) bool {
+	c := &app.heatmap
+	if !app.View.ShowHeatmap || !c.valid {
+		return false
+	}
+	if c.heading.contains(x, y) {
+		app.CycleHeatmapOrder()
+		return true
+	}
+	if c.apply.contains(x, y) {
+		app.renumberAsHeatmap(slices.Clone(c.order))
+		return true
+	}
+	return c.grid.contains(x, y)
+}
+
+// Handles dragging a row or column. Returns true while the drag has the mouse.
+func (app *App) UpdateHeatmapDrag(x, y float64) bool {
+	c := &app.heatmap
+	if !app.View.ShowHeatmap || !c.valid {
+		c.drag = nil
+		return false
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if row, col, ok := app.heatmapSlotAt(x, y); ok {
+			c.drag = &heatmapDrag{row: row, col: col, x: x, y: y}
+		}
+	}
+	if c.drag == nil {
+		return false
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if from, to := app.heatmapDrop(x, y); from != to {
+			order := slices.Clone(c.order)
+			v := order[from]
+			order = slices.Insert(slices.Delete(order, from, from+1), to, v)
+			app.renumberAsHeatmap(order)
+		}
+		c.drag = nil
+	}
+	return true
+}
+
+// Returns the row or column slot a drag started from and the one it's over, the rows if the cursor
+// went further up or down than sideways. Past the grid counts as the first or last slot.
+func (app *App) heatmapDrop(x, y float64) (from, to int) {
+	c := &app.heatmap
+	d, n := c.drag, len(c.order)
+	cell := float64(c.grid.W) / float64(n)
+	if math.Abs(y-d.y) >= math.Abs(x-d.x) {
+		return d.row, max(0, min(n-1, int(math.Floor((y-float64(c.grid.Y))/cell))))
+	}
+	return d.col, max(0, min(n-1, int(math.Floor((x-float64(c.grid.X))/cell))))
+}
+
+// Renumbers the vertices in the given order, showing the rows in vertex order so they stay put.
+func (app *App) renumberAsHeatmap(order []int) {
+	app.BeginTransaction(T("undo.reorder"))
+	ok := app.Do(Action{Kind: ActionReorderVertices, Order: order})
+	app.Commit()
+	if ok {
+		app.View.HeatmapOrder = HeatmapByIndex
+		app.Notify(T("heatmap.renumbered"))
+	}
+}
+
+// Returns the row and column of the cell under the cursor, ok is false if it's off the grid.
+func (app *App) heatmapSlotAt(x, y float64) (row, col int, ok bool) {
+	c := &app.heatmap
+	if !c.grid.contains(x, y) || len(c.order) == 0 {
+		return 0, 0, false
+	}
+	cell := float64(c.grid.W) / float64(len(c.order))
+	row, col = int((y-float64(c.grid.Y))/cell), int((x-float64(c.grid.X))/cell)
+	if row >= len(c.order) || col >= len(c.order) {
+		return 0, 0, false
+	}
+	return row, col, true
+}
+
+// Returns the row and column vertices of the cell under the cursor, ok is false if it's off the grid.
+func (app *App) heatmapCellAt(x, y float64) (i, j int, ok bool) {
+	row, col, ok := app.heatmapSlotAt(x, y)
+	if !ok {
+		return 0, 0, false
+	}
+	return app.heatmap.order[row], app.heatmap.order[col], true
+}
+
+// Draws the heatmap panel, with the hovered cell described under the grid.
+func (app *App) DrawHeatmap(screen *ebiten.Image) {
+	if !app.View.ShowHeatmap {
+		return
+	}
+	g := app.Graph
+	order := app.heatmapOrder()
+	c := &app.heatmap
+	n := len(order)
+	w, h := logicalSize(screen)
+	lineColor := color.RGBA{200, 200, 200, 255}
+	heading := T("heatmap.heading", T(heatmapOrderNames[app.View.HeatmapOrder]))
+	if n == 0 || n > heatmapMaxVertices {
+		text := T("heatmap.too_big", n, heatmapMaxVertices)
+		if n == 0 {
+			text = T("heatmap.empty")
+		}
+		pw := float32(textWidth(text) + 10)
+		x, y := float32(w)-pw-10, float32(h-60-22)
+		fillRect(screen, x, y, pw, 22, color.RGBA{30, 30, 30, 230}, true)
+		strokeRect(screen, x, y, pw, 22, 1, lineColor, true)
+		printAt(screen, text, int(x)+5, int(y)+3)
+		c.heading, c.grid, c.apply = nodeBox{}, nodeBox{}, nodeBox{}
+		return
+	}
+
+	pw, ph := float32(heatmapSize+20), float32(heatmapSize+66)
+	x, y := float32(w)-pw-10, float32(h-60)-ph
+	fillRect(screen, x, y, pw, ph, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, pw, ph, 1, lineColor, true)
+	c.heading = nodeBox{x, y, pw, 22}
+	printAt(screen, heading, int(x)+5, int(y)+3)
+
+	// Cells, shaded between the smallest and largest values
+	c.grid = nodeBox{x + 10, y + 26, heatmapSize, heatmapSize}
+	fillRect(screen, c.grid.X, c.grid.Y, heatmapSize, heatmapSize, color.RGBA{15, 15, 15, 255}, false)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, i := range order {
+		for _, j := range order {
+			if g.AdjMatrix[i][j] > 0 {
+				lo, hi = math.Min(lo, g.heatValue(i, j)), math.Max(hi, g.heatValue(i, j))
+			}
+		}
+	}
+	cell := float32(heatmapSize) / float32(n)
+	for row, i := range order {
+		for col, j := range order {
+			if g.AdjMatrix[i][j] == 0 {
+				continue
+			}
+			t := 1.0
+			if hi > lo {
+				t = (g.heatValue(i, j) - lo) / (hi - lo)
+			}
+			fillRect(screen, c.grid.X+float32(col)*cell, c.grid.Y+float32(row)*cell, max(cell, 1), max(cell, 1), gradientColor(t), false)
+		}
+	}
+	strokeRect(screen, c.grid.X, c.grid.Y, heatmapSize, heatmapSize, 1, lineColor, true)
+
+	// The renumbering button, unless the rows are in vertex order already
+	c.apply = nodeBox{}
+	if !slices.IsSorted(order) {
+		label := T("heatmap.apply")
+		c.apply = nodeBox{x + 5, y + ph - 19, float32(textWidth(label) + 10), 17}
+		fillRect(screen, c.apply.X, c.apply.Y, c.apply.W, c.apply.H, color.RGBA{60, 60, 60, 255}, true)
+		strokeRect(screen, c.apply.X, c.apply.Y, c.apply.W, c.apply.H, 1, lineColor, true)
+		printAt(screen, label, int(c.apply.X)+5, int(c.apply.Y)+1)
+	}
+
+	// A row or column being dragged, outlined where it would go
+	cx, cy := cursorPosition()
+	if c.drag != nil {
+		from, to := app.heatmapDrop(float64(cx), float64(cy))
+		if math.Abs(float64(cy)-c.drag.y) >= math.Abs(float64(cx)-c.drag.x) {
+			strokeRect(screen, c.grid.X, c.grid.Y+float32(from)*cell, heatmapSize, cell, 1, lineColor, false)
+			strokeRect(screen, c.grid.X, c.grid.Y+float32(to)*cell, heatmapSize, cell, 2, highlightEdgeColor, false)
+		} else {
+			strokeRect(screen, c.grid.X+float32(from)*cell, c.grid.Y, cell, heatmapSize, 1, lineColor, false)
+			strokeRect(screen, c.grid.X+float32(to)*cell, c.grid.Y, cell, heatmapSize, 2, highlightEdgeColor, false)
+		}
+		app.drawHitHighlight(screen, Hit{Vertex: order[from]})
+		printAt(screen, T("heatmap.moving", g.Vertices[order[from]].Label, to+1), int(x)+5, int(y+ph)-36)
+		return
+	}
+
+	// The hovered cell: its row and column outlined, its vertices marked on the canvas
+	i, j, ok := app.heatmapCellAt(float64(cx), float64(cy))
+	if !ok {
+		printAt(screen, T("heatmap.hint"), int(x)+5, int(y+ph)-36)
+		return
+	}
+	row, col := slices.Index(order, i), slices.Index(order, j)
+	strokeRect(screen, c.grid.X, c.grid.Y+float32(row)*cell, heatmapSize, cell, 1, highlightEdgeColor, false)
+	strokeRect(screen, c.grid.X+float32(col)*cell, c.grid.Y, cell, heatmapSize, 1, highlightEdgeColor, false)
+	app.drawHitHighlight(screen, Hit{Vertex: i})
+	if j != i {
+		app.drawHitHighlight(screen, Hit{Vertex: j})
+	}
+	printAt(screen, app.heatmapCellText(i, j), int(x)+5, int(y+ph)-36)
+}
+
+// Describes the cell of row i and column j: its vertices, the edges and their weight.
+func (app *App) heatmapCellText(i, j int) string {
+	g := app.Graph
+	arrow := " - "
+	if g.Directed {
+		arrow = " → "
+	}
+	ends := g.Vertices[i].Label + arrow + g.Vertices[j].Label
+	count := g.AdjMatrix[i][j]
+	if count == 0 {
+		return T("heatmap.no_edge", ends)
+	}
+	if w, ok := g.weightOf(i, j); ok {
+		return T("heatmap.weighted", ends, count, formatMetric(w))
+	}
+	return T("heatmap.edges", ends, count)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// HiDPI rendering:
+
+// The sketchpad works in fixed logical coordinates (screenWidth x screenHeight) but Layout asks for
+// as many pixels as the window really has, device scale factor included, so vertices and strokes are
+// rasterized at full resolution instead of being scaled up from a small surface.
+// All drawing goes through the helpers below, which scale logical coordinates by renderScale
+// (and shift them by the camera's pan in the world pass, see screenspace.go), and cursorPosition turns the cursor back into logical coordinates.
+// The debug font is a bitmap: text is drawn at 1x and scaled up with nearest filtering, which keeps it sharp.
+
+var (
+	layoutScale = 1.0 // Device pixels per logical pixel of the screen asked for in Layout
+	renderScale = 1.0 // Same, while drawing the screen; exports always draw at 1
+
+	renderOffsetX, renderOffsetY float64 // Device pixels added after scaling, the camera's pan in the world pass
+)
+
+var textScratch *ebiten.Image // Where text is printed before being scaled up
+
+// Returns the scale fitting the logical screen in the window, in device pixels.
+func fitScale(outsideWidth, outsideHeight int) float64 {
+	s := math.Min(float64(outsideWidth)/screenWidth, float64(outsideHeight)/screenHeight)
+	s *= ebiten.Monitor().DeviceScaleFactor()
+	if s <= 0 {
+		return 1
+	}
+	return s
+}
+
+// Returns the size of an image in logical pixels.
+func logicalSize(img *ebiten.Image) (int, int) {
+	b := img.Bounds()
+	return int(float64(b.Dx()) / renderScale), int(float64(b.Dy()) / renderScale)
+}
+
+// Returns the cursor position in logical coordinates.
+func cursorPosition() (int, int) {
+	x, y := ebiten.CursorPosition()
+	return int(float64(x) / layoutScale), int(float64(y) / layoutScale)
+}
+
+// Drawing helpers, same as the vector and ebitenutil ones but in logical coordinates:
+
+func strokeLine(dst *ebiten.Image, x0, y0, x1, y1, width float32, clr color.Color, antialias bool) {
+	s, ox, oy := float32(renderScale), float32(renderOffsetX), float32(renderOffsetY)
+	vector.StrokeLine(dst, x0*s+ox, y0*s+oy, x1*s+ox, y1*s+oy, width*s, clr, antialias)
+}
+
+func fillRect(dst *ebiten.Image, x, y, width, height float32, clr color.Color, antialias bool) {
+	s, ox, oy := float32(renderScale), float32(renderOffsetX), float32(renderOffsetY)
+	vector.DrawFilledRect(dst, x*s+ox, y*s+oy, width*s, height*s, clr, antialias)
+}
+
+func strokeRect(dst *ebiten.Image, x, y, width, height, strokeWidth float32, clr color.Color, antialias bool) {
+	s, ox, oy := float32(renderScale), float32(renderOffsetX), float32(renderOffsetY)
+	vector.StrokeRect(dst, x*s+ox, y*s+oy, width*s, height*s, strokeWidth*s, clr, antialias)
+}
+
+func fillCircle(dst *ebiten.Image, cx, cy, r float32, clr color.Color, antialias bool) {
+	s, ox, oy := float32(renderScale), float32(renderOffsetX), float32(renderOffsetY)
+	vector.DrawFilledCircle(dst, cx*s+ox, cy*s+oy, r*s, clr, antialias)
+}
+
+func strokeCircle(dst *ebiten.Image, cx, cy, r, strokeWidth float32, clr color.Color, antialias bool) {
+	s, ox, oy := float32(renderScale), float32(renderOffsetX), float32(renderOffsetY)
+	vector.StrokeCircle(dst, cx*s+ox, cy*s+oy, r*s, strokeWidth*s, clr, antialias)
+}
+
+// Prints debug font text with its top left corner at (x, y).
+func printAt(dst *ebiten.Image, str string, x, y int) {
+	if renderScale == 1 && renderOffsetX == 0 && renderOffsetY == 0 {
+		ebitenutil.DebugPrintAt(dst, str, x, y)
+		return
+	}
+	if textScratch == nil {
+		textScratch = ebiten.NewImage(screenWidth, screenHeight)
+	}
+	width := 0
+	lines := strings.Split(str, "\n")
+	for _, line := range lines {
+		width = max(width, textWidth(line))
+	}
+	r := image.Rect(0, 0, min(width, screenWidth), min(16*len(lines), screenHeight))
+	area := textScratch.SubImage(r).(*ebiten.Image)
+	area.Clear()
+	ebitenutil.DebugPrintAt(area, str, 0, 0)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(renderScale, renderScale)
+	op.GeoM.Translate(float64(x)*renderScale+renderOffsetX, float64(y)*renderScale+renderOffsetY)
+	dst.DrawImage(area, op)
+}
@@ -0,0 +1,108 @@
+package main
+
+// Undo history:
+
+// Ctrl+Z undoes the last change and Ctrl+Y (or Ctrl+Shift+Z) redoes it. Each undo step is a copy of the graph from right before the change
+// (drawings are small, and a copy can't disagree with what the actions did).
+// Every app.Do is a step of its own, unless it runs inside a transaction: composite operations
+// (a paste, an import, a template, a macro replay, dragging a vertex) call BeginTransaction first
+// and Commit when done, and undo as one step. Transactions nest, only the outermost one makes a step,
+// and one that changed nothing makes none. Undoing a step keeps a copy of the graph it undid for redo;
+// any new change forgets those.
+
+const maxUndoSteps = 100 // Older steps are forgotten
+
+type undoStep struct {
+	Name  string // What the step did, for the notice
+	graph *Graph // The graph before it
+}
+
+type History struct {
+	steps    []undoStep
+	redo     []undoStep // Undone steps, with the graph from before the undo; the last one is redone first
+	depth    int        // Open transactions
+	pending  undoStep   // Snapshot taken by the outermost open transaction
+	revision int        // app.revision when it began
+	dragging bool       // A vertex drag's transaction is open
+}
+
+// Starts a group of changes that undo together.
+func (app *App) BeginTransaction(name string) {
+	h := &app.History
+	if h.depth == 0 {
+		h.pending, h.revision = undoStep{Name: name, graph: app.Graph.Clone()}, app.revision
+	}
+	h.depth++
+}
+
+// Ends the group started by the matching BeginTransaction.
+func (app *App) Commit() {
+	h := &app.History
+	if h.depth == 0 {
+		return
+	}
+	h.depth--
+	if h.depth > 0 {
+		return
+	}
+	if app.revision != h.revision {
+		h.steps = append(h.steps, h.pending)
+		if len(h.steps) > maxUndoSteps {
+			h.steps = h.steps[1:]
+		}
+		h.redo = nil
+	}
+	h.pending = undoStep{}
+}
+
+// Opens a transaction for dragging a vertex, which moves it directly and records the move at the end.
+func (app *App) beginDrag() {
+	if !app.History.dragging {
+		app.History.dragging = true
+		app.BeginTransaction(T("undo.move"))
+	}
+}
+
+// Closes the drag's transaction, if one is open. Called whenever the mouse button is up.
+func (app *App) endDrag() {
+	if app.History.dragging {
+		app.History.dragging = false
+		app.Commit()
+	}
+}
+
+// Puts the graph back as it was before the last step.
+func (app *App) Undo() {
+	h := &app.History
+	if h.depth > 0 || len(h.steps) == 0 {
+		return // Nothing to undo, or in the middle of a change (e.g. a drag)
+	}
+	step := h.steps[len(h.steps)-1]
+	h.steps = h.steps[:len(h.steps)-1]
+	h.redo = append(h.redo, undoStep{Name: step.Name, graph: app.Graph})
+	app.restore(step.graph, T("undo.done", step.Name))
+}
+
+// Puts back the change the last undo took away.
+func (app *App) Redo() {
+	h := &app.History
+	if h.depth > 0 || len(h.redo) == 0 {
+		return
+	}
+	step := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.steps = append(h.steps, undoStep{Name: step.Name, graph: app.Graph})
+	app.restore(step.graph, T("undo.redone", step.Name))
+}
+
+// Swaps in a graph from the history, dropping whatever pointed into the old one.
+func (app *App) restore(g *Graph, msg string) {
+	app.Graph = g
+	app.revision++
+	app.Selected, app.EdgeStart, app.MovingVertex, app.Focused, app.PenLast = nil, nil, nil, nil, nil
+	app.Selection.Clear()
+	app.StopAnimation()
+
+	app.Notify(msg)
+	app.Announce(msg)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Localization:
+
+// All UI text is looked up by key in a locale file (locales/<lang>.json, embedded in the binary).
+// Keys missing from the active locale fall back to English, and missing English keys show the key itself.
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+const defaultLocale = "en"
+
+var (
+	messages         map[string]string // Active locale
+	fallbackMessages map[string]string // English
+)
+
+// Reads a locale file.
+func loadLocale(lang string) (map[string]string, error) {
+	data, err := localeFiles.ReadFile("locales/" + lang + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("unknown locale %q", lang)
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("locale %q: %w", lang, err)
+	}
+	return m, nil
+}
+
+// Switches the UI language.
+// An empty lang picks the language from the environment (LC_ALL, LC_MESSAGES, LANG).
+func SetLocale(lang string) error {
+	if fallbackMessages == nil {
+		m, err := loadLocale(defaultLocale)
+		if err != nil {
+			return err
+		}
+		fallbackMessages = m
+	}
+	if lang == "" {
+		lang = envLocale()
+	}
+	m, err := loadLocale(lang)
+	if err != nil {
+		messages = fallbackMessages
+		return err
+	}
+	messages = m
+	return nil
+}
+
+// Picks the language code out of the usual locale environment variables ("es_ES.UTF-8" -> "es").
+func envLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" && v != "C" && v != "POSIX" {
+			lang, _, _ := strings.Cut(v, "_")
+			lang, _, _ = strings.Cut(lang, ".")
+			return strings.ToLower(lang)
+		}
+	}
+	return defaultLocale
+}
+
+// Translates a UI string.
+// Extra arguments are formatted into the translation with fmt.Sprintf.
+func T(key string, args ...any) string {
+	msg, ok := messages[key]
+	if !ok {
+		msg, ok = fallbackMessages[key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"image"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Frame timing and idle redraws:
+
+// What moves by itself (auto layout, animations) advances by the time since the last update
+// rather than by a fixed amount per frame, so it runs at the same speed whatever the tick rate.
+// The screen isn't cleared every frame: when nothing is animating, being edited or touched,
+// Draw leaves the last frame up instead of drawing the same picture again,
+// so an idle sketchpad doesn't keep the CPU and GPU busy.
+
+const (
+	referenceFrame = time.Second / 60 // Per-frame speeds in the settings are meant at this frame time
+	maxFrameTime   = time.Second / 10 // Longer gaps (window dragged, machine asleep) don't jump ahead
+)
+
+type frameClock struct {
+	last     time.Time     // Previous update
+	dt       time.Duration // Time since the previous update
+	redraw   bool          // Something may look different since the last frame drawn
+	cursor   image.Point   // Cursor position at the previous update
+	size     image.Point   // Screen size of the last frame drawn
+	revision int           // Graph revision of the last frame drawn
+}
+
+// Measures the time since the previous update and notes whether the screen needs redrawing.
+// Called at the start of every update.
+func (app *App) tick() {
+	c := &app.clock
+	now := time.Now()
+	if !c.last.IsZero() {
+		c.dt = min(now.Sub(c.last), maxFrameTime)
+	}
+	c.last = now
+
+	x, y := ebiten.CursorPosition()
+	cursor := image.Pt(x, y)
+	if cursor != c.cursor || app.inputActive() || app.animating() {
+		c.redraw = true
+	}
+	c.cursor = cursor
+}
+
+// Returns how many reference frames passed since the previous update.
+func (app *App) frames() float64 {
+	return float64(app.clock.dt) / float64(referenceFrame)
+}
+
+// Reports whether a key, a mouse button or the wheel is in use this update.
+func (app *App) inputActive() bool {
+	if len(inpututil.AppendPressedKeys(nil)) > 0 || len(inpututil.AppendJustReleasedKeys(nil)) > 0 {
+		return true
+	}
+	for _, b := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle} {
+		if ebiten.IsMouseButtonPressed(b) || inpututil.IsMouseButtonJustReleased(b) {
+			return true
+		}
+	}
+	wx, wy := ebiten.Wheel()
+	return wx != 0 || wy != 0
+}
+
+// Reports whether something on screen changes by itself.
+func (app *App) animating() bool {
+	return (app.Animation != nil && app.Animation.Playing) ||
+		app.View.AutoLayout || app.LayoutRun != nil ||
+		app.Importing != nil || // Progress bar
+		app.Debug != nil || // Frame times
+		len(app.Warnings) > 0 || // They go away
+		app.TextInput != nil || // Blinking caret
+		app.Tutorial != nil || // Pulsing outline
+		(app.Hover != nil && time.Since(app.Hover.Since) < tooltipDelay+maxFrameTime) // Tooltip coming up
+}
+
+// Reports whether the frame has to be drawn, and if so clears the screen for it.
+func (app *App) beginFrame(screen *ebiten.Image) bool {
+	c := &app.clock
+	size := screen.Bounds().Size()
+	if !c.redraw && size == c.size && app.revision == c.revision {
+		return false // The last frame is still on screen
+	}
+	c.redraw, c.size, c.revision = false, size, app.revision
+	screen.Clear()
+	return true
+}
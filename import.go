@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Importing graphs:
+
+// Graph files from other tools are read into an ImportedGraph first, then loaded like a template
+// (replacing the drawing, after confirmation). Readers only deal with their format;
+// labels, positions, colors and leftover attributes are handled here for all of them.
+// The reader is picked by file extension, see importers; streamImporters read from the file as
+// it goes instead of loading it whole first.
+//
+// Files (or Neo4j servers) are opened with Ctrl+O, or by passing them on the command line. They are
+// read in the background with a progress bar (importjob.go).
+// Vertices without a position are placed on a circle, positions are scaled to fit the canvas, except
+// in the JSON files the sketchpad writes, which come back where they were saved.
+
+const importMargin = 40 // Between the imported drawing and the canvas border
+
+var errUnknownFormat = errors.New("unknown file format")
+
+type ImportedGraph struct {
+	Vertices []ImportedVertex
+	Edges    []ImportedEdge
+	Directed bool // Directed mode, else undirected
+	Exact    bool // Positions are the sketchpad's own (nodelink.go), kept as they are
+}
+
+type ImportedVertex struct {
+	Label  string
+	X, Y   float64
+	HasPos bool
+	Color  *color.RGBA // nil for the default color
+	Radius float64     // 0 for the default radius
+	Attrs  map[string]string
+}
+
+type ImportedEdge struct {
+	A, B  int // Indices into ImportedGraph.Vertices
+	Attrs map[string]string
+}
+
+// Readers by file extension.
+var importers = map[string]func(data []byte) (*ImportedGraph, error){
+	".json": parseJSONGraph,
+	".tgf":  parseTGF,
+	".gml":  parseGML,
+	".dot":  parseDOT,
+	".gv":   parseDOT,
+}
+
+// Readers by file extension that take the file as a stream.
+var streamImporters = map[string]func(r io.Reader) (*ImportedGraph, error){
+	".graphml": parseGraphML,
+}
+
+// Reports whether there is a reader for the file's extension.
+func canImport(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	_, ok := importers[ext]
+	_, streamOK := streamImporters[ext]
+	return ok || streamOK
+}
+
+// Reports whether Ctrl+S can save to the file at path: a session or a graph file the exports write.
+func canSave(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gts", ".tgf", ".gml", ".dot", ".json":
+		return true
+	}
+	return false
+}
+
+// Parses "#rrggbb" (or "rrggbb"), nil if it isn't a color.
+func parseHexColor(s string) *color.RGBA {
+	var c color.RGBA
+	if _, err := fmt.Sscanf(strings.TrimPrefix(s, "#"), "%02x%02x%02x", &c.R, &c.G, &c.B); err != nil || len(strings.TrimPrefix(s, "#")) != 6 {
+		return nil
+	}
+	c.A = 255
+	return &c
+}
+
+// Reads a graph file, picking the reader by extension.
+func readGraphFile(path string) (*ImportedGraph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readGraph(filepath.Ext(path), f)
+}
+
+// Reads a graph in the format of a file extension.
+func readGraph(ext string, r io.Reader) (*ImportedGraph, error) {
+	ext = strings.ToLower(ext)
+	if parse, ok := streamImporters[ext]; ok {
+		return parse(r)
+	}
+	parse, ok := importers[ext]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, ext)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data)
+}
+
+// Positions of the imported vertices on the canvas: theirs scaled to fit (as they are if exact), or a
+// circle if any is missing.
+func (ig *ImportedGraph) positions() []point {
+	cx, cy := float64(screenWidth)/2, (canvasTop()+screenHeight)/2
+	n := len(ig.Vertices)
+	for _, v := range ig.Vertices {
+		if !v.HasPos {
+			return circle(n, cx, cy, math.Min(screenWidth, screenHeight-canvasTop())/2-importMargin)
+		}
+	}
+	if ig.Exact {
+		positions := make([]point, n)
+		for i, v := range ig.Vertices {
+			positions[i] = point{v.X, v.Y}
+		}
+		return positions
+	}
+
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, v := range ig.Vertices {
+		minX, minY, maxX, maxY = math.Min(minX, v.X), math.Min(minY, v.Y), math.Max(maxX, v.X), math.Max(maxY, v.Y)
+	}
+	w, h := float64(screenWidth-2*importMargin), screenHeight-canvasTop()-2*importMargin
+	scale := math.Min(w/math.Max(maxX-minX, 1e-9), h/math.Max(maxY-minY, 1e-9))
+	if maxX == minX && maxY == minY {
+		scale = 0 // A single spot, put it in the middle
+	}
+	positions := make([]point, n)
+	for i, v := range ig.Vertices {
+		positions[i] = point{cx + (v.X-(minX+maxX)/2)*scale, cy + (v.Y-(minY+maxY)/2)*scale}
+	}
+	return positions
+}
+
+// Replaces the drawing with an imported graph.
+func (app *App) loadImported(ig *ImportedGraph) {
+	app.BeginTransaction(T("undo.import"))
+	defer app.Commit()
+	if len(app.Graph.Vertices) > 0 {
+		app.Do(Action{Kind: ActionClear})
+	}
+	if ig.Directed != app.Graph.Directed { // The file's mode, whatever the drawing was in
+		app.Do(Action{Kind: ActionSetDirected, Directed: ig.Directed})
+	}
+	for i, p := range ig.positions() {
+		v := ig.Vertices[i]
+		label, clr := v.Label, app.defaultVertexColor()
+		if label == "" {
+			label = fmt.Sprintf("V%d", i+1)
+		}
+		if v.Color != nil {
+			clr = *v.Color
+		}
+		radius := app.Settings.Defaults.VertexRadius
+		if v.Radius > 0 {
+			radius = v.Radius
+		}
+		app.Do(Action{Kind: ActionAddVertex, X: p.X, Y: p.Y, Label: label, Color: clr, Radius: radius})
+		if len(v.Attrs) > 0 {
+			app.Do(Action{Kind: ActionAttrVertex, V1: i, Attrs: v.Attrs})
+		}
+	}
+	for _, e := range ig.Edges {
+		app.Do(Action{Kind: ActionAddEdge, V1: e.A, V2: e.B})
+		attrs := e.Attrs
+		if w, err := strconv.ParseFloat(attrs["weight"], 64); err == nil && !math.IsNaN(w) && !math.IsInf(w, 0) {
+			attrs = maps.Clone(attrs) // A numeric weight is the edge weight, as written by the exports
+			delete(attrs, "weight")
+			app.Do(Action{Kind: ActionWeightEdge, V1: e.A, V2: e.B, Weight: w})
+		}
+		if len(attrs) > 0 {
+			app.Do(Action{Kind: ActionAttrEdge, V1: e.A, V2: e.B, Attrs: attrs})
+		}
+	}
+	app.fireLoad()
+}
+
+// Imports a graph file, asking first if it would replace a drawing.
+// Coordinate files only move the vertices of the current graph (coords.go), sessions replace everything (session.go).
+func (app *App) ImportFile(path string) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		app.applyCoordinates(path)
+		return
+	case ".gts":
+		app.OpenSession(path)
+		return
+	}
+	if !canImport(path) {
+		app.Warn(T("warn.import", path, fmt.Errorf("%w: %s", errUnknownFormat, filepath.Ext(path))))
+		return
+	}
+	app.startImport(path)
+}
+
+// Loads an imported graph, offering first to save unsaved changes. source names it in messages.
+func (app *App) importGraph(source string, ig *ImportedGraph) {
+	load := func() {
+		app.loadImported(ig)
+		app.markSaved(source)
+		app.SavePath = ""
+		if canSave(source) { // Ctrl+S writes it back
+			app.SavePath = source
+		}
+		app.Notify(T("import.done", source, len(ig.Vertices), len(ig.Edges)))
+		app.offerSample()
+	}
+	app.checkUnsaved(load)
+}
+
+// Asks for a file to import, in the file dialog.
+func (app *App) askImport() {
+	app.openFile(app.ImportFile)
+}
+
+// Asks for a file path or a Neo4j URL to import, in a text field.
+func (app *App) askImportPath() {
+	app.askPredicate(T("import.prompt"), func(path string) {
+		switch {
+		case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+			app.askNeo4jQuery(path)
+		case path != "":
+			app.ImportFile(path)
+		}
+	})
+}
+
+// Sets the attributes of the edges between two vertices, nil to drop them.
+func (g *Graph) SetEdgeAttrs(v1, v2 int, attrs map[string]string) error {
+	if err := g.CheckVertices(v1, v2); err != nil {
+		return err
+	}
+	if g.Multiplicity(v1, v2) <= 0 {
+		return fmt.Errorf("%w: %s - %s", ErrNoEdge, g.Vertices[v1].Label, g.Vertices[v2].Label)
+	}
+	if attrs == nil {
+		delete(g.EdgeAttrs, Edge(v1, v2))
+		return nil
+	}
+	if g.EdgeAttrs == nil {
+		g.EdgeAttrs = map[EdgeKey]map[string]string{}
+	}
+	g.EdgeAttrs[Edge(v1, v2)] = attrs
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Background imports:
+
+// Graph files are read on another goroutine so a big one doesn't freeze the window. Stream readers
+// (GraphML) build the graph as the file goes by instead of holding all its text. Meanwhile a bar at
+// the bottom shows how much of the file was read; Escape cancels, which stops the reader at its next
+// read. The graph is loaded, with the usual confirmation, once the whole file is in.
+
+const importBarWidth = 300
+
+// Import being read.
+type ImportJob struct {
+	Path   string
+	read   atomic.Int64 // Bytes read so far
+	total  int64        // File size, 0 if unknown
+	cancel context.CancelFunc
+}
+
+// Counts the bytes read and fails once the import is canceled.
+type importReader struct {
+	ctx context.Context
+	r   io.Reader
+	job *ImportJob
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	r.job.read.Add(int64(n))
+	return n, err
+}
+
+// Returns how much of the file was read, from 0 to 1.
+func (job *ImportJob) Progress() float64 {
+	if job.total <= 0 {
+		return 0
+	}
+	return min(float64(job.read.Load())/float64(job.total), 1)
+}
+
+// Starts reading a graph file in the background, replacing any import still running.
+func (app *App) startImport(path string) {
+	app.CancelImport()
+	f, err := os.Open(path)
+	if err != nil {
+		app.Warn(T("warn.import", path, err))
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &ImportJob{Path: path, cancel: cancel}
+	if info, err := f.Stat(); err == nil {
+		job.total = info.Size()
+	}
+	app.Importing = job
+	go func() {
+		defer f.Close()
+		ig, err := readGraph(filepath.Ext(path), &importReader{ctx: ctx, r: f, job: job})
+		app.Post(func(app *App) {
+			if app.Importing != job {
+				return // Canceled, or replaced by another import
+			}
+			app.Importing = nil
+			cancel()
+			switch {
+			case errors.Is(err, context.Canceled):
+			case err != nil:
+				app.Warn(T("warn.import", path, err))
+			default:
+				app.importGraph(path, ig)
+			}
+		})
+	}()
+}
+
+// Stops the import being read, if any. Returns whether there was one.
+func (app *App) CancelImport() bool {
+	job := app.Importing
+	if job == nil {
+		return false
+	}
+	job.cancel()
+	app.Importing = nil
+	app.Notify(T("import.canceled", job.Path))
+	return true
+}
+
+// Draws the import progress bar at the bottom of the canvas.
+func (app *App) DrawImportProgress(screen *ebiten.Image) {
+	job := app.Importing
+	if job == nil {
+		return
+	}
+	text := T("import.progress", filepath.Base(job.Path), int(job.Progress()*100))
+	_, h := logicalSize(screen)
+	w := float32(max(importBarWidth, textWidth(text)+10))
+	x, y := (float32(screenWidth)-w)/2, float32(h-60)
+	fillRect(screen, x, y, w, 18, color.RGBA{60, 60, 60, 220}, true)
+	fillRect(screen, x, y, w*float32(job.Progress()), 18, color.RGBA{70, 130, 180, 255}, true)
+	printAt(screen, text, int(x)+5, int(y))
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Graph information output:
+
+// The Print Info button asks where the info should go: the console (like before),
+// a text file, a CSV file or the clipboard. It can also save the vertex metrics as a table,
+// one row per vertex keyed by label, for statistics elsewhere (also from the command line, cli.go).
+
+const (
+	infoTextFile    = "graph-info.txt"
+	infoCSVFile     = "graph-info.csv"
+	infoMetricsFile = "graph-metrics.csv"
+)
+
+// Writes graph information as text.
+//
+//	Adjacency matrix.
+//	Number of edges and vertices.
+//	Degree of each vertex (in- and out-degree for a directed graph).
+//	Connected components and their sizes.
+//	Classes the graph belongs to.
+func writeGraphInfo(w io.Writer, g *Graph) {
+	numVertices := len(g.Vertices)
+	numEdges := 0
+	degrees := make([]int, numVertices)
+
+	// Print header:
+	fmt.Fprintln(w, "\n"+T("info.adjacency_matrix"))
+	fmt.Fprintf(w, "          ")
+	for _, vertex := range g.Vertices {
+		fmt.Fprintf(w, "%-10s", vertex.Label)
+	}
+	fmt.Fprintln(w)
+
+	// Print adjacency matrix:
+	for i := range g.AdjMatrix {
+		fmt.Fprintf(w, "%-10s", g.Vertices[i].Label)
+		for j := range g.AdjMatrix[i] {
+			fmt.Fprintf(w, "%-10d", g.AdjMatrix[i][j])
+			if g.AdjMatrix[i][j] > 0 {
+				degrees[i] += g.AdjMatrix[i][j]
+			}
+			numEdges += g.AdjMatrix[i][j]
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Print other graph information:
+	fmt.Fprintln(w, "\n"+T("info.vertices", numVertices))
+	fmt.Fprintln(w, T("info.edges", numEdges))
+	for i, degree := range degrees {
+		if g.Directed {
+			fmt.Fprintln(w, T("info.in_out_degree", i, g.Vertices[i].Label, g.InDegree(i), degree))
+		} else {
+			fmt.Fprintln(w, T("info.degree", i, g.Vertices[i].Label, degree))
+		}
+	}
+	if g.Weighted() {
+		for i, v := range g.Vertices {
+			fmt.Fprintln(w, T("info.weight", i, v.Label, formatMetric(v.Weight)))
+		}
+	}
+	if numVertices > 0 {
+		fmt.Fprintln(w, g.componentSummary())
+	}
+	fmt.Fprintln(w, g.classSummary())
+}
+
+// Writes the adjacency matrix as CSV, with a degree column at the end.
+// The header row and first column hold the vertex labels.
+func writeGraphInfoCSV(w io.Writer, g *Graph) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{""}
+	for _, v := range g.Vertices {
+		header = append(header, v.Label)
+	}
+	header = append(header, T("info.csv_degree"))
+	cw.Write(header)
+
+	for i, row := range g.AdjMatrix {
+		record := []string{g.Vertices[i].Label}
+		for _, count := range row {
+			record = append(record, strconv.Itoa(count))
+		}
+		record = append(record, strconv.Itoa(g.Degree(i)))
+		cw.Write(record)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Name of a built-in metric in CSV headers and on the command line: its locale key without "metric.".
+func metricID(m Metric) string {
+	return strings.TrimPrefix(m.Name, "metric.")
+}
+
+// Returns the built-in metrics with the given names, all of them for none.
+func metricsNamed(names []string) ([]Metric, error) {
+	if len(names) == 0 {
+		return metrics, nil
+	}
+	var ms []Metric
+	for _, name := range names {
+		found := false
+		for _, m := range metrics {
+			if metricID(m) == name {
+				ms, found = append(ms, m), true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", errUnknownMetric, name)
+		}
+	}
+	return ms, nil
+}
+
+// Writes the metrics of every vertex as CSV: a label column, then one column per metric.
+func writeMetricsCSV(w io.Writer, g *Graph, ms []Metric) error {
+	cw := csv.NewWriter(w)
+	header := []string{"label"}
+	columns := make([][]float64, len(ms))
+	for k, m := range ms {
+		header = append(header, metricID(m))
+		columns[k] = m.Values(g)
+	}
+	cw.Write(header)
+	for i, v := range g.Vertices {
+		record := []string{v.Label}
+		for _, values := range columns {
+			record = append(record, strconv.FormatFloat(values[i], 'g', -1, 64))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Saves every built-in metric of every vertex as CSV.
+func (app *App) saveMetrics(path string) {
+	var buf bytes.Buffer
+	writeMetricsCSV(&buf, app.Graph, metrics)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		app.Warn(T("warn.write_file", path, err))
+		return
+	}
+	app.Notify(T("info.saved", path))
+}
+
+// Prints graph information to the console.
+func (app *App) printGraphInfo() {
+	writeGraphInfo(os.Stdout, app.Graph)
+}
+
+// Writes graph information to a file, as CSV if csvFormat is set.
+func (app *App) saveGraphInfo(path string, csvFormat bool) {
+	var buf bytes.Buffer
+	if csvFormat {
+		writeGraphInfoCSV(&buf, app.Graph)
+	} else {
+		writeGraphInfo(&buf, app.Graph)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		app.Warn(T("warn.write_file", path, err))
+		return
+	}
+	app.Notify(T("info.saved", path))
+}
+
+// Copies graph information (as text) to the clipboard.
+func (app *App) copyGraphInfo() {
+	var buf bytes.Buffer
+	writeGraphInfo(&buf, app.Graph)
+	if err := writeClipboard(buf.String()); err != nil {
+		app.Warn(T("warn.clipboard", err))
+		return
+	}
+	app.Notify(T("info.copied"))
+}
+
+// Asks where the graph information should go.
+func (app *App) ShowInfoDialog() {
+	app.ShowDialog(&Dialog{
+		Message: T("info.dialog"),
+		Buttons: []DialogButton{
+			{Label: T("info.console"), Action: app.printGraphInfo},
+			{Label: T("info.text_file"), Action: func() { app.saveFile(infoTextFile, func(path string) { app.saveGraphInfo(path, false) }) }},
+			{Label: T("info.csv_file"), Action: func() { app.saveFile(infoCSVFile, func(path string) { app.saveGraphInfo(path, true) }) }},
+			{Label: T("info.metrics_file"), Action: func() { app.saveFile(infoMetricsFile, app.saveMetrics) }},
+			{Label: T("info.clipboard"), Action: app.copyGraphInfo},
+			{Label: T("dialog.cancel")},
+		},
+	})
+}
@@ -0,0 +1,160 @@
+package main
+
+// Editor actions:
+
+// The mouse is handled in two steps. HandleMouseInput maps clicks and drags on the canvas to editor
+// actions (place a vertex, begin an edge, delete what's at a point...), in world coordinates, and
+// Perform carries them out. Perform knows nothing of ebiten, buttons or double click timing, so
+// anything else driving the editor (touch, scripts, a network peer, tests) can hand it the same
+// actions. Actions on an edge ask which one in a menu when the click is ambiguous (disambiguate.go). The toolbar, option bar, sliders and camera are screen UI and stay with the mouse.
+
+type EditKind int
+
+const (
+	EditPlaceVertex    EditKind = iota // Add a vertex at (X, Y)
+	EditPenTo                          // Pen stroke to the vertex at (X, Y), or to a new vertex there
+	EditBeginEdge                      // Pick the vertex at (X, Y) as the start of an edge, or its end if one is started
+	EditPickVertex                     // Select the vertex at (X, Y) for moving, or grab the label there, or drop the selection if there's none
+	EditMoveVertexTo                   // Drag the vertex or label being moved (or the vertex at (X, Y)) to (X, Y)
+	EditDeleteAt                       // Delete the vertex or edge at (X, Y)
+	EditDeleteVertexAt                 // Delete the vertex at (X, Y)
+	EditDeleteEdgeAt                   // Delete one edge at (X, Y)
+	EditColorVertexAt                  // Give the vertex at (X, Y) the next palette color
+	EditRenameAt                       // Edit the label of the vertex at (X, Y)
+	EditSelectAt                       // Select what's at (X, Y), or start a lasso there
+	EditDragTo                         // Drag the lasso or rectangle being drawn to (X, Y)
+	EditWeighEdgeAt                    // Ask for the weight of the edge at (X, Y)
+	EditSplitEdgeAt                    // Put a vertex on the edge at (X, Y)
+	EditGameMoveAt                     // Play the open game at (X, Y)
+	EditRelease                        // Let go: record a vertex or label moved, finish a lasso
+	EditEndChain                       // End the pen's chain of edges
+	EditTraverseFrom                   // Animate a traversal from the vertex at (X, Y)
+	EditPathTo                         // Pick the vertex at (X, Y) as an end of a shortest path, or clear the path shown
+)
+
+type EditAction struct {
+	Kind       EditKind
+	X, Y       float64 // World coordinates
+	Extend     bool    // EditSelectAt: add to the selection (or take out of it) instead of replacing it
+	DepthFirst bool    // EditTraverseFrom: depth first instead of breadth first
+}
+
+// Carries out an editor action.
+func (app *App) Perform(a EditAction) {
+	x, y := a.X, a.Y
+	switch a.Kind {
+	case EditPlaceVertex:
+		app.AddVertexAt(x, y)
+	case EditPenTo:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.PenTo(i, app.Graph.Vertices[i].X, app.Graph.Vertices[i].Y)
+		} else {
+			app.PenTo(-1, x, y)
+		}
+	case EditBeginEdge:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.pickEdgeEnd(i, ActionAddEdge)
+		}
+	case EditPickVertex:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.Selected = &i
+		} else if i := app.LabelAt(x, y); i >= 0 {
+			app.grabLabel(i, x, y)
+		} else {
+			app.Selected = nil
+		}
+	case EditMoveVertexTo:
+		if app.MovingLabel != nil {
+			app.dragLabelTo(x, y)
+			break
+		}
+		if app.MovingVertex == nil {
+			if i := app.VertexAt(x, y); i >= 0 {
+				app.beginDrag()
+				app.MovingVertex = &i
+				v := app.Graph.Vertices[i]
+				app.vertexGrab, app.vertexFrom = point{x - v.X, y - v.Y}, point{v.X, v.Y}
+			}
+		}
+		if app.MovingVertex != nil && app.Graph.CheckVertices(*app.MovingVertex) == nil {
+			v := &app.Graph.Vertices[*app.MovingVertex]
+			v.X, v.Y = x-app.vertexGrab.X, y-app.vertexGrab.Y
+		}
+	case EditDeleteAt:
+		app.DeleteAt(x, y)
+	case EditDeleteVertexAt:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.deleteVertex(i)
+		}
+	case EditDeleteEdgeAt:
+		app.pickHit(x, y, false, true, func(h Hit) { app.Do(Action{Kind: ActionDeleteEdge, V1: h.V1, V2: h.V2}) })
+	case EditColorVertexAt:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.Do(Action{Kind: ActionColorVertex, V1: i, Color: app.nextVertexColor(app.Graph.Vertices[i].Color)})
+		}
+	case EditRenameAt:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.Selected = &i
+			app.EditLabel(i, app.Graph.Vertices[i].Label)
+		}
+	case EditSelectAt:
+		app.SelectAt(x, y, a.Extend)
+	case EditDragTo:
+		if app.SelectDrag != nil {
+			app.updateSelectDrag(x, y)
+		}
+	case EditWeighEdgeAt:
+		app.pickHit(x, y, false, true, func(h Hit) { app.askEdgeWeight(h.V1, h.V2) })
+	case EditSplitEdgeAt:
+		app.pickHit(x, y, false, true, func(h Hit) { app.SubdivideEdge(h.V1, h.V2, x, y) })
+	case EditGameMoveAt:
+		app.GameClick(x, y)
+	case EditRelease:
+		if app.MovingVertex != nil && app.Graph.CheckVertices(*app.MovingVertex) == nil { // Drag finished, record where the vertex ended up
+			v := app.Graph.Vertices[*app.MovingVertex]
+			if v.X != app.vertexFrom.X || v.Y != app.vertexFrom.Y { // A click without a drag moves nothing
+				app.Do(Action{Kind: ActionMoveVertex, V1: *app.MovingVertex, X: v.X, Y: v.Y})
+			}
+		}
+		if app.MovingLabel != nil {
+			app.dropLabel()
+		}
+		app.endDrag()
+		app.MovingVertex = nil
+		if app.SelectDrag != nil {
+			app.finishSelectDrag()
+		}
+	case EditTraverseFrom:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.Traverse(i, a.DepthFirst)
+		}
+	case EditPathTo:
+		if i := app.VertexAt(x, y); i >= 0 {
+			app.pickPathEnd(i)
+		} else {
+			app.StopAnimation()
+			app.EdgeStart = nil
+		}
+	case EditEndChain:
+		if app.PenLast != nil {
+			app.EndPenChain()
+		}
+	}
+}
+
+// Editor action of each tool's left click on the canvas. Print Info acts when picked, not on clicks.
+var clickActions = map[Tool]EditKind{
+	ToolAddVertex:    EditPlaceVertex,
+	ToolAddEdge:      EditBeginEdge,
+	ToolPen:          EditPenTo,
+	ToolDeleteVertex: EditDeleteVertexAt,
+	ToolDeleteEdge:   EditDeleteEdgeAt,
+	ToolDelete:       EditDeleteAt,
+	ToolMoveVertex:   EditPickVertex,
+	ToolColorVertex:  EditColorVertexAt,
+	ToolNameVertex:   EditRenameAt,
+	ToolSelect:       EditSelectAt,
+	ToolEdgeWeight:   EditWeighEdgeAt,
+	ToolTraverse:     EditTraverseFrom,
+	ToolShortestPath: EditPathTo,
+}
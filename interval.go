@@ -0,0 +1,80 @@
+package main
+
+import "sort"
+
+// Interval graphs:
+
+// A graph is an interval graph (the intersection graph of intervals on a line) exactly when it's chordal
+// and its complement can be oriented transitively (Gilmore & Hoffman). The maximal cliques come from
+// the LBFS ordering (chordal.go), the orientation from permutation.go. Any transitive orientation of the
+// complement then puts the cliques in a row where every vertex is in consecutive cliques: a clique
+// goes before another when one of its vertices points to one of the other's. The row is checked before
+// it's returned. Both steps take polynomial time, so the answer is always yes or no.
+
+// Returns the maximal cliques in an order where every vertex's cliques are consecutive,
+// and whether the graph is an interval graph.
+func (g *Graph) IntervalCliqueOrder() ([][]int, bool) {
+	if !g.IsSimple() {
+		return nil, false
+	}
+	peo, chordal := g.PerfectEliminationOrder()
+	if !chordal {
+		return nil, false
+	}
+	n := len(g.Vertices)
+	apart, ok := transitiveOrientation(n, func(a, b int) bool { return a != b && !g.adjacent(a, b) })
+	if !ok {
+		return nil, false
+	}
+	row := g.chordalCliques(peo)
+	sort.SliceStable(row, func(i, j int) bool {
+		for _, a := range row[i] {
+			for _, b := range row[j] {
+				if apart[a][b] {
+					return true
+				}
+			}
+		}
+		return false
+	})
+	return row, consecutiveCliques(row, n)
+}
+
+// Reports whether every vertex is in consecutive cliques of the row.
+func consecutiveCliques(row [][]int, n int) bool {
+	last := make([]int, n) // Last clique each vertex was seen in, -1 for none yet
+	for v := range last {
+		last[v] = -1
+	}
+	for k, clique := range row {
+		for _, v := range clique {
+			if last[v] >= 0 && last[v] != k-1 {
+				return false
+			}
+			last[v] = k
+		}
+	}
+	return true
+}
+
+// Returns an interval for each vertex, the first and last clique it's in along the clique order,
+// and whether the graph is an interval graph.
+func (g *Graph) IntervalModel() ([][2]int, bool) {
+	row, ok := g.IntervalCliqueOrder()
+	if !ok {
+		return nil, false
+	}
+	intervals := make([][2]int, len(g.Vertices))
+	for i := range intervals {
+		intervals[i] = [2]int{-1, -1}
+	}
+	for k, clique := range row {
+		for _, v := range clique {
+			if intervals[v][0] < 0 {
+				intervals[v][0] = k
+			}
+			intervals[v][1] = k
+		}
+	}
+	return intervals, true
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Intervals on a line make an interval graph, and the model found has the same overlaps.
+func TestIntervalGraphsRecognized(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 2000 {
+		n := 1 + rng.Intn(14)
+		spans := make([][2]int, n)
+		for i := range spans {
+			start := rng.Intn(20)
+			spans[i] = [2]int{start, start + rng.Intn(8)}
+		}
+		g := testGraph(n, false)
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				if spans[i][0] <= spans[j][1] && spans[j][0] <= spans[i][1] {
+					g.AddEdge(i, j)
+				}
+			}
+		}
+		model, ok := g.IntervalModel()
+		if !ok {
+			t.Fatalf("intervals %v: not recognized", spans)
+		}
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				overlap := model[i][0] <= model[j][1] && model[j][0] <= model[i][1]
+				if overlap != g.adjacent(i, j) {
+					t.Fatalf("intervals %v: model %v overlaps %d and %d: %v", spans, model, i, j, overlap)
+				}
+			}
+		}
+	}
+}
+
+// On small graphs the answer matches trying every order of the maximal cliques.
+func TestIntervalMatchesEveryCliqueOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for range 3000 {
+		n := 1 + rng.Intn(8)
+		g := testGraph(n, false)
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				if rng.Float64() < 0.5 {
+					g.AddEdge(i, j)
+				}
+			}
+		}
+		want := false
+		if peo, chordal := g.PerfectEliminationOrder(); chordal {
+			want = someCliqueOrder(g.chordalCliques(peo), nil, n)
+		}
+		if _, ok := g.IntervalCliqueOrder(); ok != want {
+			t.Fatalf("%v: interval %v, want %v", g.AdjMatrix, ok, want)
+		}
+	}
+}
+
+// Reports whether the cliques not in row yet can follow it with every vertex's cliques consecutive.
+func someCliqueOrder(cliques, row [][]int, n int) bool {
+	if !consecutiveCliques(row, n) {
+		return false
+	}
+	if len(cliques) == 0 {
+		return true
+	}
+	for i, c := range cliques {
+		rest := append(append([][]int{}, cliques[:i]...), cliques[i+1:]...)
+		if someCliqueOrder(rest, append(row, c), n) {
+			return true
+		}
+	}
+	return false
+}
+
+// A tree with three long arms is chordal but not interval: its leaves are an asteroidal triple.
+func TestIntervalSubdividedClaw(t *testing.T) {
+	g := testGraph(7, false, [2]int{0, 1}, [2]int{0, 2}, [2]int{0, 3}, [2]int{1, 4}, [2]int{2, 5}, [2]int{3, 6})
+	if _, ok := g.IntervalCliqueOrder(); ok {
+		t.Error("subdivided claw is interval")
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"image/color"
+)
+
+// Action journal:
+
+// Every edit made through the app is recorded as an Action.
+// Actions refer to vertices by index, same as the rest of the graph code.
+
+type ActionKind int
+
+const (
+	ActionAddVertex ActionKind = iota
+	ActionAddEdge
+	ActionDeleteVertex
+	ActionDeleteEdge
+	ActionMoveVertex
+	ActionColorVertex
+	ActionNameVertex
+	ActionClear
+	ActionStyleEdge
+	ActionWeightVertex
+	ActionTimeVertex
+	ActionTimeEdge
+	ActionAttrVertex
+	ActionAttrEdge
+	ActionDefineAttr
+	ActionSetDirected
+	ActionWeightEdge
+	ActionLayerVertex
+	ActionPlaceLabel
+	ActionReorderVertices
+)
+
+type Action struct {
+	Kind     ActionKind
+	V1, V2   int     // Vertex indices (V2 only used by edge actions)
+	X, Y     float64 // Position for add/move
+	Label    string  // Label for add/name
+	Color    color.RGBA
+	Radius   float64           // Radius for add
+	Width    float64           // Edge width for style
+	Curved   bool              // Edge drawn as an arc, for style
+	Weight   float64           // Vertex or edge weight
+	Time     *Interval         // Active times, nil for always
+	Attrs    map[string]string // Attributes, replacing the old ones
+	Column   *AttrColumn       // Attribute column to define
+	Directed bool              // Directed mode on or off
+	Layer    int               // Drawing layer of a vertex
+	Offset   *point            // Label position from the vertex, nil to place it automatically
+	Order    []int             // Old vertex indices in their new order, for reorder
+}
+
+type Journal struct {
+	Actions []Action
+}
+
+// Appends an action to the journal.
+func (j *Journal) Record(a Action) {
+	j.Actions = append(j.Actions, a)
+}
+
+// Applies an action to the graph.
+// Bad vertex references are reported as errors, the graph is left untouched.
+func (g *Graph) Apply(a Action) error {
+	switch a.Kind {
+	case ActionAddVertex:
+		g.AddVertex(a.X, a.Y, a.Label, a.Color, a.Radius)
+	case ActionAddEdge:
+		return g.AddEdge(a.V1, a.V2)
+	case ActionDeleteVertex:
+		return g.DeleteVertex(a.V1)
+	case ActionDeleteEdge:
+		return g.DeleteEdge(a.V1, a.V2)
+	case ActionClear:
+		g.Clear()
+	case ActionStyleEdge:
+		return g.SetEdgeStyle(a.V1, a.V2, EdgeStyle{Color: a.Color, Width: a.Width, Curved: a.Curved})
+	case ActionTimeEdge:
+		return g.SetEdgeTime(a.V1, a.V2, a.Time)
+	case ActionAttrEdge:
+		if err := g.SetEdgeAttrs(a.V1, a.V2, a.Attrs); err != nil {
+			return err
+		}
+		g.Schema.infer(a.Attrs, true)
+	case ActionDefineAttr:
+		g.Schema.Define(*a.Column)
+	case ActionSetDirected:
+		g.SetDirected(a.Directed)
+	case ActionWeightEdge:
+		return g.SetEdgeWeight(a.V1, a.V2, a.Weight)
+	case ActionReorderVertices:
+		return g.Reorder(a.Order)
+	case ActionMoveVertex, ActionColorVertex, ActionNameVertex, ActionWeightVertex, ActionTimeVertex, ActionAttrVertex, ActionLayerVertex, ActionPlaceLabel:
+		if err := g.CheckVertices(a.V1); err != nil {
+			return err
+		}
+		v := &g.Vertices[a.V1]
+		switch a.Kind {
+		case ActionMoveVertex:
+			v.X, v.Y = a.X, a.Y
+		case ActionColorVertex:
+			v.Color = a.Color
+		case ActionNameVertex:
+			v.Label = a.Label
+		case ActionWeightVertex:
+			v.Weight = a.Weight
+		case ActionTimeVertex:
+			v.Active = a.Time
+		case ActionAttrVertex:
+			v.Attrs = a.Attrs
+			g.Schema.infer(a.Attrs, false)
+		case ActionLayerVertex:
+			v.Layer = a.Layer
+		case ActionPlaceLabel:
+			v.LabelOffset = a.Offset
+		}
+	}
+	return nil
+}
+
+// Applies an action to the app's graph and records it in the journal.
+// Failures are shown as on-screen warnings instead of being dropped.
+func (app *App) Do(a Action) bool {
+	description := app.describe(a) // Before applying, deleted vertices still have their labels
+	name := description
+	if name == "" {
+		name = T("undo.edit")
+	}
+	if !app.checkConstraints(a) {
+		return false
+	}
+	app.BeginTransaction(name) // A step of its own, unless in a bigger transaction (history.go)
+	defer app.Commit()
+	if err := app.Graph.Apply(a); err != nil {
+		switch {
+		case errors.Is(err, ErrNoVertex):
+			app.Warn(T("warn.no_vertex"))
+		case errors.Is(err, ErrNoEdge):
+			app.Warn(T("warn.no_edge"))
+		default:
+			app.Warn(err.Error())
+		}
+		return false
+	}
+	app.Journal.Record(a)
+	app.revision++
+	app.Announce(description)
+	switch a.Kind {
+	case ActionDeleteVertex:
+		app.forgetVertex(a.V1)
+		app.StopAnimation() // Its steps refer to vertices by index
+	case ActionDeleteEdge:
+		if app.Graph.Multiplicity(a.V1, a.V2) == 0 {
+			delete(app.Selection.Edges, Edge(a.V1, a.V2))
+		}
+	case ActionReorderVertices:
+		app.renumberVertices(a.Order)
+		app.StopAnimation()
+	case ActionClear:
+		app.Selected, app.EdgeStart, app.MovingVertex, app.Focused, app.PenLast = nil, nil, nil, nil, nil
+		app.Selection.Clear()
+		app.StopAnimation()
+	}
+	return true
+}
+
+// Fixes up vertex indices held by tools after the vertices are renumbered (order lists the old
+// indices in their new order).
+func (app *App) renumberVertices(order []int) {
+	position := make([]int, len(order))
+	for k, old := range order {
+		position[old] = k
+	}
+	app.Selection.renumberVertices(position)
+	for _, ref := range []**int{&app.Selected, &app.EdgeStart, &app.MovingVertex, &app.MovingLabel, &app.Focused, &app.PenLast} {
+		if *ref != nil {
+			i := position[**ref]
+			*ref = &i
+		}
+	}
+}
+
+// Fixes up vertex indices held by tools after a vertex is deleted.
+// References to the deleted vertex are dropped, later ones shift down by one.
+func (app *App) forgetVertex(index int) {
+	app.Selection.forgetVertex(index)
+	for _, ref := range []**int{&app.Selected, &app.EdgeStart, &app.MovingVertex, &app.MovingLabel, &app.Focused, &app.PenLast} {
+		if *ref == nil {
+			continue
+		}
+		switch i := **ref; {
+		case i == index:
+			*ref = nil
+		case i > index:
+			i--
+			*ref = &i
+		}
+	}
+}
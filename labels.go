@@ -0,0 +1,270 @@
+package main
+
+import (
+	"math"
+)
+
+// Label placement:
+//
+//	Shift+N: next label placement (inside the vertex, north, south, east, west, automatic).
+//
+// Labels are written over their vertex by default. Placed north, south, east or west they go just
+// outside it on that side. Automatic placement tries eight spots around every vertex (the four sides,
+// then the corners) and takes the one covering the fewest other vertices, labels and edges; vertices
+// are placed in drawing order, each avoiding the labels placed before it. Dragging a label with the
+// Move Vertex tool pins it where it's dropped, relative to its vertex, whatever the placement; dropping
+// it back on its vertex unpins it. Pinned positions are kept in saved sessions and used by exports.
+
+type LabelPlacement int
+
+const (
+	LabelInside LabelPlacement = iota
+	LabelNorth
+	LabelSouth
+	LabelEast
+	LabelWest
+	LabelAuto
+
+	// Corners, only tried by automatic placement
+	labelNorthEast
+	labelSouthEast
+	labelSouthWest
+	labelNorthWest
+)
+
+// Locale keys of the placements Shift+N cycles through.
+var labelPlacementNames = []string{"labels.inside", "labels.north", "labels.south", "labels.east", "labels.west", "labels.auto"}
+
+const (
+	labelHeight = 12 // Of a line of text
+	labelGap    = 3  // Between a vertex and a label placed outside it
+)
+
+type labelRect struct {
+	X, Y, W, H float64 // Top left corner and size, in world coordinates
+}
+
+// Reports whether two rectangles overlap.
+func (r labelRect) overlaps(o labelRect) bool {
+	return r.X < o.X+o.W && o.X < r.X+r.W && r.Y < o.Y+o.H && o.Y < r.Y+r.H
+}
+
+// Reports whether a disc overlaps the rectangle.
+func (r labelRect) touchesDisc(x, y, radius float64) bool {
+	cx, cy := math.Max(r.X, math.Min(x, r.X+r.W)), math.Max(r.Y, math.Min(y, r.Y+r.H))
+	return math.Hypot(x-cx, y-cy) < radius
+}
+
+// Reports whether the segment from a to b crosses the rectangle (Liang–Barsky clipping).
+func (r labelRect) crossesSegment(a, b point) bool {
+	t0, t1 := 0.0, 1.0
+	dx, dy := b.X-a.X, b.Y-a.Y
+	for _, edge := range [4][2]float64{{-dx, a.X - r.X}, {dx, r.X + r.W - a.X}, {-dy, a.Y - r.Y}, {dy, r.Y + r.H - a.Y}} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+		if t0 > t1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns where the label of vertex i goes with a placement, ignoring a pinned position.
+func (app *App) labelRectAt(i int, placement LabelPlacement) labelRect {
+	v := app.Graph.Vertices[i]
+	w, h := float64(textWidth(v.Label)), float64(labelHeight)
+	r := app.vertexRadius(i) + labelGap
+	d := r / math.Sqrt2 // Corners are on the diagonals
+	switch placement {
+	case LabelNorth:
+		return labelRect{v.X - w/2, v.Y - r - h, w, h}
+	case LabelSouth:
+		return labelRect{v.X - w/2, v.Y + r, w, h}
+	case LabelEast:
+		return labelRect{v.X + r, v.Y - h/2, w, h}
+	case LabelWest:
+		return labelRect{v.X - r - w, v.Y - h/2, w, h}
+	case labelNorthEast:
+		return labelRect{v.X + d, v.Y - d - h, w, h}
+	case labelSouthEast:
+		return labelRect{v.X + d, v.Y + d, w, h}
+	case labelSouthWest:
+		return labelRect{v.X - d - w, v.Y + d, w, h}
+	case labelNorthWest:
+		return labelRect{v.X - d - w, v.Y - d - h, w, h}
+	}
+	return labelRect{v.X - 10, v.Y - 5, w, h}
+}
+
+// Spots automatic placement tries, best first.
+var autoLabelSpots = []LabelPlacement{LabelEast, LabelNorth, LabelWest, LabelSouth, labelNorthEast, labelSouthEast, labelSouthWest, labelNorthWest}
+
+// Where the labels are with automatic placement, for the positions they were computed at.
+type labelCache struct {
+	key   spatialKey
+	valid bool
+	rects []labelRect
+}
+
+// Returns where the label of vertex i is drawn.
+func (app *App) labelRect(i int) labelRect {
+	v := app.Graph.Vertices[i]
+	if v.LabelOffset != nil {
+		return labelRect{v.X + v.LabelOffset.X, v.Y + v.LabelOffset.Y, float64(textWidth(v.Label)), labelHeight}
+	}
+	if app.View.LabelPlacement == LabelAuto {
+		return app.autoLabels()[i]
+	}
+	return app.labelRectAt(i, app.View.LabelPlacement)
+}
+
+// Returns the automatically placed labels, placing them again if the graph or the layout changed.
+func (app *App) autoLabels() []labelRect {
+	s := app.spatialIndex()
+	c := &app.labels
+	if c.valid && c.key == s.key && len(c.rects) == len(app.Graph.Vertices) {
+		return c.rects
+	}
+	*c = labelCache{key: s.key, valid: true, rects: make([]labelRect, len(app.Graph.Vertices))}
+	g := app.Graph
+	placed := map[cellKey][]int{} // Labels placed so far, by the cells they cover
+	place := func(i int, r labelRect) {
+		c.rects[i] = r
+		lo, hi := cellAt(r.X, r.Y), cellAt(r.X+r.W, r.Y+r.H)
+		for cx := lo.X; cx <= hi.X; cx++ {
+			for cy := lo.Y; cy <= hi.Y; cy++ {
+				placed[cellKey{cx, cy}] = append(placed[cellKey{cx, cy}], i)
+			}
+		}
+	}
+	// Pinned labels stay where they are, the others avoid them
+	for i, v := range g.Vertices {
+		if v.LabelOffset != nil {
+			place(i, app.labelRect(i))
+		}
+	}
+	for _, i := range app.drawOrder() {
+		if g.Vertices[i].LabelOffset != nil || !app.vertexShown(i) {
+			continue
+		}
+		best, bestCost := labelRect{}, math.Inf(1)
+		for _, spot := range autoLabelSpots {
+			r := app.labelRectAt(i, spot)
+			if cost := app.labelCost(s, r, i, placed, c.rects); cost < bestCost {
+				best, bestCost = r, cost
+			}
+			if bestCost == 0 {
+				break
+			}
+		}
+		place(i, best)
+	}
+	return c.rects
+}
+
+// Counts what a label of vertex i at r would cover: other vertices and labels weigh more than edges.
+func (app *App) labelCost(s *spatialIndex, r labelRect, i int, placed map[cellKey][]int, rects []labelRect) float64 {
+	cost := 0.0
+	cx, cy := r.X+r.W/2, r.Y+r.H/2
+	reach := math.Hypot(r.W, r.H) / 2
+	seen := map[int]bool{}
+	cellsAround(cx, cy, reach+s.maxRadius, func(c cellKey) {
+		for _, j := range s.vertices[c] {
+			v := app.Graph.Vertices[j]
+			if j != i && app.vertexShown(j) && r.touchesDisc(v.X, v.Y, app.vertexRadius(j)) {
+				cost += 3
+			}
+		}
+		for _, j := range placed[c] {
+			if !seen[j] && rects[j].overlaps(r) {
+				seen[j] = true
+				cost += 3
+			}
+		}
+	})
+	crossed := map[[2]int]bool{}
+	cellsAround(cx, cy, reach, func(c cellKey) {
+		for _, n := range s.segments[c] {
+			e := s.segs[n]
+			if ends := [2]int{e.I, e.J}; !crossed[ends] && app.edgeShown(e.I, e.J) && r.crossesSegment(e.A, e.B) {
+				crossed[ends] = true
+				cost++
+			}
+		}
+	})
+	return cost
+}
+
+// Switches to the next label placement.
+func (app *App) CycleLabelPlacement() {
+	app.View.LabelPlacement = (app.View.LabelPlacement + 1) % LabelPlacement(len(labelPlacementNames))
+	msg := T("labels.placement", T(labelPlacementNames[app.View.LabelPlacement]))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Returns the vertex whose label is at (x, y), the one drawn on top if several are, -1 if none.
+func (app *App) LabelAt(x, y float64) int {
+	best := -1
+	for i, v := range app.Graph.Vertices {
+		if v.Label == "" || !app.vertexShown(i) || (best >= 0 && !app.drawnAbove(i, best)) {
+			continue
+		}
+		if r := app.labelRect(i); x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H {
+			best = i
+		}
+	}
+	return best
+}
+
+// Starts dragging the label of vertex i, held at (x, y).
+func (app *App) grabLabel(i int, x, y float64) {
+	r := app.labelRect(i)
+	app.beginDrag()
+	app.MovingLabel = &i
+	app.labelGrab = point{x - r.X, y - r.Y}
+}
+
+// Drags the label being moved to (x, y). It's only pinned once it moves, not by a click.
+func (app *App) dragLabelTo(x, y float64) {
+	i := *app.MovingLabel
+	if app.Graph.CheckVertices(i) != nil {
+		return
+	}
+	r, corner := app.labelRect(i), point{x - app.labelGrab.X, y - app.labelGrab.Y}
+	if math.Hypot(corner.X-r.X, corner.Y-r.Y) < 0.5 {
+		return
+	}
+	v := &app.Graph.Vertices[i]
+	v.LabelOffset = &point{corner.X - v.X, corner.Y - v.Y}
+}
+
+// Drops the label being moved: pinned where it is, or unpinned if it's back on its vertex.
+func (app *App) dropLabel() {
+	i := *app.MovingLabel
+	app.MovingLabel = nil
+	if app.Graph.CheckVertices(i) != nil {
+		return
+	}
+	v := app.Graph.Vertices[i]
+	if v.LabelOffset == nil {
+		return
+	}
+	r := app.labelRect(i)
+	a := Action{Kind: ActionPlaceLabel, V1: i, Offset: v.LabelOffset}
+	if math.Hypot(r.X+r.W/2-v.X, r.Y+r.H/2-v.Y) < app.vertexRadius(i) {
+		a.Offset = nil
+	}
+	app.Do(a)
+}
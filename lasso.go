@@ -0,0 +1,105 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Marquee and lasso selection:
+
+// Dragging over empty space with the Select tool selects every vertex inside the dragged region.
+// In marquee mode the region is the rectangle between press and release,
+// in lasso mode it's the free-form outline traced by the cursor (closed back to the start).
+// Shift adds to the selection instead of replacing it.
+
+const dragSelectMinDistance = 4 // Cursor travel before a press counts as a drag
+
+type point struct {
+	X, Y float64
+}
+
+type selectDrag struct {
+	Points []point
+	Lasso  bool
+	Toggle bool // Add to the selection instead of replacing it
+}
+
+// Starts dragging out a selection region.
+func (app *App) startSelectDrag(x, y float64, toggle bool) {
+	app.SelectDrag = &selectDrag{Points: []point{{x, y}}, Lasso: app.LassoSelect, Toggle: toggle}
+}
+
+// Extends the region being dragged to the cursor. Called every frame while the button is held.
+func (app *App) updateSelectDrag(x, y float64) {
+	d := app.SelectDrag
+	last := d.Points[len(d.Points)-1]
+	if math.Hypot(x-last.X, y-last.Y) >= 2 { // Skip jitter, keeps the outline short
+		d.Points = append(d.Points, point{x, y})
+	}
+}
+
+// Finishes the drag and selects the vertices inside the region.
+func (app *App) finishSelectDrag() {
+	d := app.SelectDrag
+	app.SelectDrag = nil
+
+	first, last := d.Points[0], d.Points[len(d.Points)-1]
+	if len(d.Points) < 2 || (!d.Lasso && math.Hypot(last.X-first.X, last.Y-first.Y) < dragSelectMinDistance) {
+		return // Just a click on empty space
+	}
+	if !d.Toggle {
+		app.Selection.Clear()
+	}
+	for i, v := range app.Graph.Vertices {
+		if d.contains(v.X, v.Y) {
+			app.Selection.AddVertex(i, false)
+		}
+	}
+	app.Announce(T("announce.selected", app.selectionSummary()))
+}
+
+// Reports whether a point is inside the dragged region.
+func (d *selectDrag) contains(x, y float64) bool {
+	if !d.Lasso {
+		first, last := d.Points[0], d.Points[len(d.Points)-1]
+		return x >= min(first.X, last.X) && x <= max(first.X, last.X) &&
+			y >= min(first.Y, last.Y) && y <= max(first.Y, last.Y)
+	}
+
+	// Even-odd rule: count outline crossings of a ray going right
+	inside := false
+	for i, a := range d.Points {
+		b := d.Points[(i+1)%len(d.Points)]
+		if (a.Y > y) != (b.Y > y) && x < a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Draws the region being dragged.
+func (app *App) drawSelectDrag(screen *ebiten.Image) {
+	d := app.SelectDrag
+	if d == nil {
+		return
+	}
+	fill := color.RGBA{0, 50, 64, 64}
+	points := make([]point, len(d.Points)) // The drag is in world coordinates
+	for i, p := range d.Points {
+		points[i].X, points[i].Y = app.toScreen(p.X, p.Y)
+	}
+	if !d.Lasso {
+		first, last := points[0], points[len(points)-1]
+		x, y := float32(min(first.X, last.X)), float32(min(first.Y, last.Y))
+		w, h := float32(math.Abs(last.X-first.X)), float32(math.Abs(last.Y-first.Y))
+		fillRect(screen, x, y, w, h, fill, true)
+		strokeRect(screen, x, y, w, h, 1, selectionColor, true)
+		return
+	}
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		strokeLine(screen, float32(a.X), float32(a.Y), float32(b.X), float32(b.Y), 1, selectionColor, true)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math"
+)
+
+// Force-directed layout:
+
+// A light spring embedder (Fruchterman-Reingold style): vertices push each other away,
+// edges pull their ends together, with a weak pull towards the middle so nothing drifts off.
+// With auto layout on (L), one small step runs every update while editing, scaled by the time since the last one,
+// so new vertices settle into reasonable positions by themselves. Its moves make the drawing unsaved,
+// but they aren't edits: results that depend only on the edges stay valid, and they aren't undo steps
+// of their own (Ctrl+Z goes back to before the last edit, the moves since included).
+//
+// Shift+L lays the whole graph out at once: big steps at first, cooling down to nothing over a couple
+// of seconds, so the vertices visibly slide into place. It's one undo step; Shift+L again stops it early.
+
+const (
+	layoutSpacing = 80 // Preferred edge length
+	layoutMaxMove = 2  // Pixels a vertex may move per step at full intensity
+	layoutGravity = 0.01
+
+	layoutSettled = 0.05 // Largest move, in pixels, of an auto layout step that leaves the drawing saved
+
+	layoutRunFrames    = 120 // Reference frames (1/60 s) a Shift+L layout runs
+	layoutRunIntensity = 10  // Intensity it starts at, cooling linearly to 0
+)
+
+// A Shift+L layout in progress.
+type LayoutRun struct {
+	left float64 // Reference frames to go
+}
+
+// Moves every vertex one step along the forces, by at most intensity·layoutMaxMove pixels.
+// Pinned vertices (e.g. the one being dragged) push and pull but don't move.
+// Positions stay inside the given rectangle. Returns the largest distance a vertex moved.
+func (g *Graph) LayoutStep(intensity float64, pinned map[int]bool, minX, minY, maxX, maxY float64) float64 {
+	n := len(g.Vertices)
+	if n == 0 {
+		return 0
+	}
+	dx, dy := make([]float64, n), make([]float64, n)
+	k := float64(layoutSpacing)
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+
+	for i, a := range g.Vertices {
+		for j := i + 1; j < n; j++ {
+			b := g.Vertices[j]
+			ox, oy := a.X-b.X, a.Y-b.Y
+			d := math.Hypot(ox, oy)
+			if d < 0.01 { // Same spot, nudge apart in some direction
+				ox, oy, d = float64(i-j), 1, math.Hypot(float64(i-j), 1)
+			}
+			force := k * k / d // Repulsion
+			if count := g.AdjMatrix[i][j]; count > 0 {
+				force -= d * d / k // Attraction (parallel edges count once, they'd squash the pair)
+			}
+			fx, fy := ox/d*force, oy/d*force
+			dx[i], dy[i] = dx[i]+fx, dy[i]+fy
+			dx[j], dy[j] = dx[j]-fx, dy[j]-fy
+		}
+		dx[i] += (cx - a.X) * layoutGravity * k
+		dy[i] += (cy - a.Y) * layoutGravity * k
+	}
+
+	limit := intensity * layoutMaxMove
+	moved := 0.0
+	for i := range g.Vertices {
+		if pinned[i] {
+			continue
+		}
+		d := math.Hypot(dx[i], dy[i])
+		if d < 1e-9 {
+			continue
+		}
+		step := math.Min(d, limit)
+		v := &g.Vertices[i]
+		x := math.Max(minX+v.Radius, math.Min(maxX-v.Radius, v.X+dx[i]/d*step))
+		y := math.Max(minY+v.Radius, math.Min(maxY-v.Radius, v.Y+dy[i]/d*step))
+		moved = math.Max(moved, math.Hypot(x-v.X, y-v.Y))
+		v.X, v.Y = x, y
+	}
+	return moved
+}
+
+// Runs one auto layout step, if turned on. Called every update. A step that visibly moved something
+// makes the drawing unsaved; it isn't an edit, so the revision stays.
+func (app *App) UpdateAutoLayout() {
+	if !app.View.AutoLayout {
+		return
+	}
+	pinned := map[int]bool{}
+	if app.MovingVertex != nil {
+		pinned[*app.MovingVertex] = true
+	}
+	if app.Graph.LayoutStep(app.Settings.AutoLayoutIntensity*app.frames(), pinned, 0, canvasTop(), screenWidth, screenHeight) > layoutSettled {
+		app.moved = true
+	}
+}
+
+// Starts laying out the whole graph, or stops the layout running.
+func (app *App) ToggleLayoutRun() {
+	if app.LayoutRun != nil {
+		app.finishLayoutRun()
+		return
+	}
+	if len(app.Graph.Vertices) == 0 {
+		return
+	}
+	app.BeginTransaction(T("undo.layout"))
+	app.LayoutRun = &LayoutRun{left: layoutRunFrames}
+}
+
+// Runs a step of the Shift+L layout, if one is going. Called every update.
+func (app *App) UpdateLayoutRun() {
+	run := app.LayoutRun
+	if run == nil {
+		return
+	}
+	frames := min(app.frames(), run.left)
+	intensity := layoutRunIntensity * run.left / layoutRunFrames * frames
+	run.left -= frames
+	app.Graph.LayoutStep(intensity, nil, 0, canvasTop(), screenWidth, screenHeight)
+	if run.left <= 0 {
+		app.finishLayoutRun()
+	}
+}
+
+// Records where the layout left every vertex, closing its undo step.
+func (app *App) finishLayoutRun() {
+	app.LayoutRun = nil
+	for i, v := range app.Graph.Vertices {
+		app.Do(Action{Kind: ActionMoveVertex, V1: i, X: v.X, Y: v.Y})
+	}
+	app.Commit()
+}
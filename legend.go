@@ -0,0 +1,170 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Title, caption and legend:
+
+// A drawing can have a title (top left of the canvas) and a caption (bottom center),
+// typed in with T and Shift+T. The legend explains the active color and size mappings
+// and is generated from them. All three are drawn on the canvas and included in exports (export.go).
+
+const maxLegendEntries = 8
+
+// Explanation of one mapping.
+type Legend struct {
+	Title   string
+	Entries []LegendEntry // Categories, or the two ends of a range
+	Range   bool          // Entries are the low and high end, a gradient (or size ramp) goes between them
+}
+
+type LegendEntry struct {
+	Label  string
+	Color  color.RGBA // Zero for no swatch
+	Radius float64    // Circle of this size instead of a swatch, for size mappings
+}
+
+// Returns the legends of the active mappings.
+func (app *App) legends() []Legend {
+	if len(app.Graph.Vertices) == 0 {
+		return nil
+	}
+	var legends []Legend
+	ms := app.vertexMetrics()
+	if app.ColorMap != nil {
+		m := ms[app.ColorMap.Metric]
+		values := app.metricValues(app.ColorMap.Metric)
+		l := Legend{Title: T(m.Name)}
+		if app.ColorMap.Categorical {
+			distinct := distinctValues(values)
+			for n, v := range distinct[:min(len(distinct), maxLegendEntries)] {
+				l.Entries = append(l.Entries, LegendEntry{Label: formatMetric(v), Color: app.PaletteColor(n)})
+			}
+			if len(distinct) > maxLegendEntries {
+				l.Entries = append(l.Entries, LegendEntry{Label: T("colormap.more", len(distinct)-maxLegendEntries)})
+			}
+		} else {
+			lo, hi := valueRange(values)
+			l.Range = true
+			l.Entries = []LegendEntry{
+				{Label: formatMetric(lo), Color: gradientColor(0)},
+				{Label: formatMetric(hi), Color: gradientColor(1)},
+			}
+		}
+		legends = append(legends, l)
+	}
+	if app.SizeMap != nil {
+		m := ms[app.SizeMap.Metric]
+		lo, hi := valueRange(app.metricValues(app.SizeMap.Metric))
+		legends = append(legends, Legend{Title: T(m.Name), Range: true, Entries: []LegendEntry{
+			{Label: formatMetric(lo), Radius: app.Settings.SizeMinRadius},
+			{Label: formatMetric(hi), Radius: app.Settings.SizeMaxRadius},
+		}})
+	}
+	return legends
+}
+
+// Returns the smallest and largest value.
+func valueRange(values []float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// Size of a legend box.
+func (l Legend) size() (w, h float64) {
+	w = float64(max(textWidth(l.Title), 100) + 30)
+	if l.Range {
+		h = 56
+		for _, e := range l.Entries {
+			h = max(h, 2*e.Radius+40)
+		}
+		return w, h
+	}
+	for _, e := range l.Entries {
+		w = max(w, float64(textWidth(e.Label)+40))
+	}
+	return w, float64(16*len(l.Entries) + 26)
+}
+
+// Positions of the legend boxes, stacked up from the bottom right of a w×h picture.
+func legendLayout(legends []Legend, w, h float64) (xs, ys []float64) {
+	y := h - 40 // Room for the caption
+	for _, l := range legends {
+		lw, lh := l.size()
+		y -= lh + 10
+		xs, ys = append(xs, w-lw-10), append(ys, y)
+	}
+	return xs, ys
+}
+
+// Draws the legends at the bottom right of the canvas.
+func (app *App) DrawLegend(screen *ebiten.Image) {
+	legends := app.legends()
+	xs, ys := legendLayout(legends, screenWidth, screenHeight)
+	for n, l := range legends {
+		w, h := l.size()
+		x, y := float32(xs[n]), float32(ys[n])
+		fillRect(screen, x, y, float32(w), float32(h), color.RGBA{30, 30, 30, 230}, true)
+		strokeRect(screen, x, y, float32(w), float32(h), 1, color.RGBA{200, 200, 200, 255}, true)
+		printAt(screen, l.Title, int(x)+5, int(y)+3)
+
+		if !l.Range {
+			for k, e := range l.Entries {
+				ey := y + 22 + float32(16*k)
+				if e.Color.A > 0 {
+					fillRect(screen, x+8, ey+2, 12, 12, e.Color, true)
+				}
+				printAt(screen, e.Label, int(x)+26, int(ey))
+			}
+			continue
+		}
+
+		lo, hi := l.Entries[0], l.Entries[1]
+		if lo.Radius > 0 { // Size ramp: smallest and largest circle
+			r1, r2 := float32(lo.Radius), float32(hi.Radius)
+			cy := y + 20 + r2
+			strokeCircle(screen, x+8+r1, cy, r1, 1, color.White, true)
+			strokeCircle(screen, x+float32(w)-8-r2, cy, r2, 1, color.White, true)
+		} else { // Gradient bar
+			barW := float32(w) - 16
+			for px := float32(0); px < barW; px++ {
+				fillRect(screen, x+8+px, y+22, 1, 12, gradientColor(float64(px/barW)), false)
+			}
+		}
+		printAt(screen, lo.Label, int(x)+8, int(y+float32(h))-20)
+		printAt(screen, hi.Label, int(x+float32(w))-8-textWidth(hi.Label), int(y+float32(h))-20)
+	}
+}
+
+// Draws the title and caption.
+func (app *App) DrawTitle(screen *ebiten.Image) {
+	if app.Title != "" {
+		printAt(screen, app.Title, 10, int(canvasTop())+6)
+	}
+	if app.Caption != "" {
+		printAt(screen, app.Caption, (screenWidth-textWidth(app.Caption))/2, screenHeight-24)
+	}
+}
+
+// Opens a text field to type the title (or the caption when caption is set).
+func (app *App) EditTitle(caption bool) {
+	target, y := &app.Title, canvasTop()+4
+	if caption {
+		target, y = &app.Caption, screenHeight-26
+	}
+	app.OpenTextInput(&TextInput{
+		Text: *target,
+		X:    10,
+		Y:    y,
+		OnCommit: func(text string) {
+			*target = text
+		},
+	})
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Macros:
+
+// A macro is a piece of the action journal recorded between two presses of the record key.
+// Vertex indices are stored relative to the vertex count when recording started,
+// so negative indices point at vertices that existed before the macro (-1 is the newest one).
+// Positions are stored relative to the cursor position when recording started.
+// The cursor offset between starting and stopping a recording is the macro's stride,
+// each repetition of a replay is shifted by one more stride.
+//
+// Example: start recording, add a vertex, connect it to the previous newest vertex,
+// move the cursor a bit to the right and stop. Replaying n times draws a path of length n.
+//
+// The vertices selected when recording starts are the macro's parameters: every repetition uses
+// the vertices selected when replaying instead (the first selected for the first one, and so on),
+// the same ones each time. Shift+F10 replays the n repetitions around the cursor instead of along the
+// stride, each turned by another n-th of a full turn, and the first one links back to the last.
+//
+// Example, a wheel of size n: draw a hub, then a vertex next to it, select the hub, start recording
+// with the cursor on the hub, add a vertex on the rim, connect it to the hub and to the other
+// vertex, and stop. Select any vertex as the new hub, put the cursor on it, Shift+F10 and type n.
+
+type Macro struct {
+	Actions          []Action
+	StrideX, StrideY float64
+	Args             []int // Parameters, as relative indices of the vertices selected when recording started
+	Added            int   // Vertices one repetition adds
+}
+
+type MacroRecorder struct {
+	Recording        bool
+	start            int     // Journal index where recording started
+	base             int     // Vertex count when recording started
+	args             []int   // Vertices selected when recording started
+	anchorX, anchorY float64 // Cursor position when recording started
+}
+
+// Starts recording a macro at the given cursor position.
+func (app *App) StartMacro(x, y float64) {
+	app.Recorder = MacroRecorder{
+		Recording: true,
+		start:     len(app.Journal.Actions),
+		base:      len(app.Graph.Vertices),
+		args:      app.Selection.sortedVertices(),
+		anchorX:   x,
+		anchorY:   y,
+	}
+}
+
+// Stops recording and stores the recorded actions as the current macro.
+func (app *App) StopMacro(x, y float64) {
+	r := &app.Recorder
+	r.Recording = false
+
+	macro := &Macro{StrideX: x - r.anchorX, StrideY: y - r.anchorY, Added: len(app.Graph.Vertices) - r.base}
+	for _, i := range r.args {
+		macro.Args = append(macro.Args, i-r.base)
+	}
+	for _, a := range app.Journal.Actions[r.start:] {
+		a.V1 -= r.base
+		a.V2 -= r.base
+		a.X -= r.anchorX
+		a.Y -= r.anchorY
+		macro.Actions = append(macro.Actions, a)
+	}
+	app.Macro = macro
+}
+
+// Asks how many times to replay the current macro, then replays it at the given cursor position,
+// along the stride or around it.
+func (app *App) AskReplayMacro(x, y float64, around bool) {
+	app.askPredicate(T("prompt.macro_repeat"), func(text string) {
+		n, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || n < 1 {
+			app.Warn(T("warn.not_a_number", text))
+			return
+		}
+		app.ReplayMacro(n, x, y, around)
+	})
+}
+
+// Reports whether an action refers to vertices by V1, and by V2.
+func (a Action) vertexRefs() (v1, v2 bool) {
+	switch a.Kind {
+	case ActionAddVertex, ActionClear, ActionDefineAttr, ActionSetDirected, ActionReorderVertices:
+		return false, false
+	case ActionAddEdge, ActionDeleteEdge, ActionStyleEdge, ActionTimeEdge, ActionAttrEdge, ActionWeightEdge:
+		return true, true
+	}
+	return true, false
+}
+
+// Turns a vertex index stored in the macro into one of the graph: a parameter into the matching
+// vertex of args, a vertex of the repetition into one counted from base, and a vertex from before it
+// into one counted back from before.
+func (m *Macro) resolve(i, base, before int, args []int) int {
+	switch p := slices.Index(m.Args, i); {
+	case p >= 0:
+		return args[p]
+	case i < 0:
+		return before + i
+	}
+	return base + i
+}
+
+// Replays the current macro n times, starting at the given cursor position, or around it.
+// The vertices selected are its parameters.
+func (app *App) ReplayMacro(n int, x, y float64, around bool) {
+	m := app.Macro
+	if m == nil || app.Recorder.Recording {
+		return
+	}
+	args := app.Selection.sortedVertices()
+	if len(args) < len(m.Args) {
+		app.Warn(T("warn.macro_args", len(m.Args)))
+		return
+	}
+	app.BeginTransaction(T("undo.macro"))
+	defer app.Commit()
+	first := len(app.Graph.Vertices)
+	var wrapped []Action // Actions of the first repetition around that refer to the last repetition
+	for k := 0; k < n; k++ {
+		base := len(app.Graph.Vertices)
+		for _, a := range m.Actions {
+			ref1, ref2 := a.vertexRefs()
+			chained := func(i int) bool { return i < 0 && !slices.Contains(m.Args, i) }
+			wrap := around && k == 0 && (ref1 && chained(a.V1) || ref2 && chained(a.V2))
+			before := base
+			if wrap {
+				before = first + n*m.Added // Where the last repetition ends
+			}
+			a.V1 = m.resolve(a.V1, base, before, args)
+			a.V2 = m.resolve(a.V2, base, before, args)
+			if around {
+				sin, cos := math.Sincos(2 * math.Pi * float64(k) / float64(n))
+				a.X, a.Y = x+a.X*cos-a.Y*sin, y+a.X*sin+a.Y*cos
+			} else {
+				a.X += x + float64(k)*m.StrideX
+				a.Y += y + float64(k)*m.StrideY
+			}
+			if a.Kind == ActionAddVertex {
+				a.Label = fmt.Sprintf("V%d", len(app.Graph.Vertices)+1)
+			}
+			if wrap {
+				wrapped = append(wrapped, a)
+				continue
+			}
+			app.Do(a)
+		}
+	}
+	for _, a := range wrapped {
+		app.Do(a)
+	}
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Logical screen size.
+const (
+	screenWidth  = 800
+	screenHeight = 600
 )
 
 // Tool types enum and label strings:
@@ -20,289 +26,663 @@ type Tool int
 const (
 	ToolAddVertex Tool = iota // used for auto-increment
 	ToolAddEdge
+	ToolPen
 	ToolDeleteVertex
 	ToolDeleteEdge
+	ToolDelete
 	ToolMoveVertex
 	ToolColorVertex
 	ToolNameVertex
 	ToolPrintInfo
+	ToolSelect
+	ToolEdgeWeight
+	ToolTraverse
+	ToolShortestPath
+	ToolSave
+	ToolOpen
 )
 
+// Locale keys, see i18n.go.
 var toolNames = []string{
-	"Add Vertex",
-	"Add Edge",
-	"Delete Vertex",
-	"Delete Edge",
-	"Move Vertex",
-	"Color Vertex",
-	"Name Vertex",
-	"Print Info",
+	"tool.add_vertex",
+	"tool.add_edge",
+	"tool.pen",
+	"tool.delete_vertex",
+	"tool.delete_edge",
+	"tool.delete",
+	"tool.move_vertex",
+	"tool.color_vertex",
+	"tool.name_vertex",
+	"tool.print_info",
+	"tool.select",
+	"tool.edge_weight",
+	"tool.traverse",
+	"tool.shortest_path",
+	"tool.save",
+	"tool.open",
 }
 
-// Vertex and graph info:
-
-// Edges stored via adjacency matrix.
-// Vertex drawing info in it's own struct.
-// Vertices are tracked by index, in adjacency matrix and vertex slice.
+// App struct to hold application info
 
-type Vertex struct {
-	X, Y  float64
-	Label string
-	Color color.RGBA
+type App struct {
+	Graph         *Graph    // Graph
+	Selected      *int      // Selected vertex (index)
+	Tool          Tool      // Selected tool
+	EdgeStart     *int      // Start vertex for adding an edge (or finding a path)
+	PenLast       *int      // Last vertex of the chain drawn with the pen
+	MovingVertex  *int      // Index of the vertex being moved
+	MovingLabel   *int      // Index of the vertex whose label is being dragged (labels.go)
+	LastClickTime time.Time // For vertex adding delay
+	lastClickX    float64   // Where the last click happened (double click detection)
+	lastClickY    float64
+
+	Journal             Journal       // Every edit made so far
+	Recorder            MacroRecorder // Macro recording state
+	Macro               *Macro        // Last recorded macro
+	Warnings            []Warning     // Non-fatal problems shown on screen
+	Settings            Settings      // User preferences
+	Dialog              *Dialog       // Open modal dialog (nil if none)
+	HitMenu             *HitMenu      // Open menu of what a click could mean (nil if none, see disambiguate.go)
+	Focused             *int          // Vertex focused for keyboard operation
+	TextInput           *TextInput    // Open inline text field (nil if none)
+	Hover               *Hover        // What the cursor rests on (for tooltips)
+	View                ViewOptions   // Display toggles
+	DeleteFilter        DeleteFilter  // What the Delete tool removes
+	Selection           Selection     // Vertices and edges picked with the Select tool
+	Copied              *CopiedGraph  // Last Ctrl+C, nil if nothing was copied
+	SelectDrag          *selectDrag   // Region being dragged out with the Select tool
+	LassoSelect         bool          // Drag a free-form region instead of a rectangle
+	ColorMap            *ColorMapping // Color vertices by a metric, nil for their own colors
+	SizeMap             *SizeMapping  // Size vertices by a metric, nil for their own radius
+	Title               string        // Shown top left and in exports
+	Caption             string        // Shown bottom center and in exports
+	Animation           *Animation    // Algorithm animation being played, nil if none
+	LayoutRun           *LayoutRun    // Shift+L layout in progress, nil if none
+	Importing           *ImportJob    // File being imported in the background, nil if none
+	Camera              Camera        // Zoom and pan of the canvas (see screenspace.go)
+	Debug               *DebugOverlay // F12 performance overlay, nil if off
+	Crash               *Crash        // Last recovered panic, until its dialog is closed (crash.go)
+	Exercise            *Exercise     // Open exercise, nil if none
+	Tutorial            *Tutorial     // Running tutorial, nil if none
+	Quiz                *Quiz         // Quiz in progress, nil if none
+	Game                *Game         // Graph game being played, nil if none
+	Constraints         []Constraint  // Classes edits must keep the graph in (see constraints.go)
+	ConstraintsWarnOnly bool          // Make edits breaking a constraint anyway, with a warning
+	Timeline            *Timeline     // Time slider, nil to show every vertex and edge
+	History             History       // Undo steps (see history.go)
+	Filter              *Filter       // Vertex filter query, nil to show everything
+	SavedFilters        []string      // Queries used this session, oldest first
+	Stream              *Stream       // Command stream being followed, nil if none
+	SavePath            string        // File Ctrl+S saves to: the session or graph file opened or saved last, "" before either
+	FileName            string        // Name of the file the drawing was opened from or saved to, "" for a new one (see dirty.go)
+	Documents           []*Document   // Open documents, one per tab, nil while there's only one (see documents.go)
+	CurrentDocument     int           // Tab of the document in the fields above
+
+	exerciseChecked      int          // Revision the results were computed at
+	exerciseResultsCache []GoalResult // Goal results of the open exercise
+
+	revision       int                // Counts edits, anything computed from the graph is stale once it changes
+	savedRevision  int                // Revision when the drawing was last opened or saved
+	moved          bool               // Vertices moved outside edits (auto layout) since then
+	windowTitle    string             // Window title set last
+	metricCache    metricCache        // Metrics computed since the last edit
+	models         modelCache         // Interval and permutation models of the graph
+	decomposition  decompositionCache // Tree decomposition of the graph
+	modules        moduleCache        // Modular decomposition of the graph
+	heatmap        heatmapCache       // Heatmap vertex order and panel position
+	spatial        spatialIndex       // Grid for hit testing (see spatial.go)
+	labels         labelCache         // Automatically placed labels (see labels.go)
+	labelGrab      point              // Where the label being dragged is held, from its corner
+	vertexGrab     point              // Where the vertex being dragged is held, from its center
+	vertexFrom     point              // Where the vertex being dragged was before the drag
+	bundles        bundleCache        // Bundled edge shapes for the current positions
+	bundleSlider   Slider             // Bundling strength
+	clock          frameClock         // Frame timing and idle redraws (see idle.go)
+	mutations      chan mutation      // Changes posted by other goroutines (see mutations.go)
+	announce       []func(string)     // Announcement hooks (see accessibility.go)
+	loadHooks      []func()           // Run after loading a graph (see scripts.go)
+	changeHooks    []func()           // Run after the graph changed
+	scriptRevision int                // Revision the change hooks last ran at
+	quit           bool               // Set once the user confirmed quitting
 }
 
-type Graph struct {
-	Vertices  []Vertex
-	AdjMatrix [][]int
-}
+// Initializes the app.
+func NewApp() *App {
+	app := &App{
+		Graph: &Graph{
+			Vertices:  []Vertex{},
+			AdjMatrix: [][]int{},
+		},
+		Tool:     ToolAddVertex,
+		Camera:   Camera{Zoom: 1},
+		Settings: LoadSettings(),
+		View:     ViewOptions{CollapseParallel: true},
 
-// Adds a vertex to the graph.
-func (g *Graph) AddVertex(x, y float64, label string, clr color.RGBA) {
-	g.Vertices = append(g.Vertices, Vertex{X: x, Y: y, Label: label, Color: clr})
-	// Expand adjacency matrix:
-	for i := range g.AdjMatrix {
-		g.AdjMatrix[i] = append(g.AdjMatrix[i], 0)
+		bundleSlider: Slider{X0: bundleSliderLeft, X1: bundleSliderRight, Y: bundleSliderY},
+		mutations:    make(chan mutation, mutationQueueSize),
 	}
-	g.AdjMatrix = append(g.AdjMatrix, make([]int, len(g.Vertices)))
+	app.registerScripts()
+	return app
 }
 
-// Removes a vertex (and its edges) from the graph.
-// Uses some fun slice indexing.
-func (g *Graph) DeleteVertex(index int) {
-	if index < 0 || index >= len(g.Vertices) {
-		return
+// Processes mouse interactions.
+func (app *App) HandleMouseInput() {
+	x, y := cursorPosition()
+	sx, sy := float64(x), float64(y) // Screen, for the UI
+
+	if app.UpdateTimeline(sx, sy) || app.UpdateBundleSlider(sx, sy) || app.UpdateHeatmapDrag(sx, sy) {
+		return // Dragging a slider, or a row of the heatmap
 	}
-	g.Vertices = append(g.Vertices[:index], g.Vertices[index+1:]...)
-	g.AdjMatrix = append(g.AdjMatrix[:index], g.AdjMatrix[index+1:]...)
-	for i := range g.AdjMatrix {
-		g.AdjMatrix[i] = append(g.AdjMatrix[i][:index], g.AdjMatrix[i][index+1:]...)
+	if app.UpdateCamera(sx, sy) {
+		return // Zooming or panning
 	}
-}
+	mx, my := app.toWorld(sx, sy) // World, for the graph
 
-// Removes an edge.
-func (g *Graph) DeleteEdge(v1, v2 int) {
-	if v1 < 0 || v2 < 0 || v1 >= len(g.Vertices) || v2 >= len(g.Vertices) {
-		return
+	var actions []EditAction
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		// Toolbar zone
+		if sy < toolbarHeight() {
+			if toolIndex := toolAt(sx, sy); toolIndex >= 0 {
+				app.SelectTool(Tool(toolIndex))
+			}
+			return
+		}
+		if sy < canvasTop() {
+			app.ClickOptionBar(sx)
+			return
+		}
+		if app.ClickModuleView(sx, sy) {
+			return
+		}
+		if app.ClickHeatmap(sx, sy) {
+			return
+		}
+
+		onEdge := func() bool { _, _, ok := app.EdgeAt(mx, my); return ok }
+		switch kind, ok := clickActions[app.Tool]; {
+		case app.Game != nil && app.Game.Winner < 0:
+			actions = append(actions, EditAction{Kind: EditGameMoveAt, X: mx, Y: my})
+		// Double clicking an edge splits it, unless the first click already did something there
+		case app.isDoubleClick(mx, my) && app.Tool != ToolAddVertex && app.Tool != ToolPen && app.Tool != ToolDeleteEdge && app.Tool != ToolDelete && app.Tool != ToolEdgeWeight && app.VertexAt(mx, my) < 0 && onEdge():
+			actions = append(actions, EditAction{Kind: EditSplitEdgeAt, X: mx, Y: my})
+		case ok:
+			shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+			actions = append(actions, EditAction{Kind: kind, X: mx, Y: my, Extend: shift, DepthFirst: shift})
+		}
 	}
 
-	if v1 == v2 { // Loop
-		if g.AdjMatrix[v1][v1] > 0 {
-			g.AdjMatrix[v1][v1]--
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if app.SelectDrag != nil {
+			actions = append(actions, EditAction{Kind: EditDragTo, X: mx, Y: my})
+		}
+		if app.Tool == ToolMoveVertex {
+			actions = append(actions, EditAction{Kind: EditMoveVertexTo, X: mx, Y: my})
 		}
-	} else { // Non-loop
-		g.AdjMatrix[v1][v2]--
-		g.AdjMatrix[v2][v1]--
+	} else if app.MovingVertex != nil || app.SelectDrag != nil || app.History.dragging {
+		actions = append(actions, EditAction{Kind: EditRelease, X: mx, Y: my})
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && app.PenLast != nil {
+		actions = append(actions, EditAction{Kind: EditEndChain, X: mx, Y: my})
+	}
+
+	for _, a := range actions {
+		app.Perform(a)
 	}
 }
 
-// Adds an edge between two vertices (allows parallel edges and loops - using Brezier curves).
-func (g *Graph) AddEdge(v1, v2 int) {
-	if v1 < 0 || v2 < 0 || v1 >= len(g.Vertices) || v2 >= len(g.Vertices) {
+// Returns the index of the vertex under (x, y), or -1 if there is none.
+func (app *App) VertexAt(x, y float64) int {
+	defer app.timeHitTest(time.Now())
+	return app.spatialIndex().vertexAt(app, x, y)
+}
+
+// Switches to a tool. Print Info, Save and Open aren't real tools, they act and keep the current one.
+func (app *App) SelectTool(t Tool) {
+	switch t {
+	case ToolPrintInfo:
+		app.ShowInfoDialog()
+		return
+	case ToolSave:
+		app.Save()
+		return
+	case ToolOpen:
+		app.askImport()
 		return
 	}
+	app.Tool = t
+	app.EdgeStart = nil
+	app.PenLast = nil
+	app.Announce(T("announce.tool", T(toolNames[t])))
+}
 
-	g.AdjMatrix[v1][v2]++
-	if v1 != v2 { // Only count loops once
-		g.AdjMatrix[v2][v1]++
+// Adds a new vertex with the default label and color.
+// If enabled in the settings, a label field opens right away.
+func (app *App) AddVertexAt(x, y float64) {
+	a := Action{Kind: ActionAddVertex, X: x, Y: y, Label: fmt.Sprintf("V%d", len(app.Graph.Vertices)+1), Color: app.defaultVertexColor(), Radius: app.Settings.Defaults.VertexRadius}
+	if app.Do(a) && app.Settings.LabelOnCreate {
+		app.EditLabel(len(app.Graph.Vertices)-1, "")
 	}
 }
 
-// App struct to hold application info
+// Opens an inline text field under a vertex to type its label, starting with text.
+// Committing an empty field keeps the old label.
+func (app *App) EditLabel(i int, text string) {
+	v := app.Graph.Vertices[i]
+	x, y := app.toScreen(v.X, v.Y)
+	app.OpenTextInput(&TextInput{
+		Text: text,
+		X:    x - 20,
+		Y:    y + app.toScreenLength(app.vertexRadius(i)) + 5,
+		OnCommit: func(text string) {
+			if text != "" && text != app.Graph.Vertices[i].Label {
+				app.Do(Action{Kind: ActionNameVertex, V1: i, Label: text})
+			}
+		},
+	})
+}
 
-type App struct {
-	Graph         *Graph    // Graph
-	Selected      *int      // Selected vertex (index)
-	Tool          Tool      // Selected tool
-	EdgeStart     *int      // Start vertex for adding an edge
-	MovingVertex  *int      // Index of the vertex being moved
-	LastClickTime time.Time // For vertex adding delay
+// Applies the current tool to a vertex.
+// Shared by mouse clicks and keyboard operation (Enter on the focused vertex).
+func (app *App) UseTool(i int) {
+	if app.Graph.CheckVertices(i) != nil {
+		return
+	}
+	v := app.Graph.Vertices[i]
+
+	switch app.Tool {
+	case ToolAddEdge:
+		app.pickEdgeEnd(i, ActionAddEdge)
+	case ToolDeleteEdge:
+		app.pickEdgeEnd(i, ActionDeleteEdge)
+	case ToolPen:
+		app.PenTo(i, v.X, v.Y)
+	case ToolMoveVertex:
+		app.Selected = &i
+	case ToolDeleteVertex, ToolDelete:
+		app.deleteVertex(i)
+	case ToolColorVertex:
+		app.Do(Action{Kind: ActionColorVertex, V1: i, Color: app.nextVertexColor(v.Color)})
+	case ToolNameVertex:
+		app.Selected = &i
+		app.EditLabel(i, v.Label)
+	case ToolSelect:
+		app.Selection.AddVertex(i, true)
+		app.Announce(T("announce.selected", app.selectionSummary()))
+	case ToolEdgeWeight:
+		app.pickWeightEnd(i)
+	case ToolTraverse:
+		app.Traverse(i, ebiten.IsKeyPressed(ebiten.KeyShift))
+	case ToolShortestPath:
+		app.pickPathEnd(i)
+	}
 }
 
-// Initializes the app.
-func NewApp() *App {
-	return &App{
-		Graph: &Graph{
-			Vertices:  []Vertex{},
-			AdjMatrix: [][]int{},
-		},
-		Tool: ToolAddVertex,
+// Picks a vertex as one end of an edge: the start if none is picked yet, else the end,
+// adding or removing (kind) an edge between the two.
+func (app *App) pickEdgeEnd(i int, kind ActionKind) {
+	if app.EdgeStart == nil {
+		app.EdgeStart = &i
+		app.Announce(T("announce.edge_start", app.Graph.Vertices[i].Label))
+		return
 	}
+	app.Do(Action{Kind: kind, V1: *app.EdgeStart, V2: i})
+	app.EdgeStart = nil
 }
 
-// Processes mouse interactions.
-func (app *App) HandleMouseInput() {
-	x, y := ebiten.CursorPosition()
-	mx, my := float64(x), float64(y)
+// Picks a vertex as one end of the edge to weigh: the start if none is picked yet, else the end,
+// asking then for the weight of the edge between the two if there is one.
+func (app *App) pickWeightEnd(i int) {
+	if app.EdgeStart == nil {
+		app.EdgeStart = &i
+		app.Announce(T("announce.edge_start", app.Graph.Vertices[i].Label))
+		return
+	}
+	from := *app.EdgeStart
+	app.EdgeStart = nil
+	if app.Graph.AdjMatrix[from][i] > 0 {
+		app.askEdgeWeight(from, i)
+	}
+}
 
-	// Handle other mouse clicks based on current tool
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		// Toolbar zone (assumes 100px wide buttons)
-		if my < 40 {
-			toolIndex := int(mx) / 100
-			if toolIndex >= 0 && toolIndex < len(toolNames) {
-				// Change selected tool if it's not print info
-				old_tool := app.Tool
-				app.Tool = Tool(toolIndex)
-				if app.Tool == ToolPrintInfo {
-					app.printGraphInfo()
-					app.Tool = old_tool
-				}
-			}
-			return
+// Processes keyboard shortcuts.
+//
+//	F9:  start/stop recording a macro.
+//	F10: replay the last macro (asks for a repeat count; Shift+F10: around the cursor).
+//	F1:  start/stop the tutorial.
+//	F2:  pick an exercise (or close the open one).
+//	F3:  quiz on the properties of a graph.
+//	F4:  check a property, with its proof.
+//	F12: toggle the debug overlay (frame times, hit tests, counts, memory).
+//	Ctrl+Delete: clear the graph.
+//	Ctrl+Z / Ctrl+Y: undo / redo (also Ctrl+Shift+Z).
+//	Delete/Backspace: delete the selection.
+//	Ctrl+C / Ctrl+V: copy the selection / paste it at the cursor (or a table from a spreadsheet).
+//	E: extract the selection (delete everything else; Shift+E: the k-hop neighborhood of the selected vertex).
+//	S: select by degree, color or label pattern.
+//	C: color vertices by a metric (Shift+C: continuous or categorical colors).
+//	R: size vertices by a metric.
+//	L: toggle auto layout (vertices keep drifting into place while editing; Shift+L: lay everything out now).
+//	T: edit the title (Shift+T: the caption).
+//	Ctrl+E: export as PNG, SVG, TikZ, TGF, GML, node-link JSON or DOT.
+//	Ctrl+S: save the session (to the session file opened last, or graph.gts).
+//	Ctrl+O: import a graph file (Ctrl+Shift+O: type its path, or a Neo4j URL).
+//	P: switch color palette (Shift+P: redraw without crossings, or show a K5 / K3,3 subdivision).
+//	N: toggle typing a label right after placing a vertex (Shift+N: next label placement).
+//	Ctrl+N: start over from a template.
+//	Ctrl+G: generate a random graph (G(n, p), G(n, m), Barabási–Albert, k-regular).
+//	D: toggle degree badges.
+//	F: toggle focus mode (highlight the selected vertex's neighborhood).
+//	M: toggle collapsing many parallel edges into one with a multiplicity label.
+//	W: vertex weights (set them, weighted independent set / vertex cover).
+//	I: toggle the time slider (Shift+I: set when the selection exists).
+//	B: toggle edge bundling (strength slider at the bottom; Shift+B: check bipartiteness, coloring the two sides).
+//	K: toggle coloring mode (numbered color classes, conflicts flagged; Shift+K: color the graph, greedily or exactly).
+//	Y: toggle the interval / permutation model panel.
+//	J: toggle the tree decomposition panel.
+//	U: toggle the modular decomposition panel (click a node to select its module).
+//	Z: toggle the adjacency heatmap panel (Shift+Z: next row and column order).
+//	X: replace the drawing with its quotient graph (by the categorical color mapping, or strong components).
+//	Q: critical path of a directed acyclic graph (earliest/latest times, edge weights as durations).
+//	O: color the connected components and report their sizes.
+//	V: look for an Eulerian circuit or trail and animate it edge by edge (Shift+V: a Hamiltonian cycle or path).
+//	] / [: bring the selected vertices to the front / send them to the back.
+//	Ctrl+D: use the style of the vertex under the cursor for new vertices.
+//	Home: reset zoom and pan (the wheel zooms, middle or Space drags pan).
+func (app *App) HandleKeyboardInput() {
+	mx, my := app.worldCursor()
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		if app.Recorder.Recording {
+			app.StopMacro(mx, my)
+		} else {
+			app.StartMacro(mx, my)
 		}
+	}
 
-		switch app.Tool {
-		case ToolAddVertex:
-			app.Graph.AddVertex(mx, my, fmt.Sprintf("V%d", len(app.Graph.Vertices)+1), color.RGBA{255, 0, 0, 255})
-		case ToolAddEdge:
-			for i, v := range app.Graph.Vertices { // Look thru vertices
-				if math.Hypot(v.X-mx, v.Y-my) < 15 { // To find one near mouse
-					if app.EdgeStart == nil {
-						app.EdgeStart = &i
-					} else {
-						app.Graph.AddEdge(*app.EdgeStart, i)
-						app.EdgeStart = nil
-					}
-					return
-				}
-			}
-		case ToolDeleteVertex:
-			for i, v := range app.Graph.Vertices {
-				if math.Hypot(v.X-mx, v.Y-my) < 15 {
-					app.Graph.DeleteVertex(i)
-					return
-				}
-			}
-		case ToolDeleteEdge:
-			// This whole thing could probably be better than O(n^4)
-			for i, v1 := range app.Graph.Vertices {
-				for j, v2 := range app.Graph.Vertices {
-					if i == j {
-						continue // Skip loops
-					}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		app.ToggleDebugOverlay()
+	}
 
-					if app.Graph.AdjMatrix[i][j] > 0 {
-						// Check line:
-						dist := pointToLineDistance(mx, my, v1.X, v1.Y, v2.X, v2.Y)
-						if dist < 10 {
-							app.Graph.DeleteEdge(i, j)
-							return
-						}
-
-						// Check parallel edges:
-						count := app.Graph.AdjMatrix[i][j]
-						for k := 0; k < count; k++ {
-							offset := float64(15 * (k - count/2))
-							cx, cy := (v1.X+v2.X)/2+offset, (v1.Y+v2.Y)/2-offset
-							dist := pointToBezierDistance(mx, my, v1.X, v1.Y, v2.X, v2.Y, cx, cy)
-							if dist < 10 {
-								app.Graph.DeleteEdge(i, j)
-								return
-							}
-						}
-					}
-				}
-			}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
+		app.ToggleTutorial()
+	}
 
-			// Handle loops
-			for i, v1 := range app.Graph.Vertices {
-				if app.Graph.AdjMatrix[i][i] > 0 {
-					count := app.Graph.AdjMatrix[i][i]
-					for k := 0; k < count; k++ {
-						angleOffset := float64(k) * (2 * math.Pi / float64(count))
-						angleLeft := angleOffset - math.Pi/10
-						angleRight := angleOffset + math.Pi/10
-						cxLeft := v1.X + 60*math.Cos(angleLeft)
-						cyLeft := v1.Y + 60*math.Sin(angleLeft)
-						cxRight := v1.X + 60*math.Cos(angleRight)
-						cyRight := v1.Y + 60*math.Sin(angleRight)
-						dist := pointToQuadraticBezierDistance(mx, my, v1.X, v1.Y, v1.X, v1.Y, cxLeft, cyLeft, cxRight, cyRight)
-						if dist < 10 {
-							app.Graph.DeleteEdge(i, i)
-							return
-						}
-					}
-				}
-			}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		app.ShowExerciseDialog()
+	}
 
-		case ToolColorVertex:
-			for i, v := range app.Graph.Vertices {
-				if math.Hypot(v.X-mx, v.Y-my) < 15 {
-					app.Graph.Vertices[i].Color = color.RGBA{0, 255, 0, 255}
-					return
-				}
-			}
-		case ToolNameVertex:
-			for i, v := range app.Graph.Vertices {
-				if math.Hypot(v.X-mx, v.Y-my) < 15 {
-					app.Selected = &i
-					fmt.Printf("Name V%d: ", i)
-					var newName string
-					fmt.Scanln(&newName)
-					app.Graph.Vertices[i].Label = newName
-					return
-				}
-			}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		app.StartQuiz()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+		app.ShowCertifyDialog()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) && app.Macro != nil && !app.Recorder.Recording {
+		app.AskReplayMacro(mx, my, ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyDelete) && len(app.Graph.Vertices) > 0 {
+		app.Confirm(T("confirm.clear"), func() { app.Do(Action{Kind: ActionClear}) })
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyDelete) || inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		app.DeleteSelection()
+	}
+
+	if ctrl && ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		app.Redo()
+	} else if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		app.Undo()
+	} else if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		app.CycleHeatmapOrder()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		app.ToggleHeatmap()
+	}
+
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		app.Redo()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		app.ToggleModelView()
+	}
+
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		app.CopySelection()
+	}
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		app.PasteClipboard(mx, my)
+	} else if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		app.ShowHamiltonianDialog()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		app.ShowEulerianTrail()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) && !ctrl {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			app.ToggleColorMappingKind()
+		} else {
+			app.CycleColorMapping()
 		}
 	}
 
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		if app.Tool == ToolMoveVertex {
-			for i, v := range app.Graph.Vertices {
-				if math.Hypot(v.X-mx, v.Y-my) < 15 {
-					app.MovingVertex = &i
-					break
-				}
-			}
-			if app.MovingVertex != nil {
-				v := &app.Graph.Vertices[*app.MovingVertex]
-				v.X, v.Y = mx, my
-			}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		app.EditTitle(ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		app.ToggleLayoutRun()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		app.toggleView(&app.View.AutoLayout, "view.auto_layout")
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		app.CycleSizeMapping()
+	}
+
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		app.Save()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		app.ShowSelectByDialog()
+	}
+	if ctrl && ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		app.askImportPath()
+	} else if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		app.askImport()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		app.AnalyzeComponents()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+		app.RaiseSelection(true)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+		app.RaiseSelection(false)
+	}
+
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		app.ShowExportDialog()
+	} else if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		app.ExtractEgoNetwork()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyE) && !app.Selection.Empty() {
+		app.Confirm(T("confirm.extract"), app.ExtractSelection)
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		app.Planarize()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		app.CyclePalette()
+	}
+
+	if ctrl && ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		app.ToggleFilterDim()
+	} else if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		app.OpenFilterBar()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		app.toggleView(&app.View.FocusMode, "view.focus_mode")
+	}
+
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		app.CloseDocument()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		app.ShowWeightsDialog()
+	}
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyPageDown) {
+		app.cycleDocument(1)
+	} else if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyPageUp) {
+		app.cycleDocument(-1)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		app.ShowAttributesDialog()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			app.askSelectionTime()
+		} else {
+			app.ToggleTimeline()
+		}
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		app.CheckBipartite()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		app.ToggleBundling()
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		app.ShowColorGraphDialog()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		app.ToggleColoringMode()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		app.ToggleDecompositionView()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		app.ToggleModuleView()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		app.ShowCriticalPath()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
+		app.Condense()
+	}
+
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		app.ShowGenerateDialog()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		app.ShowGameDialog()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		app.ShowConstraintsDialog()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		app.toggleView(&app.View.CollapseParallel, "view.collapse_parallel")
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		if i := app.VertexAt(mx, my); i >= 0 {
+			app.SetAsDefault(i)
 		}
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		app.toggleView(&app.View.ShowDegrees, "view.degrees")
 	}
 
-	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		app.MovingVertex = nil
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		app.ShowTemplateDialog()
+	} else if ebiten.IsKeyPressed(ebiten.KeyShift) && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		app.CycleLabelPlacement()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		app.Settings.LabelOnCreate = !app.Settings.LabelOnCreate
+		if err := app.Settings.Save(); err != nil {
+			app.Warn(T("warn.save_settings", err))
+		}
+		if app.Settings.LabelOnCreate {
+			app.Notify(T("status.label_on_create_on"))
+		} else {
+			app.Notify(T("status.label_on_create_off"))
+		}
 	}
+
+	app.HandleKeyboardNavigation()
+}
+
+// Quits, offering first to save unsaved changes in every document.
+func (app *App) RequestQuit() {
+	app.checkUnsavedDocuments(0, func() { app.quit = true })
 }
 
 // Drawing functions:
 
+// Curves are flattened into curveSegments segments for hit testing (spatial.go). Drawing leaves the
+// flattening to vector.Path, which follows the curve as closely at any zoom.
+const curveSegments = 32
+
+// Strokes a polyline, as one mesh (stroke.go).
+func strokePolyline(screen *ebiten.Image, points []point, width float64, clr color.RGBA) {
+	strokePath(screen, points, width, clr, capButt)
+}
+
 // Draws a Bézier curve from (x1,y1) to (x2,y2) with control point (cx,cy).
-func DrawLinearBézierEdge(screen *ebiten.Image, x1, y1, x2, y2, cx, cy float64, clr color.RGBA) {
-	for t := 0.0; t <= 1.0; t += 0.001 {
-		x := (1-t)*(1-t)*x1 + 2*(1-t)*t*cx + t*t*x2
-		y := (1-t)*(1-t)*y1 + 2*(1-t)*t*cy + t*t*y2
-		vector.DrawFilledRect(screen, float32(x), float32(y), 1, 1, clr, true)
-	}
+func DrawLinearBézierEdge(screen *ebiten.Image, x1, y1, x2, y2, cx, cy, width float64, clr color.RGBA) {
+	strokeQuadratic(screen, point{x1, y1}, point{cx, cy}, point{x2, y2}, width, clr)
 }
 
 // Draws a Bézier curve from (x1,y1) to (x2,y2) with control points (cx1,cy1) and (cx2,cy2).
-func DrawQuadraticBézierEdge(screen *ebiten.Image, x1, y1, x2, y2, xc1, yc1, xc2, yc2 float64, clr color.RGBA) {
-	for t := 0.0; t <= 1.0; t += 0.001 {
-		x := (1-t)*(1-t)*(1-t)*x1 + 3*(1-t)*(1-t)*t*xc1 + 3*(1-t)*t*t*xc2 + t*t*t*x2
-		y := (1-t)*(1-t)*(1-t)*y1 + 3*(1-t)*(1-t)*t*yc1 + 3*(1-t)*t*t*yc2 + t*t*t*y2
-		vector.DrawFilledRect(screen, float32(x), float32(y), 1, 1, clr, true)
-	}
+func DrawQuadraticBézierEdge(screen *ebiten.Image, x1, y1, x2, y2, xc1, yc1, xc2, yc2, width float64, clr color.RGBA) {
+	strokeCubic(screen, point{x1, y1}, point{xc1, yc1}, point{xc2, yc2}, point{x2, y2}, width, clr)
 }
 
 // Draws all edges of the graph.
-func (g *Graph) DrawEdges(screen *ebiten.Image) {
-	edgeColor := color.RGBA{255, 0, 0, 255}
-
+// Each pair is drawn once (the matrix is symmetric), so transparent edges aren't blended twice.
+// Directed edges get an arrowhead at their target (directed.go).
+func (app *App) DrawEdges(screen *ebiten.Image) {
+	g := app.Graph
 	for i, v1 := range g.Vertices {
-		for j, v2 := range g.Vertices {
-			count := g.AdjMatrix[i][j]
-			if count > 0 {
-				if i == j { // Loop: Bézier curve
-					DrawLoopEdge(screen, v1.X, v1.Y, count, edgeColor)
+		for j := i; j < len(g.Vertices); j++ {
+			v2 := g.Vertices[j]
+			count := g.Multiplicity(i, j)
+			if count > 0 && app.edgeShown(i, j) {
+				edgeColor, width := app.edgeColor(i, j), app.edgeStyle(i, j).Width
+				// Arrowhead of the k-th edge, arriving along the line from (x, y)
+				arrow := func(k int, x, y float64) {
+					if _, to := g.edgeEnds(i, j, k); g.Directed {
+						v := g.Vertices[to]
+						drawArrowhead(screen, x, y, v.X, v.Y, app.vertexRadius(to), width, edgeColor)
+					}
+				}
+				if app.collapsed(count) { // Many parallel edges: one edge with a "×k" label
+					app.drawCollapsedEdges(screen, i, j, edgeColor)
+				} else if i == j { // Loop: Bézier curve
+					DrawLoopEdge(screen, v1.X, v1.Y, count, width, edgeColor)
+					for k := range count {
+						_, _, cxRight, cyRight := loopControls(v1.X, v1.Y, k, count)
+						arrow(k, cxRight, cyRight)
+					}
+				} else if path := app.bundledEdge(i, j); path != nil { // Bundled edge: polyline
+					strokePolyline(screen, path, width, edgeColor)
+					if from, _ := g.edgeEnds(i, j, 0); from == i {
+						arrow(0, path[len(path)-2].X, path[len(path)-2].Y)
+					} else {
+						arrow(0, path[1].X, path[1].Y)
+					}
+				} else if cx, cy, ok := app.curvedEdge(i, j); ok { // Single edge drawn as an arc
+					DrawLinearBézierEdge(screen, v1.X, v1.Y, v2.X, v2.Y, cx, cy, width, edgeColor)
+					arrow(0, cx, cy)
 				} else if count == 1 { // Single edge: straight line
-					vector.StrokeLine(screen, float32(v1.X), float32(v1.Y), float32(v2.X), float32(v2.Y), 3.0, edgeColor, true)
+					strokePolyline(screen, []point{{v1.X, v1.Y}, {v2.X, v2.Y}}, width, edgeColor)
+					from, _ := g.edgeEnds(i, j, 0)
+					arrow(0, g.Vertices[from].X, g.Vertices[from].Y)
 				} else { // Parallel edges: Bézier curves
 					for k := 0; k < count; k++ {
-						offset := float64(20 * (k - count/2)) // Offset for parallel edges
-						cx, cy := (v1.X+v2.X)/2+offset, (v1.Y+v2.Y)/2-offset
-						DrawLinearBézierEdge(screen, v1.X, v1.Y, v2.X, v2.Y, cx, cy, edgeColor)
+						cx, cy := g.edgeControl(i, j, k, count)
+						DrawLinearBézierEdge(screen, v1.X, v1.Y, v2.X, v2.Y, cx, cy, width, edgeColor)
+						arrow(k, cx, cy)
 					}
 				}
 			}
@@ -312,98 +692,146 @@ func (g *Graph) DrawEdges(screen *ebiten.Image) {
 
 // Application functions.
 
-// Displays graph information.
-//
-//	Adjacency matrix.
-//	Number of edges and vertices.
-//	Degree of each vertex.
-func (app *App) printGraphInfo() {
-	numVertices := len(app.Graph.Vertices)
-	numEdges := 0
-	degrees := make([]int, numVertices)
-
-	// Print header:
-	fmt.Println("\nAdjacency Matrix:")
-	fmt.Printf("          ")
-	for _, vertex := range app.Graph.Vertices {
-		fmt.Printf("%-10s", vertex.Label)
-	}
-	fmt.Println()
-
-	// Print adjacency matrix:
-	for i := range app.Graph.AdjMatrix {
-		fmt.Printf("%-10s", app.Graph.Vertices[i].Label)
-		for j := range app.Graph.AdjMatrix[i] {
-			fmt.Printf("%-10d", app.Graph.AdjMatrix[i][j])
-			if app.Graph.AdjMatrix[i][j] > 0 {
-				degrees[i] += app.Graph.AdjMatrix[i][j]
-			}
-			numEdges += app.Graph.AdjMatrix[i][j]
-		}
-		fmt.Println()
-	}
-
-	// Print other graph information:
-	fmt.Printf("\n# vertices: %d\n", numVertices)
-	fmt.Printf("# edges: %d\n", numEdges)
-	for i, degree := range degrees {
-		fmt.Printf("deg(V%d \"%s\"): %d\n", i, app.Graph.Vertices[i].Label, degree)
-	}
-}
-
 // Draws loop(s) evenly spaced around the vertex.
-func DrawLoopEdge(screen *ebiten.Image, x, y float64, count int, clr color.RGBA) {
+func DrawLoopEdge(screen *ebiten.Image, x, y float64, count int, width float64, clr color.RGBA) {
 	for i := 0; i < count; i++ {
-		// Find angle to middle of loop
-		angleOffset := float64(i) * (2 * math.Pi / float64(count))
-		// Find angle to either side (for 2 Brezier control points)
-		angleLeft := angleOffset - math.Pi/10
-		angleRight := angleOffset + math.Pi/10
-		// Find control points
-		cxLeft := x + 60*math.Cos(angleLeft)
-		cyLeft := y + 60*math.Sin(angleLeft)
-		cxRight := x + 60*math.Cos(angleRight)
-		cyRight := y + 60*math.Sin(angleRight)
-		// Draw Brezier
-		DrawQuadraticBézierEdge(screen, x, y, x, y, cxLeft, cyLeft, cxRight, cyRight, clr)
+		cxLeft, cyLeft, cxRight, cyRight := loopControls(x, y, i, count)
+		DrawQuadraticBézierEdge(screen, x, y, x, y, cxLeft, cyLeft, cxRight, cyRight, width, clr)
 	}
 }
 
 // Draws the application.
 func (app *App) Draw(screen *ebiten.Image) {
-	// Draw toolbar
-	for i, toolName := range toolNames {
-		toolColor := color.RGBA{200, 200, 200, 255}
-		if app.Tool == Tool(i) {
-			toolColor = color.RGBA{100, 100, 255, 255} // Highlight selected tool
-		}
-		vector.DrawFilledRect(screen, float32(i*100), 0, 100, 40, toolColor, true)
-		ebitenutil.DebugPrintAt(screen, toolName, i*100+5, 10)
+	defer app.recoverPanic()
+	if !app.beginFrame(screen) {
+		return
+	}
+	defer app.timeDraw(time.Now())
+	renderScale = float64(screen.Bounds().Dx()) / screenWidth
+	defer func() { renderScale = 1 }()
+
+	// World pass: edges and vertices, through the camera (screenspace.go)
+	app.drawWorld(screen, app.DrawGraph)
+
+	// Screen pass: title and legends, then the marks on the graph
+	app.drawSceneText(screen)
+	app.drawSelection(screen)
+	app.drawSelectDrag(screen)
+	app.drawGameMarks(screen)
+
+	// Draw the pen's next edge following the cursor
+	if app.PenLast != nil && app.Graph.CheckVertices(*app.PenLast) == nil && app.Dialog == nil {
+		v := app.Graph.Vertices[*app.PenLast]
+		x, y := app.toScreen(v.X, v.Y)
+		cx, cy := cursorPosition()
+		strokeLine(screen, float32(x), float32(y), float32(cx), float32(cy), 1, color.RGBA{150, 150, 150, 255}, true)
 	}
 
-	// Draw edges
-	app.Graph.DrawEdges(screen)
+	// Draw keyboard focus and pending edge start
+	if app.EdgeStart != nil && app.Graph.CheckVertices(*app.EdgeStart) == nil {
+		v := app.Graph.Vertices[*app.EdgeStart]
+		x, y := app.toScreen(v.X, v.Y)
+		strokeCircle(screen, float32(x), float32(y), float32(app.toScreenLength(app.vertexRadius(*app.EdgeStart)))+3, 2, color.RGBA{100, 100, 255, 255}, true)
+	}
+	if app.Focused != nil && app.Graph.CheckVertices(*app.Focused) == nil {
+		v := app.Graph.Vertices[*app.Focused]
+		x, y := app.toScreen(v.X, v.Y)
+		strokeCircle(screen, float32(x), float32(y), float32(app.toScreenLength(app.vertexRadius(*app.Focused)))+6, 2, color.RGBA{255, 255, 0, 255}, true)
+	}
 
-	// Draw vertices
-	for _, v := range app.Graph.Vertices {
-		vector.DrawFilledCircle(screen, float32(v.X), float32(v.Y), 15, v.Color, true)
-		ebitenutil.DebugPrintAt(screen, v.Label, int(v.X)-10, int(v.Y)-5)
+	// Draw macro recording indicator
+	if app.Recorder.Recording {
+		printAt(screen, T("status.recording"), 5, 580)
 	}
+
+	// UI goes on top of the graph
+	app.DrawToolbar(screen)
+	app.DrawOptionBar(screen)
+	app.DrawExercise(screen)
+	app.DrawColoringStatus(screen)
+	app.DrawModelView(screen)
+	panelTop := app.DrawDecompositionView(screen, float32(canvasTop())+40)
+	app.DrawModuleView(screen, panelTop)
+	app.DrawHeatmap(screen)
+	app.DrawFilterStatus(screen)
+	app.DrawConstraintStatus(screen)
+	app.DrawGameStatus(screen)
+	app.DrawTimeline(screen)
+	app.DrawBundleSlider(screen)
+	app.DrawTutorial(screen)
+
+	app.DrawTooltip(screen)
+	app.DrawHitMenu(screen)
+	app.DrawTextInput(screen)
+	app.DrawImportProgress(screen)
+	app.DrawWarnings(screen)
+	app.DrawDialog(screen)
+	app.DrawDebugOverlay(screen)
 }
 
 // Computes next frame.
 func (app *App) Update() error {
+	defer app.recoverPanic()
+	defer app.timeUpdate(time.Now())
+	app.tick()
+	app.runMutations()
+	app.UpdateScripts()
+	app.dropStaleMappings()
+	if app.quit {
+		return ebiten.Termination
+	}
+	if ebiten.IsWindowBeingClosed() {
+		app.RequestQuit()
+	}
+	app.UpdateWindowTitle()
+
+	app.UpdateTutorial()
+
+	// An open dialog blocks everything else
+	if app.Dialog != nil {
+		app.HandleDialogInput(screenWidth, screenHeight)
+		return nil
+	}
+	if app.HitMenu != nil {
+		app.HandleHitMenuInput()
+		return nil
+	}
+
+	// Typing into a text field replaces the keyboard shortcuts
+	if app.TextInput != nil {
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			app.CommitTextInput() // Clicking elsewhere keeps the text
+		} else {
+			app.HandleTextInput()
+		}
+		app.HandleMouseInput()
+		return nil
+	}
+
+	app.UpdateCursor()
+	app.UpdateHover()
+	app.UpdateAnimation()
+	app.UpdateAutoLayout()
+	app.UpdateLayoutRun()
 	app.HandleMouseInput()
+	app.HandleKeyboardInput()
 	return nil
 }
 
 // Sets the screen size.
+// The screen has the window's real resolution, drawing scales logical coordinates up (hidpi.go).
 func (app *App) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return 800, 600
+	layoutScale = fitScale(outsideWidth, outsideHeight)
+	return int(math.Round(screenWidth * layoutScale)), int(math.Round(screenHeight * layoutScale))
 }
 
 // Helper functions:
 
+// Width in pixels of a string drawn with the debug font.
+func textWidth(s string) int {
+	return 6 * utf8.RuneCountInString(s)
+}
+
 // Calculate the distance from a point (mx, my) to a line segment (x1, y1) -> (x2, y2).
 func pointToLineDistance(mx, my, x1, y1, x2, y2 float64) float64 {
 	lineLength := math.Hypot(x2-x1, y2-y1)
@@ -418,57 +846,36 @@ func pointToLineDistance(mx, my, x1, y1, x2, y2 float64) float64 {
 	return math.Hypot(mx-closestX, my-closestY)
 }
 
-// Calculate the distance from a point (mx, my) to a Bézier curve (x1, y1) -> (x2, y2) with control point (cx, cy).
-// Done by sample along the Bézier curve and find the closest point.
-func pointToBezierDistance(mx, my, x1, y1, x2, y2, cx, cy float64) float64 {
-	closestDist := math.Inf(1)
-	for t := 0.0; t <= 1.0; t += 0.001 {
-		x := (1-t)*(1-t)*x1 + 2*(1-t)*t*cx + t*t*x2
-		y := (1-t)*(1-t)*y1 + 2*(1-t)*t*cy + t*t*y2
-		dist := math.Hypot(mx-x, my-y)
-		if dist < closestDist {
-			closestDist = dist
-		}
-	}
-	return closestDist
-}
+// Entry point.
 
-// Calculate the distance from a point (mx, my) to a Bézier curve (x1, y1) -> (x2, y2) with control point (cx, cy).
-// Done by sampling along the Bézier curve and finding the closest point.
-func pointToLinearBezierDistance(mx, my, x1, y1, x2, y2, cx, cy float64) float64 {
-	closestDist := math.Inf(1)
-	for t := 0.0; t <= 1.0; t += 0.001 {
-		x := (1-t)*(1-t)*x1 + 2*(1-t)*t*cx + t*t*x2
-		y := (1-t)*(1-t)*y1 + 2*(1-t)*t*cy + t*t*y2
-		dist := math.Hypot(mx-x, my-y)
-		if dist < closestDist {
-			closestDist = dist
+func main() {
+	stream := flag.String("stream", "", "follow graph commands from a file (- for stdin)")
+	flag.Parse()
+
+	app := NewApp()
+	if err := SetLocale(app.Settings.Locale); err != nil {
+		log.Println(err)
+	}
+	if ran, err := runCommand(flag.Args()); ran {
+		if err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
-	return closestDist
-}
-
-// Calculate the distance from a point (mx, my) to a Bézier curve (x1, y1) -> (x2, y2) with control points (cx1, cy1) and (cx2, cy2).
-// Done by sampling along the Bézier curve and finding the closest point.
-func pointToQuadraticBezierDistance(mx, my, x1, y1, x2, y2, cx1, cy1, cx2, cy2 float64) float64 {
-	closestDist := math.Inf(1)
-	for t := 0.0; t <= 1.0; t += 0.001 {
-		x := (1-t)*(1-t)*(1-t)*x1 + 3*(1-t)*(1-t)*t*cx1 + 3*(1-t)*t*t*cx2 + t*t*t*x2
-		y := (1-t)*(1-t)*(1-t)*y1 + 3*(1-t)*(1-t)*t*cy1 + 3*(1-t)*t*t*cy2 + t*t*t*y2
-		dist := math.Hypot(mx-x, my-y)
-		if dist < closestDist {
-			closestDist = dist
+	if *stream != "" {
+		if err := app.StartStream(*stream); err != nil {
+			log.Fatal(err)
 		}
 	}
-	return closestDist
-}
-
-// Entry point.
-
-func main() {
-	app := NewApp()
 	ebiten.SetWindowSize(1920, 1080)
-	ebiten.SetWindowTitle("Graph Tool")
+	app.UpdateWindowTitle()
+	ebiten.SetWindowClosingHandled(true)
+	ebiten.SetScreenClearedEveryFrame(false) // Idle frames aren't drawn again (idle.go)
+	if path := flag.Arg(0); path != "" {
+		app.ImportFile(path)
+	} else if app.Stream == nil {
+		app.ShowTemplateDialog() // The stream draws the graph
+	}
 	if err := ebiten.RunGame(app); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"slices"
+)
+
+// Vertex metrics:
+
+// Numbers computed per vertex, used to color (and size) vertices by them.
+// Each metric returns one value per vertex, in vertex order.
+// New metrics just need an entry in metrics, the vertex attribute columns (schema.go) come after them.
+
+type Metric struct {
+	Name        string // Locale key
+	Categorical bool   // Values are labels (e.g. component ids), not amounts
+	Values      func(g *Graph) []float64
+}
+
+var metrics = []Metric{
+	{Name: "metric.degree", Values: func(g *Graph) []float64 {
+		values := make([]float64, len(g.Vertices))
+		for i := range g.Vertices {
+			values[i] = float64(g.Degree(i))
+		}
+		return values
+	}},
+	{Name: "metric.component", Categorical: true, Values: func(g *Graph) []float64 {
+		values := make([]float64, len(g.Vertices))
+		for i, c := range g.Components() {
+			values[i] = float64(c)
+		}
+		return values
+	}},
+	{Name: "metric.strong_component", Categorical: true, Values: func(g *Graph) []float64 {
+		values := make([]float64, len(g.Vertices))
+		for i, c := range g.StrongComponents() {
+			values[i] = float64(c)
+		}
+		return values
+	}},
+	{Name: "metric.core", Values: func(g *Graph) []float64 {
+		values := make([]float64, len(g.Vertices))
+		for i, k := range g.CoreNumbers() {
+			values[i] = float64(k)
+		}
+		return values
+	}},
+	{Name: "metric.closeness", Values: func(g *Graph) []float64 { return g.Closeness() }},
+	{Name: "metric.betweenness", Values: func(g *Graph) []float64 { return g.Betweenness() }},
+	{Name: "metric.weight", Values: func(g *Graph) []float64 {
+		values := make([]float64, len(g.Vertices))
+		for i, v := range g.Vertices {
+			values[i] = v.Weight
+		}
+		return values
+	}},
+}
+
+// Returns the metrics to map by: the built-in ones, then one per vertex attribute column.
+func (app *App) vertexMetrics() []Metric {
+	return append(slices.Clip(metrics), app.Graph.Schema.metrics()...)
+}
+
+// Drops color and size mappings whose attribute column went away (cleared or replaced graph).
+func (app *App) dropStaleMappings() {
+	n := len(app.vertexMetrics())
+	if app.ColorMap != nil && app.ColorMap.Metric >= n {
+		app.ColorMap = nil
+	}
+	if app.SizeMap != nil && app.SizeMap.Metric >= n {
+		app.SizeMap = nil
+	}
+}
+
+// Returns the connected component of each vertex, numbered from 0 in order of their first vertex.
+// Edges join their ends whichever way they go.
+func (g *Graph) Components() []int {
+	component := make([]int, len(g.Vertices))
+	for i := range component {
+		component[i] = -1
+	}
+	next := 0
+	for start := range g.Vertices {
+		if component[start] >= 0 {
+			continue
+		}
+		component[start] = next
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for w := range g.Vertices {
+				if g.Multiplicity(v, w) > 0 && component[w] < 0 {
+					component[w] = next
+					queue = append(queue, w)
+				}
+			}
+		}
+		next++
+	}
+	return component
+}
+
+// Returns the core number of each vertex: the largest k such that the vertex
+// is in a subgraph where every vertex has at least k edges (loops don't count, directions don't either).
+// Repeatedly peels off a vertex of smallest remaining degree.
+func (g *Graph) CoreNumbers() []int {
+	n := len(g.Vertices)
+	degree := make([]int, n)
+	for i := range g.Vertices {
+		for j := range g.Vertices {
+			if j != i {
+				degree[i] += g.Multiplicity(i, j)
+			}
+		}
+	}
+	core := make([]int, n)
+	removed := make([]bool, n)
+	k := 0
+	for range n {
+		v := -1
+		for i := range n {
+			if !removed[i] && (v < 0 || degree[i] < degree[v]) {
+				v = i
+			}
+		}
+		k = max(k, degree[v])
+		core[v] = k
+		removed[v] = true
+		for w := range g.Vertices {
+			if !removed[w] && w != v {
+				degree[w] -= g.Multiplicity(v, w)
+			}
+		}
+	}
+	return core
+}
+
+// Returns the closeness centrality of each vertex: how near it is to everything it can reach,
+// (reachable - 1)² / ((n - 1) · total distance), so vertices in small components don't look central.
+func (g *Graph) Closeness() []float64 {
+	n := len(g.Vertices)
+	closeness := make([]float64, n)
+	for s := range n {
+		dist := g.distancesFrom(s)
+		reached, total := 0, 0
+		for _, d := range dist {
+			if d > 0 {
+				reached++
+				total += d
+			}
+		}
+		if total > 0 {
+			closeness[s] = float64(reached*reached) / float64((n-1)*total)
+		}
+	}
+	return closeness
+}
+
+// Returns the betweenness centrality of each vertex: over all pairs of other vertices, the share of
+// their shortest paths going through it (Brandes). Each pair counts once in undirected mode.
+func (g *Graph) Betweenness() []float64 {
+	n := len(g.Vertices)
+	betweenness := make([]float64, n)
+	for s := range n {
+		// Shortest paths from s: how many reach each vertex, and through which predecessors
+		dist := make([]int, n)
+		paths := make([]float64, n)
+		preds := make([][]int, n)
+		for i := range dist {
+			dist[i] = -1
+		}
+		dist[s], paths[s] = 0, 1
+		order := []int{s}
+		for k := 0; k < len(order); k++ {
+			v := order[k]
+			for w, count := range g.AdjMatrix[v] {
+				if count == 0 || w == v {
+					continue
+				}
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					order = append(order, w)
+				}
+				if dist[w] == dist[v]+1 {
+					paths[w] += paths[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+		// Back from the farthest vertices, each passes its share on to its predecessors
+		share := make([]float64, n)
+		for k := len(order) - 1; k > 0; k-- {
+			w := order[k]
+			for _, v := range preds[w] {
+				share[v] += paths[v] / paths[w] * (1 + share[w])
+			}
+			betweenness[w] += share[w]
+		}
+	}
+	if !g.Directed {
+		for i := range betweenness {
+			betweenness[i] /= 2
+		}
+	}
+	return betweenness
+}
+
+// Returns the number of edges on a shortest path from s to every vertex, -1 if unreachable.
+func (g *Graph) distancesFrom(s int) []int {
+	dist := make([]int, len(g.Vertices))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[s] = 0
+	queue := []int{s}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for w, count := range g.AdjMatrix[v] {
+			if count > 0 && dist[w] < 0 {
+				dist[w] = dist[v] + 1
+				queue = append(queue, w)
+			}
+		}
+	}
+	return dist
+}
+
+// Computed metrics, so drawing and hit tests don't recompute them every call.
+// Thrown away after every edit.
+type metricCache struct {
+	revision int
+	values   map[int][]float64 // By metric index
+}
+
+// Returns the values of a metric for the current graph.
+func (app *App) metricValues(m int) []float64 {
+	c := &app.metricCache
+	if c.values == nil || c.revision != app.revision {
+		c.revision, c.values = app.revision, map[int][]float64{}
+	}
+	values, ok := c.values[m]
+	if !ok || len(values) != len(app.Graph.Vertices) {
+		values = app.vertexMetrics()[m].Values(app.Graph)
+		c.values[m] = values
+	}
+	return values
+}
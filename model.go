@@ -0,0 +1,122 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Geometric models:
+
+// Y toggles a panel at the bottom left showing what makes an interval or permutation graph one:
+// the intervals (a bar per vertex over the clique order, overlapping exactly for neighbors),
+// or the permutation diagram (a segment per vertex between two lines, crossing exactly for neighbors).
+// Both are shown when the graph is both. Bars and segments take their vertex's color.
+
+const (
+	modelPanelWidth = 300
+	modelMaxRows    = 12 // Interval bars shown, the rest are summed up
+	modelLineGap    = 70 // Between the two lines of the permutation diagram
+)
+
+// Models of the graph, recomputed after edits.
+type modelCache struct {
+	revision    int
+	valid       bool
+	intervals   [][2]int // nil if not an interval graph
+	top, bottom []int    // nil if not a permutation graph
+}
+
+// Returns the models of the current graph.
+func (app *App) graphModels() *modelCache {
+	c := &app.models
+	if !c.valid || c.revision != app.revision {
+		*c = modelCache{revision: app.revision, valid: true}
+		c.intervals, _ = app.Graph.IntervalModel()
+		c.top, c.bottom, _ = app.Graph.PermutationModel()
+	}
+	return c
+}
+
+// Turns the model panel on or off.
+func (app *App) ToggleModelView() {
+	app.toggleView(&app.View.ShowModel, "view.model")
+}
+
+// Draws the model panel.
+func (app *App) DrawModelView(screen *ebiten.Image) {
+	if !app.View.ShowModel || len(app.Graph.Vertices) == 0 {
+		return
+	}
+	m := app.graphModels()
+	rows := min(len(app.Graph.Vertices), modelMaxRows)
+	h := 0
+	if m.intervals != nil {
+		h += 22 + 16*rows
+		if rows < len(app.Graph.Vertices) {
+			h += 16
+		}
+	}
+	if m.top != nil {
+		h += 22 + modelLineGap + 32
+	}
+	if h == 0 {
+		h = 22
+	}
+
+	_, sh := logicalSize(screen)
+	x, y := float32(10), float32(sh-60-h)
+	fillRect(screen, x, y, modelPanelWidth, float32(h), color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, modelPanelWidth, float32(h), 1, color.RGBA{200, 200, 200, 255}, true)
+	if m.intervals == nil && m.top == nil {
+		printAt(screen, T("model.none"), int(x)+5, int(y)+3)
+		return
+	}
+	if m.intervals != nil {
+		y = app.drawIntervalModel(screen, m.intervals, x, y)
+	}
+	if m.top != nil {
+		app.drawPermutationDiagram(screen, m.top, m.bottom, x, y)
+	}
+}
+
+// Draws a bar per vertex under a heading, returns where the next part of the panel goes.
+func (app *App) drawIntervalModel(screen *ebiten.Image, intervals [][2]int, x, y float32) float32 {
+	g := app.Graph
+	printAt(screen, T("model.interval"), int(x)+5, int(y)+3)
+	y += 22
+	cliques := 0
+	for _, iv := range intervals {
+		cliques = max(cliques, iv[1]+1)
+	}
+	left := x + 50 // Room for the labels
+	step := (modelPanelWidth - 60) / float32(cliques)
+	for i, iv := range intervals[:min(len(intervals), modelMaxRows)] {
+		printAt(screen, g.Vertices[i].Label, int(x)+5, int(y))
+		fillRect(screen, left+step*float32(iv[0])+1, y+4, step*float32(iv[1]-iv[0]+1)-2, 8, g.Vertices[i].Color, true)
+		y += 16
+	}
+	if len(intervals) > modelMaxRows {
+		printAt(screen, T("colormap.more", len(intervals)-modelMaxRows), int(x)+5, int(y))
+		y += 16
+	}
+	return y
+}
+
+// Draws the two lines of a permutation diagram with a segment per vertex.
+func (app *App) drawPermutationDiagram(screen *ebiten.Image, top, bottom []int, x, y float32) {
+	g := app.Graph
+	printAt(screen, T("model.permutation"), int(x)+5, int(y)+3)
+	y += 22 + 16 // Top labels above the line
+	left := x + 15
+	step := (modelPanelWidth - 30) / float32(max(1, len(top)-1))
+	lineColor := color.RGBA{200, 200, 200, 255}
+	strokeLine(screen, left, y, left+step*float32(len(top)-1), y, 1, lineColor, true)
+	strokeLine(screen, left, y+modelLineGap, left+step*float32(len(top)-1), y+modelLineGap, 1, lineColor, true)
+	for i, v := range g.Vertices {
+		x0, x1 := left+step*float32(top[i]), left+step*float32(bottom[i])
+		strokeLine(screen, x0, y, x1, y+modelLineGap, 2, v.Color, true)
+		printAt(screen, v.Label, int(x0)-textWidth(v.Label)/2, int(y)-16)
+		printAt(screen, v.Label, int(x1)-textWidth(v.Label)/2, int(y)+modelLineGap)
+	}
+}
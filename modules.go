@@ -0,0 +1,209 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Modular decomposition:
+
+// A module is a set of vertices every other vertex sees all or none of. U toggles a panel on the right
+// (treepanel.go) with the modular decomposition tree: a disconnected set splits into its components
+// (a parallel node), one whose complement is disconnected into the co-components (a series node), and
+// any other into its maximal proper modules (a prime node), down to single vertices. Clicking a node
+// selects its module on the canvas.
+//
+// The maximal module holding v is v with every w whose smallest module with v isn't the whole set;
+// that smallest module grows by adding each vertex that tells one of its members apart from v.
+// Loops and parallel edges don't matter, edges are read both ways.
+
+type ModuleNode struct {
+	Kind     string // Locale key: modules.parallel, modules.series, modules.prime, or "" for a vertex
+	Vertices []int  // The module, sorted
+	Parent   int    // -1 for the root
+}
+
+// Returns the modular decomposition tree, parents before children, nil for the empty graph.
+func (g *Graph) ModularDecomposition() []ModuleNode {
+	n := len(g.Vertices)
+	if n == 0 {
+		return nil
+	}
+	adj := func(a, b int) bool { return a != b && (g.adjacent(a, b) || g.adjacent(b, a)) }
+	var nodes []ModuleNode
+	var decompose func(set []int, parent int)
+	decompose = func(set []int, parent int) {
+		k := len(nodes)
+		nodes = append(nodes, ModuleNode{Vertices: set, Parent: parent})
+		if len(set) == 1 {
+			return
+		}
+		parts := splitConnected(set, adj)
+		nodes[k].Kind = "modules.parallel"
+		if len(parts) == 1 {
+			parts = splitConnected(set, func(a, b int) bool { return a != b && !adj(a, b) })
+			nodes[k].Kind = "modules.series"
+		}
+		if len(parts) == 1 {
+			parts = maximalModules(set, adj)
+			nodes[k].Kind = "modules.prime"
+		}
+		for _, part := range parts {
+			decompose(part, k)
+		}
+	}
+	all := make([]int, n)
+	for i := range all {
+		all[i] = i
+	}
+	decompose(all, -1)
+	return nodes
+}
+
+// Splits a vertex set into the components of the graph adj draws on it, each sorted.
+func splitConnected(set []int, adj func(a, b int) bool) [][]int {
+	seen := map[int]bool{}
+	var parts [][]int
+	for _, v := range set {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		part := []int{v}
+		for k := 0; k < len(part); k++ {
+			for _, w := range set {
+				if !seen[w] && adj(part[k], w) {
+					seen[w] = true
+					part = append(part, w)
+				}
+			}
+		}
+		slices.Sort(part)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// Splits a vertex set whose graph and complement are both connected into its maximal proper modules.
+func maximalModules(set []int, adj func(a, b int) bool) [][]int {
+	// Smallest module of the set holding v and w
+	smallest := func(v, w int) []int {
+		in := map[int]bool{v: true, w: true}
+		module := []int{v, w}
+		for k := 1; k < len(module); k++ {
+			x := module[k]
+			for _, y := range set {
+				if !in[y] && adj(y, x) != adj(y, v) {
+					in[y] = true
+					module = append(module, y)
+				}
+			}
+		}
+		return module
+	}
+	done := map[int]bool{}
+	var parts [][]int
+	for _, v := range set {
+		if done[v] {
+			continue
+		}
+		done[v] = true
+		part := []int{v}
+		for _, w := range set {
+			if !done[w] && len(smallest(v, w)) < len(set) {
+				done[w] = true
+				part = append(part, w)
+			}
+		}
+		slices.Sort(part)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// Modular decomposition of the graph, recomputed after edits.
+type moduleCache struct {
+	revision int
+	valid    bool
+	nodes    []ModuleNode
+	selected int       // Node clicked last, -1 for none
+	boxes    []nodeBox // Where the nodes were drawn, nil if they weren't
+}
+
+// Returns the modular decomposition cache for the current graph.
+func (app *App) modularDecomposition() *moduleCache {
+	c := &app.modules
+	if !c.valid || c.revision != app.revision {
+		*c = moduleCache{revision: app.revision, valid: true, nodes: app.Graph.ModularDecomposition(), selected: -1}
+	}
+	return c
+}
+
+// Turns the modular decomposition panel on or off.
+func (app *App) ToggleModuleView() {
+	app.toggleView(&app.View.ShowModules, "view.modules")
+	app.modules.boxes = nil
+}
+
+// Text of a tree node: the vertex label for a leaf, else the kind and module size.
+func (g *Graph) moduleText(node ModuleNode) string {
+	if node.Kind == "" {
+		return g.Vertices[node.Vertices[0]].Label
+	}
+	return T(node.Kind, len(node.Vertices))
+}
+
+// Draws the modular decomposition panel with its top at y, returns where the next panel goes.
+func (app *App) DrawModuleView(screen *ebiten.Image, y float32) float32 {
+	if !app.View.ShowModules {
+		return y
+	}
+	c := app.modularDecomposition()
+	if c.nodes == nil {
+		return y
+	}
+	prime := 0
+	for _, node := range c.nodes {
+		if node.Kind == "modules.prime" {
+			prime++
+		}
+	}
+	panel := TreePanel{Summary: T("modules.summary", len(c.nodes), prime)}
+	for k, node := range c.nodes {
+		panel.Texts = append(panel.Texts, app.Graph.moduleText(node))
+		panel.Parent = append(panel.Parent, node.Parent)
+		border := color.RGBA{200, 200, 200, 255}
+		if k == c.selected {
+			border = highlightEdgeColor
+		}
+		panel.Borders = append(panel.Borders, border)
+	}
+	y, c.boxes = panel.Draw(screen, y)
+	return y
+}
+
+// Selects the module of the tree node at (x, y), if there's one. Reports whether one was clicked.
+func (app *App) ClickModuleView(x, y float64) bool {
+	if !app.View.ShowModules {
+		return false
+	}
+	c := app.modularDecomposition()
+	for k, box := range c.boxes {
+		if !box.contains(x, y) {
+			continue
+		}
+		c.selected = k
+		app.Selection.Clear()
+		labels := make([]string, len(c.nodes[k].Vertices))
+		for a, v := range c.nodes[k].Vertices {
+			app.Selection.AddVertex(v, false)
+			labels[a] = app.Graph.Vertices[v].Label
+		}
+		app.Notify(T("modules.selected", app.Graph.moduleText(c.nodes[k]), strings.Join(labels, ", ")))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+// Mutation queue:
+
+// The graph has a single owner, the game loop: Update and Draw run on the same goroutine and are the
+// only code touching the model. Anything running elsewhere (the command stream, background
+// computations, and later an HTTP API, live sync or a file watcher) never touches it directly.
+// It posts functions to the mutation queue instead, which Update runs first thing, between frames.
+// Long computations work on a copy of the graph (Background) and post their result.
+
+const mutationQueueSize = 256 // Posting waits when this many are pending
+
+type mutation func(app *App)
+
+// Runs f on the game loop before the next frame. Safe to call from any goroutine but the loop's own,
+// where it could wait forever on a full queue.
+func (app *App) Post(f func(app *App)) {
+	app.mutations <- f
+}
+
+// Runs the posted mutations. Called at the start of every update.
+func (app *App) runMutations() {
+	for {
+		select {
+		case f := <-app.mutations:
+			f(app)
+		default:
+			return
+		}
+	}
+}
+
+// Runs compute on a copy of the graph in another goroutine, then done with its result on the game loop.
+// The result is dropped if the graph was edited meanwhile, it would describe another graph.
+func Background[T any](app *App, compute func(g *Graph) T, done func(app *App, result T)) {
+	g, revision := app.Graph.Clone(), app.revision
+	go func() {
+		result := compute(g)
+		app.Post(func(app *App) {
+			if app.revision == revision {
+				done(app, result)
+			}
+		})
+	}()
+}
+
+// Returns a deep copy of the graph.
+func (g *Graph) Clone() *Graph {
+	c := &Graph{
+		Vertices:    slices.Clone(g.Vertices),
+		AdjMatrix:   make([][]int, len(g.AdjMatrix)),
+		EdgeStyles:  maps.Clone(g.EdgeStyles),
+		EdgeTimes:   maps.Clone(g.EdgeTimes),
+		EdgeWeights: maps.Clone(g.EdgeWeights),
+		EdgeAttrs:   map[EdgeKey]map[string]string{},
+		Schema:      Schema{Columns: slices.Clone(g.Schema.Columns)},
+		Directed:    g.Directed,
+	}
+	for i, v := range c.Vertices {
+		if v.Active != nil {
+			active := *v.Active
+			c.Vertices[i].Active = &active
+		}
+		c.Vertices[i].Attrs = maps.Clone(v.Attrs)
+		if v.LabelOffset != nil {
+			offset := *v.LabelOffset
+			c.Vertices[i].LabelOffset = &offset
+		}
+	}
+	for i, row := range g.AdjMatrix {
+		c.AdjMatrix[i] = slices.Clone(row)
+	}
+	for key, attrs := range g.EdgeAttrs {
+		c.EdgeAttrs[key] = maps.Clone(attrs)
+	}
+	return c
+}
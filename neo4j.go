@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Neo4j results:
+
+// Property graphs come in two shapes, both read by the .json importer:
+//   - A transactional HTTP API answer with the "graph" result format, as saved from
+//     POST /db/neo4j/tx/commit (or fetched live, see below).
+//   - Neo4j Browser / APOC JSON exports: one {"type": "node"} or {"type": "relationship"} object per line.
+//
+// The vertex label is the "name" or "title" property if there is one, else the first node label and the id.
+// Properties become attributes, plus "labels" (joined with ":") on vertices and "type" on edges.
+//
+// Ctrl+O also takes the URL of a server (http://localhost:7474) and then asks for a Cypher query.
+// NEO4J_USER and NEO4J_PASSWORD are used for basic authentication. The query blocks until the answer
+// arrives (or neo4jTimeout passes), so keep it to something that fits on a canvas anyway.
+
+const (
+	neo4jTimeout      = 15 * time.Second
+	neo4jDefaultQuery = "MATCH p=()-->() RETURN p LIMIT 300"
+)
+
+var errNeo4j = errors.New("not a Neo4j result")
+
+type neo4jNode struct {
+	ID         json.RawMessage            `json:"id"`
+	Labels     []string                   `json:"labels"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+type neo4jRelationship struct {
+	ID         json.RawMessage            `json:"id"`
+	Type       string                     `json:"type"`
+	Label      string                     `json:"label"` // APOC exports call the type label
+	StartNode  json.RawMessage            `json:"startNode"`
+	EndNode    json.RawMessage            `json:"endNode"`
+	Start      *neo4jNode                 `json:"start"`
+	End        *neo4jNode                 `json:"end"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+type neo4jResponse struct {
+	Results []struct {
+		Data []struct {
+			Graph struct {
+				Nodes         []neo4jNode         `json:"nodes"`
+				Relationships []neo4jRelationship `json:"relationships"`
+			} `json:"graph"`
+		} `json:"data"`
+	} `json:"results"`
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Collects nodes and relationships, each node and relationship once (results repeat them).
+type neo4jBuilder struct {
+	ig    ImportedGraph
+	nodes map[string]int
+	rels  map[string]bool
+}
+
+func (b *neo4jBuilder) node(n neo4jNode) {
+	id := jsonText(n.ID)
+	if _, ok := b.nodes[id]; ok {
+		return
+	}
+	b.nodes[id] = len(b.ig.Vertices)
+	v := ImportedVertex{Label: id, Attrs: map[string]string{}}
+	if len(n.Labels) > 0 {
+		v.Label = n.Labels[0] + " " + id
+		v.Attrs["labels"] = strings.Join(n.Labels, ":")
+	}
+	for key, raw := range n.Properties {
+		v.Attrs[key] = jsonText(raw)
+	}
+	for _, key := range []string{"title", "name"} {
+		if s, ok := v.Attrs[key]; ok {
+			v.Label = s
+		}
+	}
+	b.ig.Vertices = append(b.ig.Vertices, v)
+}
+
+func (b *neo4jBuilder) relationship(r neo4jRelationship) error {
+	id := jsonText(r.ID)
+	if b.rels[id] {
+		return nil
+	}
+	b.rels[id] = true
+	start, end := jsonText(r.StartNode), jsonText(r.EndNode)
+	if r.Start != nil && r.End != nil {
+		start, end = jsonText(r.Start.ID), jsonText(r.End.ID)
+	}
+	a, okA := b.nodes[start]
+	c, okC := b.nodes[end]
+	if !okA || !okC {
+		return fmt.Errorf("%w: relationship %s (%s - %s)", ErrNoVertex, id, start, end)
+	}
+	e := ImportedEdge{A: a, B: c, Attrs: map[string]string{"type": r.Type}}
+	if r.Type == "" {
+		e.Attrs["type"] = r.Label
+	}
+	for key, raw := range r.Properties {
+		e.Attrs[key] = jsonText(raw)
+	}
+	b.ig.Edges = append(b.ig.Edges, e)
+	return nil
+}
+
+// Parses a Neo4j HTTP API answer or a JSON lines export.
+func parseNeo4j(data []byte) (*ImportedGraph, error) {
+	b := &neo4jBuilder{nodes: map[string]int{}, rels: map[string]bool{}}
+
+	var resp neo4jResponse
+	if json.Unmarshal(data, &resp) == nil && (resp.Results != nil || resp.Errors != nil) {
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("%s: %s", resp.Errors[0].Code, resp.Errors[0].Message)
+		}
+		var rels []neo4jRelationship
+		for _, result := range resp.Results {
+			for _, row := range result.Data {
+				for _, n := range row.Graph.Nodes {
+					b.node(n)
+				}
+				rels = append(rels, row.Graph.Relationships...)
+			}
+		}
+		for _, r := range rels { // After all nodes, a row may mention a node before its own row
+			if err := b.relationship(r); err != nil {
+				return nil, err
+			}
+		}
+		return &b.ig, nil
+	}
+
+	// JSON lines export
+	var rels []neo4jRelationship
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var kind struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &kind); err != nil {
+			return nil, errNeo4j
+		}
+		switch kind.Type {
+		case "node":
+			var n neo4jNode
+			if err := json.Unmarshal(line, &n); err != nil {
+				return nil, err
+			}
+			b.node(n)
+		case "relationship":
+			var r neo4jRelationship
+			if err := json.Unmarshal(line, &r); err != nil {
+				return nil, err
+			}
+			rels = append(rels, r)
+		default:
+			return nil, errNeo4j
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, r := range rels {
+		if err := b.relationship(r); err != nil {
+			return nil, err
+		}
+	}
+	return &b.ig, nil
+}
+
+// Reads any of the JSON formats: node-link, then the Neo4j ones.
+func parseJSONGraph(data []byte) (*ImportedGraph, error) {
+	ig, err := parseNodeLink(data)
+	if err == nil {
+		return ig, nil
+	}
+	if ig, errNeo := parseNeo4j(data); errNeo == nil {
+		return ig, nil
+	}
+	return nil, err
+}
+
+// Runs a Cypher query on a server and returns the graph it matched.
+func fetchNeo4j(server, query string) (*ImportedGraph, error) {
+	url := strings.TrimRight(server, "/")
+	if !strings.Contains(url, "/db/") {
+		url += "/db/neo4j/tx/commit"
+	}
+	body, err := json.Marshal(map[string]any{
+		"statements": []map[string]any{{"statement": query, "resultDataContents": []string{"graph"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if user := os.Getenv("NEO4J_USER"); user != "" {
+		req.SetBasicAuth(user, os.Getenv("NEO4J_PASSWORD"))
+	}
+
+	resp, err := (&http.Client{Timeout: neo4jTimeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return parseNeo4j(data)
+}
+
+// Asks for a Cypher query, then loads what it matches from a server.
+func (app *App) askNeo4jQuery(server string) {
+	app.OpenTextInput(&TextInput{
+		Text: neo4jDefaultQuery,
+		X:    40, Y: screenHeight / 2,
+		OnCommit: func(query string) {
+			ig, err := fetchNeo4j(server, query)
+			if err != nil {
+				app.Warn(T("warn.import", server, err))
+				return
+			}
+			app.importGraph(server, ig)
+		},
+	})
+	app.Notify(T("import.cypher_prompt"))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// networkx node-link JSON:
+
+// What nx.node_link_data (and json_graph.node_link_data) writes:
+//
+//	{"directed": false, "multigraph": false, "graph": {},
+//	 "nodes": [{"id": "a", "color": "#ff0000"}, {"id": "b", "pos": [0.1, 0.5]}],
+//	 "links": [{"source": "a", "target": "b", "weight": 3}]}
+//
+// Newer networkx versions write "edges" instead of "links", both are read.
+// The label is "label" or "name" if there is one, else the id. The position is "pos" ([x, y]) or "x" and "y".
+// "color" sets the vertex color when it's "#rrggbb". Everything else is kept as attributes.
+// "directed": true switches to directed mode. Other JSON graphs are tried next (neo4j.go).
+// Written files (graph.json) use the same layout, with the vertex index as id, so opening one
+// gives back the labels, positions, colors, attributes and edges. They say so with "generator" in
+// "graph": their positions are kept as they are rather than fitted to the canvas, "radius" is the
+// vertex radius, and see-through colors are written "#rrggbbaa".
+
+var errNodeLink = errors.New("not node-link JSON (no \"nodes\")")
+
+const nodeLinkGenerator = "graph-sketchpad" // "generator" of the files written here
+
+type nodeLinkData struct {
+	Directed bool                         `json:"directed"`
+	Graph    map[string]json.RawMessage   `json:"graph"`
+	Nodes    []map[string]json.RawMessage `json:"nodes"`
+	Links    []map[string]json.RawMessage `json:"links"`
+	Edges    []map[string]json.RawMessage `json:"edges"`
+}
+
+// Text of a JSON value: strings without their quotes, anything else as written.
+func jsonText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// Reads a float from a JSON number (or a string holding one).
+func jsonFloat(raw json.RawMessage) (float64, bool) {
+	f, err := strconv.ParseFloat(jsonText(raw), 64)
+	return f, err == nil
+}
+
+// Parses a node color: "#rrggbb", or "#rrggbbaa" for a see-through one. nil if it isn't a color.
+func parseNodeLinkColor(s string) *color.RGBA {
+	if len(s) != len("#rrggbbaa") || s[0] != '#' {
+		return parseHexColor(s)
+	}
+	c := parseHexColor(s[:len("#rrggbb")])
+	a, err := strconv.ParseUint(s[len("#rrggbb"):], 16, 8)
+	if c == nil || err != nil {
+		return nil
+	}
+	see := withOpacity(*c, uint8(a))
+	return &see
+}
+
+// Writes a node color as "#rrggbb", with the opacity after it when it isn't opaque.
+func nodeLinkColor(c color.RGBA) string {
+	if c.A == 0 || c.A == 255 {
+		return svgColor(c)
+	}
+	return fmt.Sprintf("%s%02x", svgColor(c), c.A)
+}
+
+// Parses node-link JSON.
+func parseNodeLink(data []byte) (*ImportedGraph, error) {
+	var d nodeLinkData
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	if d.Nodes == nil {
+		return nil, errNodeLink
+	}
+
+	ig := &ImportedGraph{Directed: d.Directed, Exact: jsonText(d.Graph["generator"]) == nodeLinkGenerator}
+	index := map[string]int{} // id -> vertex
+	for n, node := range d.Nodes {
+		id := jsonText(node["id"])
+		if node["id"] == nil {
+			id = strconv.Itoa(n)
+		}
+		index[id] = n
+		v := ImportedVertex{Label: id, Attrs: map[string]string{}}
+		for key, raw := range node {
+			switch key {
+			case "id":
+			case "label", "name":
+				v.Label = jsonText(raw)
+			case "pos":
+				var pos []float64
+				if json.Unmarshal(raw, &pos) == nil && len(pos) >= 2 {
+					v.X, v.Y, v.HasPos = pos[0], pos[1], true
+					continue
+				}
+				v.Attrs[key] = jsonText(raw)
+			case "color":
+				if v.Color = parseNodeLinkColor(jsonText(raw)); v.Color == nil {
+					v.Attrs[key] = jsonText(raw)
+				}
+			case "radius":
+				if r, ok := jsonFloat(raw); ok && ig.Exact && r > 0 {
+					v.Radius = r
+					continue
+				}
+				v.Attrs[key] = jsonText(raw)
+			default:
+				v.Attrs[key] = jsonText(raw)
+			}
+		}
+		if x, okX := jsonFloat(node["x"]); okX && !v.HasPos {
+			if y, okY := jsonFloat(node["y"]); okY {
+				v.X, v.Y, v.HasPos = x, y, true
+				delete(v.Attrs, "x")
+				delete(v.Attrs, "y")
+			}
+		}
+		ig.Vertices = append(ig.Vertices, v)
+	}
+
+	for _, link := range append(d.Links, d.Edges...) {
+		a, okA := index[jsonText(link["source"])]
+		b, okB := index[jsonText(link["target"])]
+		if !okA || !okB {
+			return nil, fmt.Errorf("%w: link %s - %s", ErrNoVertex, jsonText(link["source"]), jsonText(link["target"]))
+		}
+		e := ImportedEdge{A: a, B: b, Attrs: map[string]string{}}
+		for key, raw := range link {
+			if key != "source" && key != "target" && key != "key" {
+				e.Attrs[key] = jsonText(raw)
+			}
+		}
+		ig.Edges = append(ig.Edges, e)
+	}
+	return ig, nil
+}
+
+// Writes the graph as node-link JSON with positions, colors, radii and attributes.
+func writeNodeLink(buf *bytes.Buffer, g *Graph) error {
+	nodes := make([]map[string]any, len(g.Vertices))
+	for i, v := range g.Vertices {
+		node := map[string]any{}
+		for k, value := range v.Attrs {
+			node[k] = value
+		}
+		node["id"], node["label"], node["pos"], node["color"] = i, v.Label, []float64{v.X, v.Y}, nodeLinkColor(v.Color)
+		node["radius"] = v.Radius
+		nodes[i] = node
+	}
+	links := []map[string]any{}
+	multigraph := false
+	for i := range g.Vertices {
+		for j := range g.Vertices {
+			if j < i && !g.Directed { // Written from the lower index only
+				continue
+			}
+			multigraph = multigraph || g.AdjMatrix[i][j] > 1
+			for range g.AdjMatrix[i][j] {
+				link := map[string]any{}
+				for k, value := range g.EdgeAttrs[Edge(i, j)] {
+					link[k] = value
+				}
+				if w, ok := g.weightOf(i, j); ok {
+					link["weight"] = w
+				}
+				link["source"], link["target"] = i, j
+				links = append(links, link)
+			}
+		}
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"directed":   g.Directed,
+		"multigraph": multigraph,
+		"graph":      map[string]any{"generator": nodeLinkGenerator},
+		"nodes":      nodes,
+		"links":      links,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	buf.WriteByte('\n')
+	return nil
+}
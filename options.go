@@ -0,0 +1,200 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Tool option bar:
+
+// A strip under the toolbar with the parameters of the active tool.
+// Each tool lists its options in toolOptions; clicking an option cycles its value.
+// New tool parameters go there, nothing else needs to change.
+
+const (
+	optionBarHeight = 24
+	optionPadding   = 8
+)
+
+type ToolOption struct {
+	Label  string
+	Swatch *color.RGBA // Color sample drawn after the label (may be nil)
+	Action func()
+}
+
+// Top of the drawing area, below toolbar and option bar.
+func canvasTop() float64 {
+	return toolbarHeight() + optionBarHeight
+}
+
+// Cycles v through the given values (starting over after the last one).
+func cycleFloat(v float64, values ...float64) float64 {
+	for i, x := range values {
+		if x == v {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+// Saves the settings after an option changed.
+func (app *App) saveOption() {
+	if err := app.Settings.Save(); err != nil {
+		app.Warn(T("warn.save_settings", err))
+	}
+}
+
+// Says on or off, for option labels.
+func onOff(b bool) string {
+	if b {
+		return T("view.on")
+	}
+	return T("view.off")
+}
+
+// Options of the active tool.
+func (app *App) toolOptions() []ToolOption {
+	d := &app.Settings.Defaults
+
+	switch app.Tool {
+	case ToolAddVertex, ToolPen:
+		vertexColor := app.defaultVertexColor()
+		return []ToolOption{
+			{Label: T("option.color"), Swatch: &vertexColor, Action: func() {
+				d.VertexColor = app.nextVertexColor(vertexColor)
+				app.saveOption()
+			}},
+			{Label: T("option.opacity", opacityPercent(vertexColor)), Action: func() {
+				d.VertexColor = nextOpacity(vertexColor)
+				app.saveOption()
+			}},
+			{Label: T("option.size", d.VertexRadius), Action: func() {
+				d.VertexRadius = cycleFloat(d.VertexRadius, 10, 15, 20, 25)
+				app.saveOption()
+			}},
+			{Label: T("option.label_on_create", onOff(app.Settings.LabelOnCreate)), Action: func() {
+				app.Settings.LabelOnCreate = !app.Settings.LabelOnCreate
+				app.saveOption()
+			}},
+		}
+	case ToolAddEdge:
+		edgeColor := d.EdgeColor
+		return []ToolOption{
+			{Label: T("option.edge_color"), Swatch: &edgeColor, Action: func() {
+				d.EdgeColor = app.nextVertexColor(edgeColor)
+				app.saveOption()
+			}},
+			{Label: T("option.edge_width", d.EdgeWidth), Action: func() {
+				d.EdgeWidth = cycleFloat(d.EdgeWidth, 1, 2, 3, 5)
+				app.saveOption()
+			}},
+			{Label: T("option.opacity", opacityPercent(edgeColor)), Action: func() {
+				d.EdgeColor = nextOpacity(edgeColor)
+				app.saveOption()
+			}},
+			{Label: T("option.curved", onOff(d.EdgeCurved)), Action: func() {
+				d.EdgeCurved = !d.EdgeCurved
+				app.saveOption()
+			}},
+			{Label: T("option.directed", onOff(app.Graph.Directed)), Action: app.ToggleDirected},
+		}
+	case ToolDeleteVertex:
+		return []ToolOption{
+			{Label: T("option.confirm", onOff(app.Settings.Confirmations)), Action: func() {
+				app.Settings.Confirmations = !app.Settings.Confirmations
+				app.saveOption()
+			}},
+		}
+	case ToolDelete:
+		return []ToolOption{
+			{Label: T(deleteFilterNames[app.DeleteFilter]), Action: func() {
+				app.DeleteFilter = (app.DeleteFilter + 1) % DeleteFilter(len(deleteFilterNames))
+			}},
+			{Label: T("option.confirm", onOff(app.Settings.Confirmations)), Action: func() {
+				app.Settings.Confirmations = !app.Settings.Confirmations
+				app.saveOption()
+			}},
+		}
+	case ToolSelect:
+		return app.selectionOptions()
+	case ToolColorVertex:
+		return []ToolOption{
+			{Label: T("option.palette", T(app.Palette().Name)), Action: app.CyclePalette},
+			{Label: T("option.coloring_mode", onOff(app.View.ColoringMode)), Action: app.ToggleColoringMode},
+		}
+	}
+	return nil
+}
+
+// Position and width of each option button.
+func optionLayout(options []ToolOption) (xs, widths []float32) {
+	x := float32(optionPadding)
+	for _, o := range options {
+		w := float32(textWidth(o.Label) + 2*optionPadding)
+		if o.Swatch != nil {
+			w += 18
+		}
+		xs, widths = append(xs, x), append(widths, w)
+		x += w + optionPadding
+	}
+	return xs, widths
+}
+
+// Handles a click in the option bar.
+func (app *App) ClickOptionBar(x float64) {
+	if app.ClickTabs(x) {
+		return
+	}
+	options := app.toolOptions()
+	xs, widths := optionLayout(options)
+	for i, o := range options {
+		if float32(x) >= xs[i] && float32(x) < xs[i]+widths[i] {
+			o.Action()
+			return
+		}
+	}
+}
+
+// Draws the option bar of the active tool.
+func (app *App) DrawOptionBar(screen *ebiten.Image) {
+	top := float32(toolbarHeight())
+	fillRect(screen, 0, top, screenWidth, optionBarHeight, color.RGBA{60, 60, 60, 255}, true)
+
+	options := app.toolOptions()
+	xs, widths := optionLayout(options)
+	for i, o := range options {
+		strokeRect(screen, xs[i], top+3, widths[i], optionBarHeight-6, 1, color.RGBA{140, 140, 140, 255}, true)
+		printAt(screen, o.Label, int(xs[i])+optionPadding, int(top)+4)
+		if o.Swatch != nil {
+			sx := xs[i] + widths[i] - optionPadding - 12
+			fillRect(screen, sx, top+6, 12, 12, *o.Swatch, true)
+		}
+	}
+	app.DrawTabs(screen)
+}
+
+// Sets the mouse cursor to fit the active tool (or the UI element under it).
+func (app *App) UpdateCursor() {
+	_, y := cursorPosition()
+	shape := ebiten.CursorShapeDefault
+	switch {
+	case app.Dialog != nil:
+	case float64(y) < canvasTop():
+		shape = ebiten.CursorShapePointer
+	default:
+		switch app.Tool {
+		case ToolAddVertex, ToolPen, ToolDeleteVertex, ToolDeleteEdge, ToolDelete:
+			shape = ebiten.CursorShapeCrosshair
+		case ToolAddEdge, ToolColorVertex, ToolSelect:
+			shape = ebiten.CursorShapePointer
+		case ToolMoveVertex:
+			shape = ebiten.CursorShapeMove
+		case ToolNameVertex:
+			shape = ebiten.CursorShapeText
+		}
+	}
+	if ebiten.CursorShape() != shape {
+		ebiten.SetCursorShape(shape)
+	}
+}
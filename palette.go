@@ -0,0 +1,95 @@
+package main
+
+import (
+	"image/color"
+)
+
+// Color palettes:
+
+// Everything that hands out colors (the Color Vertex tool, algorithm colorings) picks them from the active palette,
+// so switching to a colorblind-safe scheme changes all of them at once.
+
+type Palette struct {
+	Name   string // Locale key
+	Colors []color.RGBA
+}
+
+var palettes = []Palette{
+	{Name: "palette.default", Colors: []color.RGBA{
+		{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 0, 255},
+		{255, 0, 255, 255}, {0, 255, 255, 255}, {255, 128, 0, 255}, {128, 0, 255, 255},
+	}},
+	// Saturated colors far apart in brightness, for projectors and low vision.
+	{Name: "palette.high_contrast", Colors: []color.RGBA{
+		{255, 255, 255, 255}, {255, 255, 0, 255}, {0, 255, 255, 255}, {255, 0, 255, 255},
+		{0, 255, 0, 255}, {255, 128, 0, 255}, {128, 128, 128, 255}, {0, 128, 255, 255},
+	}},
+	// Okabe-Ito, the usual choice for red-green (deuteranopia) safety.
+	{Name: "palette.deuteranopia", Colors: []color.RGBA{
+		{230, 159, 0, 255}, {86, 180, 233, 255}, {0, 158, 115, 255}, {240, 228, 66, 255},
+		{0, 114, 178, 255}, {213, 94, 0, 255}, {204, 121, 167, 255}, {153, 153, 153, 255},
+	}},
+	// Paul Tol's "bright" scheme, doesn't rely on the brightness of reds (protanopia).
+	{Name: "palette.protanopia", Colors: []color.RGBA{
+		{68, 119, 170, 255}, {238, 102, 119, 255}, {34, 136, 51, 255}, {204, 187, 68, 255},
+		{102, 204, 238, 255}, {170, 51, 119, 255}, {187, 187, 187, 255}, {255, 255, 255, 255},
+	}},
+	// Avoids blue/green and yellow/pink pairs (tritanopia), mostly red/teal plus brightness steps.
+	{Name: "palette.tritanopia", Colors: []color.RGBA{
+		{228, 26, 28, 255}, {0, 158, 160, 255}, {255, 170, 187, 255}, {255, 255, 255, 255},
+		{140, 0, 60, 255}, {120, 220, 220, 255}, {128, 128, 128, 255}, {0, 90, 90, 255},
+	}},
+}
+
+// Returns the active palette.
+func (app *App) Palette() Palette {
+	return palettes[app.Settings.Palette%len(palettes)]
+}
+
+// Returns the i-th color of the active palette (wrapping around).
+func (app *App) PaletteColor(i int) color.RGBA {
+	colors := app.Palette().Colors
+	return colors[i%len(colors)]
+}
+
+// Returns the position of a color in the active palette, or -1.
+func (app *App) paletteIndex(c color.RGBA) int {
+	for i, pc := range app.Palette().Colors {
+		if pc == opaque(c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Switches to the next palette.
+// Vertices colored from the old palette keep their slot, so colorings stay meaningful. The recoloring
+// undoes in one step; the palette stays.
+func (app *App) CyclePalette() {
+	old := app.Palette()
+	app.Settings.Palette = (app.Settings.Palette + 1) % len(palettes)
+	app.BeginTransaction(T("undo.palette"))
+	for i, v := range app.Graph.Vertices {
+		for slot, c := range old.Colors {
+			if opaque(v.Color) == c {
+				if recolored := withOpacity(app.PaletteColor(slot), v.Color.A); recolored != v.Color {
+					app.Do(Action{Kind: ActionColorVertex, V1: i, Color: recolored})
+				}
+				break
+			}
+		}
+	}
+	app.Commit()
+	if err := app.Settings.Save(); err != nil {
+		app.Warn(T("warn.save_settings", err))
+	}
+	msg := T("announce.palette", T(app.Palette().Name))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Returns the color the Color Vertex tool gives a vertex: the palette color after its current one,
+// at the same opacity.
+func (app *App) nextVertexColor(c color.RGBA) color.RGBA {
+	return withOpacity(app.PaletteColor(app.paletteIndex(c)+1), c.A)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// Shortest paths:
+
+// The Shortest Path tool picks two vertices and highlights a shortest path from the first to the
+// second, with the distance so far under each of its vertices and the total length on screen.
+// Without edge weights the path has the fewest edges (breadth first search); once weights are set it
+// has the least total weight (Dijkstra, which needs them non-negative). Directed edges are followed
+// their way only. The next click clears the highlight.
+
+// Returns a shortest path from one vertex to another and its length, ok is false if there's none.
+func (g *Graph) ShortestPath(from, to int) (path []int, length float64, ok bool) {
+	n := len(g.Vertices)
+	dist := make([]float64, n)
+	prev := make([]int, n)
+	for i := range dist {
+		dist[i], prev[i] = math.Inf(1), -1
+	}
+	dist[from] = 0
+
+	if len(g.EdgeWeights) == 0 {
+		queue := []int{from}
+		for len(queue) > 0 && math.IsInf(dist[to], 1) {
+			v := queue[0]
+			queue = queue[1:]
+			for w, count := range g.AdjMatrix[v] {
+				if count > 0 && math.IsInf(dist[w], 1) {
+					dist[w], prev[w] = dist[v]+1, v
+					queue = append(queue, w)
+				}
+			}
+		}
+	} else {
+		// Picks the closest unsettled vertex each round, fine on an adjacency matrix
+		settled := make([]bool, n)
+		for {
+			v := -1
+			for i := range n {
+				if !settled[i] && !math.IsInf(dist[i], 1) && (v < 0 || dist[i] < dist[v]) {
+					v = i
+				}
+			}
+			if v < 0 || v == to {
+				break
+			}
+			settled[v] = true
+			for w, count := range g.AdjMatrix[v] {
+				if d := dist[v] + g.EdgeWeight(v, w); count > 0 && !settled[w] && d < dist[w] {
+					dist[w], prev[w] = d, v
+				}
+			}
+		}
+	}
+
+	if math.IsInf(dist[to], 1) {
+		return nil, 0, false
+	}
+	for v := to; v >= 0; v = prev[v] {
+		path = append([]int{v}, path...)
+	}
+	return path, dist[to], true
+}
+
+// Reports whether some edge has a negative weight.
+func (g *Graph) hasNegativeWeight() bool {
+	for e, w := range g.EdgeWeights {
+		if w < 0 && g.Multiplicity(e.A, e.B)+g.Multiplicity(e.B, e.A) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Picks a vertex as an end of the path: the start if none is picked yet, else the end, then shows
+// the path. Picking a start clears the last path.
+func (app *App) pickPathEnd(i int) {
+	if app.EdgeStart == nil {
+		app.StopAnimation()
+		app.EdgeStart = &i
+		app.Announce(T("announce.path_start", app.Graph.Vertices[i].Label))
+		return
+	}
+	from := *app.EdgeStart
+	app.EdgeStart = nil
+	app.ShowShortestPath(from, i)
+}
+
+// Highlights a shortest path between two vertices and tells its length.
+func (app *App) ShowShortestPath(from, to int) {
+	g := app.Graph
+	if g.hasNegativeWeight() {
+		app.Warn(T("path.negative"))
+		return
+	}
+	path, length, ok := g.ShortestPath(from, to)
+	if !ok {
+		app.Warn(T("path.none", g.Vertices[from].Label, g.Vertices[to].Label))
+		return
+	}
+	step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: map[EdgeKey]bool{}, Labels: map[int]string{}}
+	distance := 0.0
+	for k, v := range path {
+		if k > 0 {
+			step.Edges[Edge(path[k-1], v)] = true
+			if len(g.EdgeWeights) > 0 {
+				distance += g.EdgeWeight(path[k-1], v)
+			} else {
+				distance++
+			}
+		}
+		step.Vertices[v] = highlightEdgeColor
+		step.Labels[v] = formatMetric(distance)
+	}
+	step.Text = T("path.text", formatMetric(length), g.pathText(path))
+	app.PlayAnimation(&Animation{Title: T("path.title"), Steps: []AnimationStep{step}})
+	app.Notify(step.Text)
+}
@@ -0,0 +1,29 @@
+package main
+
+// Pen tool:
+
+// Each click adds a vertex connected to the previous one, so paths and cycles take one click per vertex.
+// Clicking an existing vertex connects to it and continues from there (that's how a cycle gets closed).
+// Right click or Escape ends the chain.
+
+// Extends the pen chain to vertex i, or to a new vertex at (x, y) if i is -1.
+func (app *App) PenTo(i int, x, y float64) {
+	if i < 0 {
+		n := len(app.Graph.Vertices)
+		app.AddVertexAt(x, y)
+		if len(app.Graph.Vertices) == n {
+			return // Adding failed
+		}
+		i = n
+	}
+	if app.PenLast != nil && *app.PenLast != i {
+		app.Do(Action{Kind: ActionAddEdge, V1: *app.PenLast, V2: i})
+	}
+	app.PenLast = &i
+}
+
+// Stops the current pen chain, the next click starts a new one.
+func (app *App) EndPenChain() {
+	app.PenLast = nil
+	app.Announce(T("announce.pen_end"))
+}
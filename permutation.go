@@ -0,0 +1,108 @@
+package main
+
+// Permutation graphs:
+
+// A permutation graph has its vertices on two parallel lines, once on each, with an edge exactly
+// when their segments cross. It's one exactly when both the graph and its complement can be oriented
+// transitively (they're comparability graphs). The two orientations together then order the top line,
+// and with the graph's one reversed, the bottom line.
+//
+// Orientations are found a class of forced edges at a time (Golumbic): orienting a→b forces a→c when
+// b and c aren't adjacent, and c→b when a and c aren't, among the edges still unoriented. A class forcing
+// some edge both ways means there's no transitive orientation. The model is checked before it's returned.
+
+// Orients the graph given by adj transitively: out[a][b] is set for a→b. ok is false if it can't be done.
+func transitiveOrientation(n int, adj func(a, b int) bool) (out [][]bool, ok bool) {
+	left := make([][]bool, n) // Edges not oriented yet
+	out = make([][]bool, n)
+	for a := range n {
+		left[a], out[a] = make([]bool, n), make([]bool, n)
+		for b := range n {
+			left[a][b] = adj(a, b)
+		}
+	}
+	for a := range n {
+		for b := range n {
+			if !left[a][b] {
+				continue
+			}
+			// The class forced by a→b among the edges left
+			class := [][2]int{{a, b}}
+			in := map[[2]int]bool{{a, b}: true}
+			for k := 0; k < len(class); k++ {
+				x, y := class[k][0], class[k][1]
+				var forced [][2]int
+				for z := range n {
+					if z != x && z != y && left[x][z] && !left[y][z] {
+						forced = append(forced, [2]int{x, z})
+					}
+					if z != x && z != y && left[z][y] && !left[x][z] {
+						forced = append(forced, [2]int{z, y})
+					}
+				}
+				for _, arc := range forced {
+					if in[[2]int{arc[1], arc[0]}] {
+						return nil, false
+					}
+					if !in[arc] {
+						in[arc] = true
+						class = append(class, arc)
+					}
+				}
+			}
+			for _, arc := range class {
+				out[arc[0]][arc[1]] = true
+				left[arc[0]][arc[1]], left[arc[1]][arc[0]] = false, false
+			}
+		}
+	}
+	// Check transitivity, the orientation is only trusted once it passes
+	for a := range n {
+		for b := range n {
+			for c := range n {
+				if out[a][b] && out[b][c] && !out[a][c] {
+					return nil, false
+				}
+			}
+		}
+	}
+	return out, true
+}
+
+// Returns where each vertex is on the top and bottom line of a permutation diagram,
+// and whether the graph is a permutation graph.
+func (g *Graph) PermutationModel() (top, bottom []int, ok bool) {
+	if !g.IsSimple() {
+		return nil, nil, false
+	}
+	n := len(g.Vertices)
+	edges, ok := transitiveOrientation(n, g.adjacent)
+	if !ok {
+		return nil, nil, false
+	}
+	others, ok := transitiveOrientation(n, func(a, b int) bool { return a != b && !g.adjacent(a, b) })
+	if !ok {
+		return nil, nil, false
+	}
+	// Every pair is ordered one way; a vertex's place is the number of vertices before it
+	top, bottom = make([]int, n), make([]int, n)
+	for a := range n {
+		for b := range n {
+			if edges[b][a] || others[b][a] {
+				top[a]++
+			}
+			if edges[a][b] || others[b][a] {
+				bottom[a]++
+			}
+		}
+	}
+	for a := range n {
+		for b := a + 1; b < n; b++ {
+			crossing := (top[a] < top[b]) != (bottom[a] < bottom[b])
+			if top[a] == top[b] || bottom[a] == bottom[b] || crossing != g.adjacent(a, b) {
+				return nil, nil, false
+			}
+		}
+	}
+	return top, bottom, true
+}
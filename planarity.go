@@ -0,0 +1,296 @@
+package main
+
+// Planarity:
+
+// A graph is planar when each of its biconnected blocks is, and loops and parallel edges never matter.
+// Each block is tested by embedding it a piece at a time (Demoucron, Malgrange and Pertuiset):
+// start from a cycle, whose two faces are the inside and the outside, then keep finding the fragments
+// of the block not embedded yet (chords between embedded vertices, or pieces hanging from them) and the
+// faces whose boundary has all of a fragment's attachments. A fragment fitting no face means the block
+// isn't planar. Otherwise a path through a fragment that fits only one face (or any, if all fit several)
+// is drawn across such a face, splitting it in two. In a biconnected plane graph every face is bounded
+// by a cycle, so faces are kept as vertex cycles.
+
+// Reports whether the graph can be drawn without crossing edges.
+func (g *Graph) IsPlanar() bool {
+	for _, block := range g.blocks() {
+		if !g.planarBlock(block) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the edges of each biconnected block (bridges are blocks of one edge), loops and parallels left out.
+func (g *Graph) blocks() [][]EdgeKey {
+	n := len(g.Vertices)
+	disc, low := make([]int, n), make([]int, n)
+	time := 0
+	var stack []EdgeKey
+	var blocks [][]EdgeKey
+
+	var visit func(v, parent int)
+	visit = func(v, parent int) {
+		time++
+		disc[v], low[v] = time, time
+		for w := range g.Vertices {
+			if !g.adjacent(v, w) || w == parent {
+				continue
+			}
+			if disc[w] == 0 {
+				stack = append(stack, Edge(v, w))
+				visit(w, v)
+				low[v] = min(low[v], low[w])
+				if low[w] >= disc[v] { // v separates w's subtree: pop its block
+					var block []EdgeKey
+					for {
+						e := stack[len(stack)-1]
+						stack = stack[:len(stack)-1]
+						block = append(block, e)
+						if e == Edge(v, w) {
+							break
+						}
+					}
+					blocks = append(blocks, block)
+				}
+			} else if disc[w] < disc[v] {
+				stack = append(stack, Edge(v, w))
+				low[v] = min(low[v], disc[w])
+			}
+		}
+	}
+	for v := range g.Vertices {
+		if disc[v] == 0 {
+			visit(v, -1)
+		}
+	}
+	return blocks
+}
+
+// A piece of the block not embedded yet.
+type fragment struct {
+	attach []int // Embedded vertices it touches
+	path   []int // A path through it between two of them, ends included
+}
+
+// Tests one biconnected block.
+func (g *Graph) planarBlock(block []EdgeKey) bool {
+	var vertices []int
+	seen := map[int]bool{}
+	adj := map[int][]int{}
+	for _, e := range block {
+		for _, v := range []int{e.A, e.B} {
+			if !seen[v] {
+				seen[v] = true
+				vertices = append(vertices, v)
+			}
+		}
+		adj[e.A] = append(adj[e.A], e.B)
+		adj[e.B] = append(adj[e.B], e.A)
+	}
+	nv, ne := len(vertices), len(block)
+	if ne < 3 || ne <= nv { // An edge, or a cycle
+		return true
+	}
+	if ne > 3*nv-6 { // Euler's formula
+		return false
+	}
+
+	embeddedV := map[int]bool{}
+	embeddedE := map[EdgeKey]bool{}
+	cycle := findCycle(vertices[0], adj)
+	for i, v := range cycle {
+		embeddedV[v] = true
+		embeddedE[Edge(v, cycle[(i+1)%len(cycle)])] = true
+	}
+	faces := [][]int{cycle, append([]int(nil), cycle...)}
+
+	for len(embeddedE) < ne {
+		fragments := findFragments(adj, vertices, embeddedV, embeddedE)
+		var pick *fragment
+		face := -1
+		for i := range fragments {
+			fits := facesHolding(faces, fragments[i].attach)
+			if len(fits) == 0 {
+				return false
+			}
+			if pick == nil || len(fits) == 1 {
+				pick, face = &fragments[i], fits[0]
+				if len(fits) == 1 {
+					break
+				}
+			}
+		}
+		path := pick.path
+		for i, v := range path {
+			embeddedV[v] = true
+			if i > 0 {
+				embeddedE[Edge(path[i-1], v)] = true
+			}
+		}
+		// The face's boundary from one end of the path to the other then the path back, and the rest of it
+		a, b, inner := path[0], path[len(path)-1], path[1:len(path)-1]
+		old := faces[face]
+		faces[face] = faceHalf(old, a, b, reversed(inner))
+		faces = append(faces, faceHalf(old, b, a, inner))
+	}
+	return true
+}
+
+// Returns a cycle found searching from start; the block is biconnected, so there is one.
+func findCycle(start int, adj map[int][]int) []int {
+	parent := map[int]int{start: -1}
+	var cycle []int
+	var dfs func(v int) bool
+	dfs = func(v int) bool {
+		for _, w := range adj[v] {
+			if w == parent[v] {
+				continue
+			}
+			if _, ok := parent[w]; ok { // Back edge: the cycle is the tree path from w to v
+				for u := v; u != w; u = parent[u] {
+					cycle = append(cycle, u)
+				}
+				cycle = append(cycle, w)
+				return true
+			}
+			parent[w] = v
+			if dfs(w) {
+				return true
+			}
+		}
+		return false
+	}
+	dfs(start)
+	return cycle
+}
+
+// Finds the fragments of the block: edges between embedded vertices that aren't embedded,
+// and the components of what isn't embedded with the edges linking them to embedded vertices.
+func findFragments(adj map[int][]int, vertices []int, embeddedV map[int]bool, embeddedE map[EdgeKey]bool) []fragment {
+	var fragments []fragment
+	for _, v := range vertices {
+		if !embeddedV[v] {
+			continue
+		}
+		for _, w := range adj[v] {
+			if v < w && embeddedV[w] && !embeddedE[Edge(v, w)] {
+				fragments = append(fragments, fragment{attach: []int{v, w}, path: []int{v, w}})
+			}
+		}
+	}
+
+	done := map[int]bool{}
+	for _, start := range vertices {
+		if embeddedV[start] || done[start] {
+			continue
+		}
+		// Collect the component and its attachments
+		component := []int{start}
+		done[start] = true
+		attached := map[int]bool{}
+		var attach []int
+		for k := 0; k < len(component); k++ {
+			for _, w := range adj[component[k]] {
+				switch {
+				case embeddedV[w]:
+					if !attached[w] {
+						attached[w] = true
+						attach = append(attach, w)
+					}
+				case !done[w]:
+					done[w] = true
+					component = append(component, w)
+				}
+			}
+		}
+		fragments = append(fragments, fragment{attach: attach, path: fragmentPath(adj, component, attach, embeddedV)})
+	}
+	return fragments
+}
+
+// Returns a path from one attachment through the component to another attachment.
+func fragmentPath(adj map[int][]int, component, attach []int, embeddedV map[int]bool) []int {
+	a := attach[0]
+	inside := map[int]bool{}
+	for _, v := range component {
+		inside[v] = true
+	}
+	var start int
+	for _, w := range adj[a] {
+		if inside[w] {
+			start = w
+			break
+		}
+	}
+	parent := map[int]int{start: -1}
+	queue := []int{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range adj[v] {
+			if embeddedV[w] && w != a { // Reached another attachment
+				path := []int{w}
+				for u := v; u != -1; u = parent[u] {
+					path = append(path, u)
+				}
+				return append(path, a)
+			}
+			if _, ok := parent[w]; inside[w] && !ok {
+				parent[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil // Can't happen in a biconnected block: a alone doesn't separate the component
+}
+
+// Returns the faces whose boundary has all the vertices.
+func facesHolding(faces [][]int, vertices []int) []int {
+	var fits []int
+	for i, face := range faces {
+		on := map[int]bool{}
+		for _, v := range face {
+			on[v] = true
+		}
+		if subsetOf(vertices, on) {
+			fits = append(fits, i)
+		}
+	}
+	return fits
+}
+
+func subsetOf(s []int, set map[int]bool) bool {
+	for _, x := range s {
+		if !set[x] {
+			return false
+		}
+	}
+	return true
+}
+
+// Walks a face's boundary from a to b, then appends the inner vertices of the way back.
+func faceHalf(face []int, a, b int, back []int) []int {
+	i := 0
+	for face[i] != a {
+		i++
+	}
+	var half []int
+	for {
+		v := face[i%len(face)]
+		half = append(half, v)
+		if v == b {
+			break
+		}
+		i++
+	}
+	return append(half, back...)
+}
+
+func reversed(s []int) []int {
+	r := make([]int, len(s))
+	for i, x := range s {
+		r[len(s)-1-i] = x
+	}
+	return r
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Planarize:
+//
+//	Shift+P: redraw the graph without crossing edges, or show why that's impossible.
+//
+// A planar graph is first completed to a triangulation, adding edges one at a time as long as it stays
+// planar (only while computing, the graph keeps its edges). A triangulation is 3-connected, so nailing
+// one of its triangular faces to a big triangle and putting every other vertex at the average of its
+// neighbors (Tutte's barycentric embedding) draws it with straight edges and no crossings, and the
+// graph's edges are among them. That drawing is then spread out by springs that never let an edge
+// cross another, and the vertices move there (one undo step). A graph that isn't planar
+// contains a subdivision of K5 or K3,3 (Kuratowski's theorem): dropping edges one by one as long as
+// what's left still isn't planar leaves just such a subdivision, which is highlighted with its branch
+// vertices marked, until Escape. Both run in the background, up to planarizeMaxVertices vertices.
+// Loops, parallel edges and directions don't matter.
+
+const (
+	planarizeMaxVertices = 100
+	planarRelaxSteps     = 300 // Of spreading the drawing out
+)
+
+// Returns the graph without loops, parallel edges or directions, vertex indices kept. Only its
+// adjacency is set, enough for the planarity test.
+func (g *Graph) simpleSkeleton() *Graph {
+	n := len(g.Vertices)
+	s := &Graph{Vertices: make([]Vertex, n), AdjMatrix: make([][]int, n)}
+	for i := range n {
+		s.AdjMatrix[i] = make([]int, n)
+		for j := range n {
+			if i != j && g.Multiplicity(i, j) > 0 {
+				s.AdjMatrix[i][j] = 1
+			}
+		}
+	}
+	return s
+}
+
+// Sets or clears the edge between i and j of a skeleton (simpleSkeleton).
+func (g *Graph) setSkeletonEdge(i, j int, on bool) {
+	k := 0
+	if on {
+		k = 1
+	}
+	g.AdjMatrix[i][j], g.AdjMatrix[j][i] = k, k
+}
+
+// Returns the edges of a subdivision of K5 or K3,3 in the graph and its branch vertices (five of
+// degree 4, or six of degree 3), nil if the graph is planar.
+func (g *Graph) Kuratowski() (edges []EdgeKey, branch []int) {
+	s := g.simpleSkeleton()
+	if s.IsPlanar() {
+		return nil, nil
+	}
+	n := len(s.Vertices)
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			if s.AdjMatrix[i][j] == 0 {
+				continue
+			}
+			s.setSkeletonEdge(i, j, false)
+			if s.IsPlanar() { // Needed
+				s.setSkeletonEdge(i, j, true)
+			}
+		}
+	}
+	for i := range n {
+		degree := 0
+		for j := i + 1; j < n; j++ {
+			if s.AdjMatrix[i][j] > 0 {
+				edges = append(edges, Edge(i, j))
+			}
+		}
+		for j := range n {
+			degree += s.AdjMatrix[i][j]
+		}
+		if degree >= 3 {
+			branch = append(branch, i)
+		}
+	}
+	return edges, branch
+}
+
+// Completes a planar skeleton to a triangulation: adds every edge that keeps it planar, first between
+// vertices with a common neighbor (usually all it takes), then between any two.
+func (g *Graph) triangulate() {
+	n := len(g.Vertices)
+	edges := 0
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			edges += g.AdjMatrix[i][j]
+		}
+	}
+	for _, nearOnly := range []bool{true, false} {
+		for i := range n {
+			for j := i + 1; j < n && edges < 3*n-6; j++ {
+				if g.AdjMatrix[i][j] > 0 || nearOnly && !g.commonNeighbor(i, j) {
+					continue
+				}
+				g.setSkeletonEdge(i, j, true)
+				if g.IsPlanar() {
+					edges++
+				} else {
+					g.setSkeletonEdge(i, j, false)
+				}
+			}
+		}
+	}
+}
+
+// Reports whether i and j of a skeleton have a common neighbor.
+func (g *Graph) commonNeighbor(i, j int) bool {
+	for k := range g.Vertices {
+		if g.AdjMatrix[i][k] > 0 && g.AdjMatrix[j][k] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a triangle of a triangulation that is one of its faces: the rest stays connected without it.
+func (g *Graph) outerFace() [3]int {
+	n := len(g.Vertices)
+	for a := range n {
+		for b := a + 1; b < n; b++ {
+			if g.AdjMatrix[a][b] == 0 {
+				continue
+			}
+			for c := b + 1; c < n; c++ {
+				if g.AdjMatrix[a][c] > 0 && g.AdjMatrix[b][c] > 0 && g.connectedWithout(a, b, c) {
+					return [3]int{a, b, c}
+				}
+			}
+		}
+	}
+	return [3]int{0, 1, 2}
+}
+
+// Reports whether the vertices of a skeleton other than a, b and c are connected among themselves.
+func (g *Graph) connectedWithout(a, b, c int) bool {
+	n := len(g.Vertices)
+	seen := map[int]bool{a: true, b: true, c: true}
+	start := 0
+	for seen[start] {
+		start++
+	}
+	seen[start] = true
+	queue := []int{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for w := range n {
+			if g.AdjMatrix[v][w] > 0 && !seen[w] {
+				seen[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	return len(seen) == n
+}
+
+// Returns positions drawing the graph without crossings inside the circle of radius r around
+// (cx, cy), ok is false if it isn't planar.
+func (g *Graph) PlanarLayout(cx, cy, r float64) (positions []point, ok bool) {
+	n := len(g.Vertices)
+	s := g.simpleSkeleton()
+	if !s.IsPlanar() {
+		return nil, false
+	}
+	positions = make([]point, n)
+	corner := func(k int) point {
+		angle := -math.Pi/2 + float64(k)*2*math.Pi/3
+		return point{cx + r*math.Cos(angle), cy + r*math.Sin(angle)}
+	}
+	if n <= 3 {
+		for i := range n {
+			positions[i] = corner(i)
+		}
+		return positions, true
+	}
+	var edges [][2]int // The graph's own, before triangulating
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			if s.AdjMatrix[i][j] > 0 {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+	}
+	s.triangulate()
+	outer := s.outerFace()
+	fixed := map[int]bool{}
+	for k, v := range outer {
+		positions[v], fixed[v] = corner(k), true
+	}
+
+	// Each inner vertex at the average of its neighbors: deg(v)·p(v) - Σ inner neighbors = Σ outer ones
+	var inner []int
+	row := make([]int, n)
+	for v := range n {
+		if !fixed[v] {
+			row[v] = len(inner)
+			inner = append(inner, v)
+		}
+	}
+	m := len(inner)
+	a := make([][]float64, m)
+	for k, v := range inner {
+		a[k] = make([]float64, m+2) // Right-hand sides for x and y last
+		for w := range n {
+			if s.AdjMatrix[v][w] == 0 {
+				continue
+			}
+			a[k][k]++
+			if fixed[w] {
+				a[k][m] += positions[w].X
+				a[k][m+1] += positions[w].Y
+			} else {
+				a[k][row[w]]--
+			}
+		}
+	}
+	solveLinear(a)
+	for k, v := range inner {
+		positions[v] = point{a[k][m], a[k][m+1]}
+	}
+	relaxPlanar(positions, edges, cx, cy, r)
+
+	// Scaled down into the circle if it grew out of it, which keeps it free of crossings
+	far := 0.0
+	for _, p := range positions {
+		far = math.Max(far, math.Hypot(p.X-cx, p.Y-cy))
+	}
+	if far > r {
+		for i, p := range positions {
+			positions[i] = point{cx + (p.X-cx)*r/far, cy + (p.Y-cy)*r/far}
+		}
+	}
+	return positions, true
+}
+
+// Spreads out a drawing without crossings, which Tutte's embedding crowds towards the middle: spring
+// forces as in the force-directed layout (layout.go), with a pull towards (cx, cy), cooling down over
+// planarRelaxSteps steps, but a vertex only moves where its edges cross no others, halving the move
+// until they don't.
+func relaxPlanar(positions []point, edges [][2]int, cx, cy, r float64) {
+	n := len(positions)
+	incident := make([][]int, n) // Edges at each vertex, by index
+	for k, e := range edges {
+		incident[e[0]] = append(incident[e[0]], k)
+		incident[e[1]] = append(incident[e[1]], k)
+	}
+	// Reports whether the edges at v cross none of the others with v at p
+	clear := func(v int, p point) bool {
+		for _, k := range incident[v] {
+			w := edges[k][0] + edges[k][1] - v
+			for _, f := range edges {
+				if f[0] == v || f[1] == v || f[0] == w || f[1] == w {
+					continue
+				}
+				if segmentsCross(p, positions[w], positions[f[0]], positions[f[1]]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	k := float64(layoutSpacing)
+	for step := range planarRelaxSteps {
+		maxMove := r / 10 * float64(planarRelaxSteps-step) / planarRelaxSteps
+		for v := range n {
+			var dx, dy float64
+			for w := range n {
+				if w == v {
+					continue
+				}
+				ex, ey := positions[v].X-positions[w].X, positions[v].Y-positions[w].Y
+				d := math.Max(math.Hypot(ex, ey), 0.01)
+				dx, dy = dx+ex/d*k*k/d, dy+ey/d*k*k/d
+			}
+			for _, e := range incident[v] {
+				w := edges[e][0] + edges[e][1] - v
+				ex, ey := positions[v].X-positions[w].X, positions[v].Y-positions[w].Y
+				d := math.Hypot(ex, ey)
+				dx, dy = dx-ex*d/k, dy-ey*d/k
+			}
+			dx, dy = dx-(positions[v].X-cx)*layoutGravity, dy-(positions[v].Y-cy)*layoutGravity
+			d := math.Hypot(dx, dy)
+			if d < 1e-9 {
+				continue
+			}
+			move := math.Min(d, maxMove)
+			for range 4 {
+				p := point{positions[v].X + dx/d*move, positions[v].Y + dy/d*move}
+				if clear(v, p) {
+					positions[v] = p
+					break
+				}
+				move /= 2
+			}
+		}
+	}
+}
+
+// Reports whether the segments ab and cd cross at a point inside both.
+func segmentsCross(a, b, c, d point) bool {
+	side := func(p, q, r point) float64 { return (q.X-p.X)*(r.Y-p.Y) - (q.Y-p.Y)*(r.X-p.X) }
+	return side(a, b, c)*side(a, b, d) < 0 && side(c, d, a)*side(c, d, b) < 0
+}
+
+// Solves a linear system in place by Gauss-Jordan elimination with partial pivoting: each row holds
+// the coefficients then the right-hand sides, which end up holding the solutions.
+func solveLinear(a [][]float64) {
+	m := len(a)
+	for col := range m {
+		pivot := col
+		for r := col + 1; r < m; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		p := a[col][col]
+		for c := range a[col] {
+			a[col][c] /= p
+		}
+		for r := range m {
+			if f := a[r][col]; r != col && f != 0 {
+				for c := range a[r] {
+					a[r][c] -= f * a[col][c]
+				}
+			}
+		}
+	}
+}
+
+// Result of Planarize, computed in the background.
+type planarizeResult struct {
+	positions []point   // nil if the graph isn't planar
+	edges     []EdgeKey // Kuratowski subdivision otherwise
+	branch    []int
+}
+
+// Redraws the graph without crossings, or highlights a Kuratowski subdivision.
+func (app *App) Planarize() {
+	n := len(app.Graph.Vertices)
+	if n == 0 {
+		return
+	}
+	if n > planarizeMaxVertices {
+		app.Warn(T("planarize.too_big", n, planarizeMaxVertices))
+		return
+	}
+	cx, cy := float64(screenWidth)/2, (canvasTop()+screenHeight)/2
+	r := float64(screenHeight-canvasTop())/2 - 40
+	Background(app, func(g *Graph) planarizeResult {
+		if positions, ok := g.PlanarLayout(cx, cy, r); ok {
+			return planarizeResult{positions: positions}
+		}
+		edges, branch := g.Kuratowski()
+		return planarizeResult{edges: edges, branch: branch}
+	}, func(app *App, result planarizeResult) {
+		if result.positions != nil {
+			app.BeginTransaction(T("undo.planarize"))
+			for i, p := range result.positions {
+				app.Do(Action{Kind: ActionMoveVertex, V1: i, X: p.X, Y: p.Y})
+			}
+			app.Commit()
+			app.Notify(T("planarize.done"))
+			app.Announce(T("planarize.done"))
+			return
+		}
+		step := app.kuratowskiHighlight(result.edges, result.branch)
+		app.PlayAnimation(&Animation{Title: T("planarize.not_planar"), Steps: []AnimationStep{step}})
+		app.Notify(step.Text)
+	})
+	app.Notify(T("planarize.working"))
+}
+
+// Highlights a Kuratowski subdivision: its edges, the vertices on its paths and, brighter, its branch
+// vertices. The text says which graph it subdivides.
+func (app *App) kuratowskiHighlight(edges []EdgeKey, branch []int) AnimationStep {
+	g := app.Graph
+	step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: map[EdgeKey]bool{}}
+	for _, e := range edges {
+		step.Edges[e] = true
+		step.Vertices[e.A], step.Vertices[e.B] = app.PaletteColor(2), app.PaletteColor(2)
+	}
+	labels := make([]string, len(branch))
+	for k, v := range branch {
+		step.Vertices[v] = highlightEdgeColor
+		labels[k] = g.Vertices[v].Label
+	}
+	kind := "K3,3"
+	if len(branch) == 5 {
+		kind = "K5"
+	}
+	step.Text = T("planarize.kuratowski", kind, strings.Join(labels, ", "))
+	return step
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Select by predicate:
+
+// "Select by..." (S, or the Select tool's option bar) selects every vertex matching a condition:
+// a minimum degree, a color, a label regular expression, or the value of an attribute column
+// (a comparison for numbers, yes or no for flags, a pattern otherwise). The result replaces the selection,
+// ready for bulk recoloring, deleting or extracting.
+
+const maxColorChoices = 9 // Most frequent colors offered, two rows of dialog buttons with Cancel
+
+// Replaces the selection with the vertices matching a predicate.
+func (app *App) SelectWhere(match func(i int) bool) {
+	app.Selection.Clear()
+	for i := range app.Graph.Vertices {
+		if match(i) {
+			app.Selection.AddVertex(i, false)
+		}
+	}
+	msg := T("announce.selected", app.selectionSummary())
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Opens a text field in the middle of the screen, for the predicate's parameter.
+func (app *App) askPredicate(prompt string, onCommit func(text string)) {
+	app.Notify(prompt)
+	app.OpenTextInput(&TextInput{X: screenWidth/2 - 30, Y: screenHeight / 2, OnCommit: onCommit})
+}
+
+// Asks for a minimum degree, then selects the vertices having it.
+func (app *App) selectByDegree() {
+	app.askPredicate(T("predicate.degree_prompt"), func(text string) {
+		k, err := strconv.Atoi(text)
+		if err != nil {
+			app.Warn(T("warn.not_a_number", text))
+			return
+		}
+		app.SelectWhere(func(i int) bool { return app.Graph.Degree(i) >= k })
+	})
+}
+
+// Asks for a label pattern, then selects the vertices whose label matches it.
+func (app *App) selectByLabel() {
+	app.askPredicate(T("predicate.label_prompt"), func(text string) {
+		re, err := regexp.Compile(text)
+		if err != nil {
+			app.Warn(T("warn.bad_pattern", err))
+			return
+		}
+		app.SelectWhere(func(i int) bool { return re.MatchString(app.Graph.Vertices[i].Label) })
+	})
+}
+
+// Offers the most common vertex colors, then selects the vertices having the one picked.
+func (app *App) selectByColor() {
+	counts := map[color.RGBA]int{}
+	for _, v := range app.Graph.Vertices {
+		counts[v.Color]++
+	}
+	var colors []color.RGBA
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if counts[colors[i]] != counts[colors[j]] {
+			return counts[colors[i]] > counts[colors[j]]
+		}
+		return app.paletteIndex(colors[i]) < app.paletteIndex(colors[j])
+	})
+
+	d := &Dialog{Message: T("predicate.color_prompt")}
+	for _, c := range colors[:min(len(colors), maxColorChoices)] {
+		label := fmt.Sprintf("#%02x%02x%02x (%d)", c.R, c.G, c.B, counts[c])
+		d.Buttons = append(d.Buttons, DialogButton{Label: label, Action: func() {
+			app.SelectWhere(func(i int) bool { return app.Graph.Vertices[i].Color == c })
+		}})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
+
+// Parses a number condition: "op value" with op one of = != < <= > >=, or just a value for =.
+func parseComparison(text string) (func(f float64) bool, error) {
+	text = strings.TrimSpace(text)
+	ops := []struct {
+		op   string
+		test func(a, b float64) bool
+	}{
+		{"<=", func(a, b float64) bool { return a <= b }},
+		{">=", func(a, b float64) bool { return a >= b }},
+		{"!=", func(a, b float64) bool { return a != b }},
+		{"<", func(a, b float64) bool { return a < b }},
+		{">", func(a, b float64) bool { return a > b }},
+		{"=", func(a, b float64) bool { return a == b }},
+	}
+	test := ops[len(ops)-1].test
+	for _, o := range ops {
+		if rest, ok := strings.CutPrefix(text, o.op); ok {
+			text, test = rest, o.test
+			break
+		}
+	}
+	b, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return nil, err
+	}
+	return func(a float64) bool { return test(a, b) }, nil
+}
+
+// Asks for a condition on an attribute column, then selects the vertices meeting it.
+func (app *App) selectByAttr(c AttrColumn) {
+	var texts []string // Evaluated on commit, computed columns can be slow
+	value := func(i int) string {
+		if texts == nil {
+			texts = app.Graph.columnValues(c)
+		}
+		return texts[i]
+	}
+	switch c.Type {
+	case AttrNumber:
+		app.askPredicate(T("predicate.number_prompt", c.Name), func(text string) {
+			test, err := parseComparison(text)
+			if err != nil {
+				app.Warn(T("warn.not_a_number", text))
+				return
+			}
+			app.SelectWhere(func(i int) bool { f := c.Number(value(i)); return !math.IsNaN(f) && test(f) })
+		})
+	case AttrBool:
+		flag := func(want float64) func() {
+			return func() { app.SelectWhere(func(i int) bool { return c.Number(value(i)) == want }) }
+		}
+		app.ShowDialog(&Dialog{
+			Message: T("predicate.flag_prompt", c.Name),
+			Buttons: []DialogButton{{Label: T("attr.yes"), Action: flag(1)}, {Label: T("attr.no"), Action: flag(0)}, {Label: T("dialog.cancel")}},
+		})
+	default:
+		app.askPredicate(T("predicate.pattern_prompt", c.Name), func(text string) {
+			re, err := regexp.Compile(text)
+			if err != nil {
+				app.Warn(T("warn.bad_pattern", err))
+				return
+			}
+			app.SelectWhere(func(i int) bool { return value(i) != "" && re.MatchString(c.Format(value(i))) })
+		})
+	}
+}
+
+// Offers the vertex attribute columns to select by.
+func (app *App) selectByAttrDialog() {
+	columns := app.Graph.Schema.columns(false)
+	d := &Dialog{Message: T("predicate.attr_prompt")}
+	for _, c := range columns[:min(len(columns), maxColorChoices)] {
+		d.Buttons = append(d.Buttons, DialogButton{Label: c.Name, Action: func() { app.selectByAttr(c) }})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
+
+// Asks what to select by.
+func (app *App) ShowSelectByDialog() {
+	if len(app.Graph.Vertices) == 0 {
+		return
+	}
+	buttons := []DialogButton{
+		{Label: T("predicate.degree"), Action: app.selectByDegree},
+		{Label: T("predicate.color"), Action: app.selectByColor},
+		{Label: T("predicate.label"), Action: app.selectByLabel},
+	}
+	if len(app.Graph.Schema.columns(false)) > 0 {
+		buttons = append(buttons, DialogButton{Label: T("predicate.attr"), Action: app.selectByAttrDialog})
+	}
+	app.ShowDialog(&Dialog{Message: T("predicate.dialog"), Buttons: append(buttons, DialogButton{Label: T("dialog.cancel")})})
+}
@@ -0,0 +1,208 @@
+package main
+
+// Graph properties:
+
+// Yes/no questions about the graph, shared by exercises, quizzes and the analysis commands.
+
+// Reports whether every vertex can reach every other one (true for the empty graph).
+func (g *Graph) IsConnected() bool {
+	for _, c := range g.Components() {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the number of connected components.
+func (g *Graph) ComponentCount() int {
+	count := 0
+	for _, c := range g.Components() {
+		count = max(count, c+1)
+	}
+	return count
+}
+
+// Reports whether the graph has no loops and no parallel edges.
+func (g *Graph) IsSimple() bool {
+	for i, row := range g.AdjMatrix {
+		for j, count := range row {
+			if count > 1 || (i == j && count > 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Returns the number of edges, parallel edges and loops each count once.
+func (g *Graph) EdgeCount() int {
+	total := 0
+	for i, row := range g.AdjMatrix {
+		for j := i; j < len(row); j++ {
+			if g.Directed && j != i {
+				total += g.AdjMatrix[j][i]
+			}
+			total += row[j]
+		}
+	}
+	return total
+}
+
+// Splits the vertices into two sides with every edge going across, whichever way it goes.
+// Returns the side (0 or 1) of each vertex, or ok false if that's impossible (the graph has an odd cycle).
+func (g *Graph) Bipartition() (side []int, ok bool) {
+	side = make([]int, len(g.Vertices))
+	for i := range side {
+		side[i] = -1
+	}
+	for start := range g.Vertices {
+		if side[start] >= 0 {
+			continue
+		}
+		side[start] = 0
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for w := range g.Vertices {
+				if g.Multiplicity(v, w) == 0 {
+					continue
+				}
+				if side[w] < 0 {
+					side[w] = 1 - side[v]
+					queue = append(queue, w)
+				} else if side[w] == side[v] {
+					return nil, false
+				}
+			}
+		}
+	}
+	return side, true
+}
+
+// Returns the smallest number of colors needed so that no edge joins two vertices of the same color,
+// along with such a coloring. Exact branch and bound (colorgraph.go), fine for classroom sized graphs;
+// chordal graphs are colored straight from their elimination ordering (chordal.go).
+// A graph with a loop can't be colored at all, that's reported as ok false.
+func (g *Graph) ChromaticNumber() (k int, coloring []int, ok bool) {
+	if g.hasLoop() {
+		return 0, nil, false
+	}
+	if peo, chordal := g.PerfectEliminationOrder(); chordal {
+		k, coloring = g.chordalColoring(peo)
+		return k, coloring, true
+	}
+	k, coloring = g.exactColoring()
+	return k, coloring, true
+}
+
+// Reports whether the graph has a closed walk using every edge exactly once, along the edge directions
+// in directed mode: connected (ignoring isolated vertices) and every degree even, or in directed mode
+// every in-degree equal to the out-degree.
+func (g *Graph) IsEulerian() bool {
+	if len(g.eulerianUnbalanced()) > 0 {
+		return false
+	}
+	component, start := g.Components(), -1
+	for i := range g.Vertices {
+		if g.Degree(i)+g.InDegree(i) > 0 {
+			if start < 0 {
+				start = component[i]
+			} else if component[i] != start {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Returns the vertices of an odd cycle in order (a loop is a cycle of length 1), nil if there is none.
+// Edge directions are ignored.
+// Found by two-coloring breadth first: an edge inside one color closes an odd cycle through the search tree.
+func (g *Graph) OddCycle() []int {
+	n := len(g.Vertices)
+	for i := range n {
+		if g.AdjMatrix[i][i] > 0 {
+			return []int{i}
+		}
+	}
+	side, parent, depth := make([]int, n), make([]int, n), make([]int, n)
+	for i := range side {
+		side[i] = -1
+	}
+	for start := range n {
+		if side[start] >= 0 {
+			continue
+		}
+		side[start], parent[start] = 0, -1
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for w := range g.Vertices {
+				if g.Multiplicity(v, w) == 0 {
+					continue
+				}
+				if side[w] < 0 {
+					side[w], parent[w], depth[w] = 1-side[v], v, depth[v]+1
+					queue = append(queue, w)
+					continue
+				}
+				if side[w] != side[v] {
+					continue
+				}
+				// Walk both ends up to their common ancestor
+				var left, right []int
+				a, b := v, w
+				for a != b {
+					if depth[a] >= depth[b] {
+						left, a = append(left, a), parent[a]
+					} else {
+						right, b = append(right, b), parent[b]
+					}
+				}
+				cycle := append(left, a)
+				for k := len(right) - 1; k >= 0; k-- {
+					cycle = append(cycle, right[k])
+				}
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a closed walk using every edge exactly once, as a vertex sequence (first = last), along the
+// edge directions in directed mode. nil if the graph isn't Eulerian or has no edges (eulerian.go).
+func (g *Graph) EulerianCircuit() []int {
+	if trail, closed := g.EulerianTrail(); closed {
+		return trail
+	}
+	return nil
+}
+
+// Returns the edges of a breadth first spanning forest, one tree per component. Edge directions are ignored.
+func (g *Graph) SpanningForest() [][2]int {
+	seen := make([]bool, len(g.Vertices))
+	var edges [][2]int
+	for start := range g.Vertices {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		queue := []int{start}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for w := range g.Vertices {
+				if g.Multiplicity(v, w) > 0 && !seen[w] {
+					seen[w] = true
+					edges = append(edges, [2]int{v, w})
+					queue = append(queue, w)
+				}
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// Quiz mode:
+
+// F3 asks questions about a graph ("Is this graph bipartite?", "What is its chromatic number?")
+// and checks every answer with the algorithms in properties.go.
+// The graph is either the current drawing or a fresh random one.
+
+const (
+	quizQuestions = 5 // Questions per round
+	quizVertices  = 7 // Size of random quiz graphs
+	quizDensity   = 0.35
+)
+
+type QuizQuestion struct {
+	Text    string // Locale key
+	Answer  func(g *Graph) string
+	Choices func(g *Graph) []string     // Answer buttons, the right answer among them
+	Certify func(app *App) *Certificate // Proof highlighted once answered, nil if none
+}
+
+type Quiz struct {
+	Asked, Correct int
+	questions      []QuizQuestion // Left to ask, in order
+}
+
+// Yes/no answer.
+func yesNo(b bool) string {
+	if b {
+		return T("dialog.yes")
+	}
+	return T("dialog.no")
+}
+
+func yesNoChoices(g *Graph) []string {
+	return []string{T("dialog.yes"), T("dialog.no")}
+}
+
+// Numbers around the right answer (never below zero).
+func numberChoices(answer int) []string {
+	lo := max(0, answer-rand.Intn(3))
+	var choices []string
+	for n := lo; n < lo+4; n++ {
+		choices = append(choices, strconv.Itoa(n))
+	}
+	return choices
+}
+
+var quizBank = []QuizQuestion{
+	{Text: "quiz.bipartite", Choices: yesNoChoices, Certify: (*App).certifyBipartite, Answer: func(g *Graph) string {
+		_, ok := g.Bipartition()
+		return yesNo(ok)
+	}},
+	{Text: "quiz.connected", Choices: yesNoChoices, Certify: (*App).certifyConnected,
+		Answer: func(g *Graph) string { return yesNo(g.IsConnected()) }},
+	{Text: "quiz.tree", Choices: yesNoChoices, Answer: func(g *Graph) string {
+		return yesNo(g.IsConnected() && g.IsSimple() && g.EdgeCount() == len(g.Vertices)-1)
+	}},
+	{Text: "quiz.eulerian", Choices: yesNoChoices, Certify: (*App).certifyEulerian,
+		Answer: func(g *Graph) string { return yesNo(g.IsEulerian()) }},
+	{Text: "quiz.chromatic", Answer: func(g *Graph) string {
+		k, _, _ := g.ChromaticNumber()
+		return strconv.Itoa(k)
+	}, Choices: func(g *Graph) []string {
+		k, _, _ := g.ChromaticNumber()
+		return numberChoices(k)
+	}},
+	{Text: "quiz.components", Answer: func(g *Graph) string { return strconv.Itoa(g.ComponentCount()) },
+		Choices: func(g *Graph) []string { return numberChoices(g.ComponentCount()) }},
+	{Text: "quiz.edges", Answer: func(g *Graph) string { return strconv.Itoa(g.EdgeCount()) },
+		Choices: func(g *Graph) []string { return numberChoices(g.EdgeCount()) }},
+}
+
+// Asks which graph to quiz on.
+func (app *App) StartQuiz() {
+	begin := func() {
+		app.Quiz = &Quiz{questions: append([]QuizQuestion{}, quizBank...)}
+		rand.Shuffle(len(app.Quiz.questions), func(i, j int) {
+			app.Quiz.questions[i], app.Quiz.questions[j] = app.Quiz.questions[j], app.Quiz.questions[i]
+		})
+		app.Quiz.questions = app.Quiz.questions[:min(quizQuestions, len(app.Quiz.questions))]
+		app.askQuizQuestion()
+	}
+	random := func() {
+		app.LoadTemplate(Template{Name: "quiz.random", Build: func(cx, cy float64) ([]point, [][2]int) {
+			var edges [][2]int
+			for i := range quizVertices {
+				for j := i + 1; j < quizVertices; j++ {
+					if rand.Float64() < quizDensity {
+						edges = append(edges, [2]int{i, j})
+					}
+				}
+			}
+			return circle(quizVertices, cx, cy, 180), edges
+		}}, begin)
+	}
+	buttons := []DialogButton{{Label: T("quiz.random"), Action: random}}
+	if len(app.Graph.Vertices) > 0 {
+		buttons = append(buttons, DialogButton{Label: T("quiz.current"), Action: begin})
+	}
+	app.ShowDialog(&Dialog{Message: T("quiz.dialog"), Buttons: append(buttons, DialogButton{Label: T("dialog.cancel")})})
+}
+
+// Shows the next question, or the score once all are answered.
+func (app *App) askQuizQuestion() {
+	q := app.Quiz
+	if len(q.questions) == 0 {
+		app.ShowDialog(&Dialog{Message: T("quiz.score", q.Correct, q.Asked), Buttons: []DialogButton{{Label: T("dialog.ok")}}})
+		app.Quiz = nil
+		return
+	}
+	question := q.questions[0]
+	q.questions = q.questions[1:]
+	answer := question.Answer(app.Graph)
+
+	d := &Dialog{Message: T("quiz.question", q.Asked+1, T(question.Text))}
+	for _, choice := range question.Choices(app.Graph) {
+		d.Buttons = append(d.Buttons, DialogButton{Label: choice, Action: func() {
+			q.Asked++
+			if choice == answer {
+				q.Correct++
+				app.Notify(T("quiz.right"))
+			} else {
+				app.Warn(T("quiz.wrong", answer))
+			}
+			if question.Certify != nil {
+				app.HighlightCertificate(question.Certify(app))
+			}
+			app.askQuizQuestion()
+		}})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("quiz.stop"), Action: func() { app.Quiz = nil }})
+	app.ShowDialog(d)
+	app.Announce(d.Message)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Sampled views of big graphs:
+
+// Importing a graph with more than SampleSize vertices (settings) offers to show only part of it:
+// the vertices of highest degree, a random induced subgraph, or the largest component, about
+// SampleSize vertices each. A sample is just a filter query (filter.go), so the whole graph stays
+// loaded for analyses and exports, Ctrl+F shows or changes the query and an empty one shows everything.
+
+// Returns a query keeping the vertices of highest degree, about n of them (ties are all kept).
+func topDegreeQuery(g *Graph, n int) string {
+	degrees := make([]int, len(g.Vertices))
+	for i := range g.Vertices {
+		degrees[i] = g.Degree(i)
+	}
+	slices.Sort(degrees)
+	return fmt.Sprintf("degree >= %d", degrees[len(degrees)-min(n, len(degrees))])
+}
+
+// Returns a query keeping a random induced subgraph of about n vertices.
+func randomSampleQuery(g *Graph, n int) string {
+	return fmt.Sprintf("random < %.6f", float64(n)/float64(len(g.Vertices)))
+}
+
+// Returns a query keeping the largest component.
+func largestComponentQuery(g *Graph) string {
+	sizes := make([]int, len(g.Vertices)) // Components are numbered from 0
+	for _, c := range g.Components() {
+		sizes[c]++
+	}
+	largest := 0
+	for c, size := range sizes {
+		if size > sizes[largest] {
+			largest = c
+		}
+	}
+	return fmt.Sprintf("component == %d", largest)
+}
+
+// Offers a sampled view if the graph is over the sample size.
+func (app *App) offerSample() {
+	g, n := app.Graph, app.Settings.SampleSize
+	if n <= 0 || len(g.Vertices) <= n {
+		return
+	}
+	sample := func(query string) func() {
+		return func() { app.SetFilter(query) }
+	}
+	app.ShowDialog(&Dialog{
+		Message: T("sample.prompt", len(g.Vertices), n),
+		Buttons: []DialogButton{
+			{Label: T("sample.top_degree"), Action: sample(topDegreeQuery(g, n))},
+			{Label: T("sample.random"), Action: sample(randomSampleQuery(g, n))},
+			{Label: T("sample.component"), Action: sample(largestComponentQuery(g))},
+			{Label: T("sample.everything")},
+		},
+	})
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Attribute schema:
+
+// Vertex and edge attributes (Vertex.Attrs, Graph.EdgeAttrs) are stored as text, the schema says what
+// each named attribute holds: text, a number, a yes/no flag or a color. Attributes set without a column
+// (imports, pasted tables) get one with the type their values fit, widened to text if a later value
+// doesn't. "Attributes..." (A) defines columns and sets values on the selection.
+// Computed columns ("risk = weight * degree") aren't stored: their expression is evaluated when read.
+//
+// Everything reading attributes goes through the schema: the tooltip shows the typed values,
+// number, flag and color columns are metrics to color and size vertices by (metrics.go),
+// "Select by..." filters on them (predicate.go), and GML writes each value with its type (gml.go).
+
+type AttrType int
+
+const (
+	AttrString AttrType = iota
+	AttrNumber
+	AttrBool
+	AttrColor
+)
+
+var attrTypeKeys = []string{"attr.type.string", "attr.type.number", "attr.type.bool", "attr.type.color"}
+
+const maxListedColumns = 4 // Columns named in the dialog message, the rest are counted
+
+var errAttrValue = errors.New("value doesn't fit the column type")
+
+type AttrColumn struct {
+	Name     string
+	Type     AttrType
+	Edge     bool   // Edge attribute, vertex attribute otherwise
+	Inferred bool   // Typed from the values, not by the user: widened when a value doesn't fit
+	Expr     string // Computed from other attributes and metrics (expr.go), "" for stored values
+}
+
+type Schema struct {
+	Columns []AttrColumn
+}
+
+// Returns the column for an attribute name, if there is one.
+func (s *Schema) Column(name string, edge bool) (AttrColumn, bool) {
+	for _, c := range s.Columns {
+		if c.Name == name && c.Edge == edge {
+			return c, true
+		}
+	}
+	return AttrColumn{}, false
+}
+
+// Returns the vertex or edge columns.
+func (s *Schema) columns(edge bool) []AttrColumn {
+	var columns []AttrColumn
+	for _, c := range s.Columns {
+		if c.Edge == edge {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// Adds a column, or replaces the one with the same name.
+func (s *Schema) Define(c AttrColumn) {
+	for n, old := range s.Columns {
+		if old.Name == c.Name && old.Edge == c.Edge {
+			s.Columns[n] = c
+			return
+		}
+	}
+	s.Columns = append(s.Columns, c)
+}
+
+// Adds columns for attributes that have none, and widens inferred columns their values don't fit.
+func (s *Schema) infer(attrs map[string]string, edge bool) {
+	names := slices.Sorted(maps.Keys(attrs))
+	for _, name := range names {
+		value := attrs[name]
+		c, ok := s.Column(name, edge)
+		if !ok {
+			s.Define(AttrColumn{Name: name, Type: inferAttrType(value), Edge: edge, Inferred: true})
+		} else if c.Inferred && c.Check(value) != nil {
+			c.Type = AttrString
+			s.Define(c)
+		}
+	}
+}
+
+// Returns the narrowest type a value fits.
+func inferAttrType(value string) AttrType {
+	switch {
+	case value == "":
+		return AttrString
+	case AttrColumn{Type: AttrNumber}.Check(value) == nil:
+		return AttrNumber
+	case AttrColumn{Type: AttrBool}.Check(value) == nil:
+		return AttrBool
+	case AttrColumn{Type: AttrColor}.Check(value) == nil:
+		return AttrColor
+	}
+	return AttrString
+}
+
+// Parses a flag: true/false, yes/no or 1/0.
+func parseFlag(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, errAttrValue
+}
+
+// Reports whether a value fits the column type. Empty values fit any column, they mean unset.
+func (c AttrColumn) Check(value string) error {
+	if value == "" {
+		return nil
+	}
+	switch c.Type {
+	case AttrNumber:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			return errAttrValue
+		}
+	case AttrBool:
+		if _, err := parseFlag(value); err != nil {
+			return errAttrValue
+		}
+	case AttrColor:
+		if parseHexColor(strings.TrimSpace(value)) == nil {
+			return errAttrValue
+		}
+	}
+	return nil
+}
+
+// Returns a value as a number: numbers as they are, flags as 1 or 0, and NaN if it doesn't fit or is unset.
+func (c AttrColumn) Number(value string) float64 {
+	switch c.Type {
+	case AttrNumber:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return f
+		}
+	case AttrBool:
+		if b, err := parseFlag(value); err == nil && b {
+			return 1
+		} else if err == nil {
+			return 0
+		}
+	}
+	return math.NaN()
+}
+
+// Formats a value for display.
+func (c AttrColumn) Format(value string) string {
+	switch c.Type {
+	case AttrNumber:
+		if f := c.Number(value); !math.IsNaN(f) {
+			return formatMetric(f)
+		}
+	case AttrBool:
+		if b, err := parseFlag(value); err == nil && b {
+			return T("attr.yes")
+		} else if err == nil {
+			return T("attr.no")
+		}
+	case AttrColor:
+		if clr := parseHexColor(strings.TrimSpace(value)); clr != nil {
+			return svgColor(*clr)
+		}
+	}
+	return value
+}
+
+// Returns the text of a vertex column for every vertex, computed ones evaluated.
+// Vertices whose expression fails get no value.
+func (g *Graph) columnValues(c AttrColumn) []string {
+	values := make([]string, len(g.Vertices))
+	if c.Expr == "" {
+		for i, v := range g.Vertices {
+			values[i] = v.Attrs[c.Name]
+		}
+		return values
+	}
+	if computed, err := newExprEnv(g).computedColumn(c); err == nil {
+		for i, value := range computed {
+			values[i] = exprText(value)
+		}
+	}
+	return values
+}
+
+// Lines describing vertex i's attributes, in column order, for the tooltip.
+func (g *Graph) describeVertex(i int) []string {
+	var lines []string
+	for _, c := range g.Schema.columns(false) {
+		value := g.Vertices[i].Attrs[c.Name]
+		if c.Expr != "" {
+			value = g.columnValues(c)[i]
+		}
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", c.Name, c.Format(value)))
+		}
+	}
+	return lines
+}
+
+// Lines describing an edge's attributes, in column order, for the tooltip.
+func (g *Graph) describeEdge(v1, v2 int) []string {
+	var lines []string
+	if w, ok := g.weightOf(v1, v2); ok {
+		lines = append(lines, T("edge_weight.tooltip", formatMetric(w)))
+	}
+	attrs := g.EdgeAttrs[Edge(v1, v2)]
+	for _, c := range g.Schema.columns(true) {
+		if value := attrs[c.Name]; value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", c.Name, c.Format(value)))
+		}
+	}
+	return lines
+}
+
+// Metrics from the vertex columns, to color and size by.
+// Numbers are amounts, flags, colors and text are categories.
+func (s *Schema) metrics() []Metric {
+	var ms []Metric
+	for _, c := range s.columns(false) {
+		ms = append(ms, Metric{Name: c.Name, Categorical: c.Type != AttrNumber, Values: func(g *Graph) []float64 {
+			texts := g.columnValues(c)
+			values := make([]float64, len(g.Vertices))
+			if c.Type == AttrNumber || c.Type == AttrBool {
+				for i := range g.Vertices {
+					values[i] = c.Number(texts[i])
+					if math.IsNaN(values[i]) {
+						values[i] = 0 // Unset, keeps the color and size scales finite
+					}
+				}
+				return values
+			}
+			// Categories: the sorted distinct values, unset first
+			var distinct []string
+			for _, text := range texts {
+				if value := c.Format(text); !slices.Contains(distinct, value) {
+					distinct = append(distinct, value)
+				}
+			}
+			sort.Strings(distinct)
+			for i, text := range texts {
+				values[i] = float64(slices.Index(distinct, c.Format(text)))
+			}
+			return values
+		}})
+	}
+	return ms
+}
+
+// Sets one attribute on the selected vertices (or edges, for an edge column).
+func (app *App) setSelectionAttr(c AttrColumn, value string) {
+	set := func(attrs map[string]string) map[string]string {
+		attrs = maps.Clone(attrs)
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		if value == "" {
+			delete(attrs, c.Name)
+		} else {
+			attrs[c.Name] = value
+		}
+		return attrs
+	}
+	app.BeginTransaction(T("undo.attrs"))
+	defer app.Commit()
+	if c.Edge {
+		for _, k := range app.selectionEdges() {
+			app.Do(Action{Kind: ActionAttrEdge, V1: k.A, V2: k.B, Attrs: set(app.Graph.EdgeAttrs[k])})
+		}
+		return
+	}
+	for _, i := range app.selectionVertices() {
+		app.Do(Action{Kind: ActionAttrVertex, V1: i, Attrs: set(app.Graph.Vertices[i].Attrs)})
+	}
+}
+
+// Asks for a value of a column, then sets it on the selection.
+func (app *App) askSelectionAttr(c AttrColumn) {
+	app.askPredicate(T("attr.value_prompt", c.Name, T(attrTypeKeys[c.Type])), func(text string) {
+		if err := c.Check(text); err != nil {
+			app.Warn(T("warn.attr_value", text, T(attrTypeKeys[c.Type])))
+			return
+		}
+		app.setSelectionAttr(c, strings.TrimSpace(text))
+	})
+}
+
+// Asks for the name, then the type, of a new column.
+func (app *App) askNewColumn(edge bool) {
+	app.askPredicate(T("attr.name_prompt"), func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		d := &Dialog{Message: T("attr.type_prompt", name)}
+		for t := range attrTypeKeys {
+			d.Buttons = append(d.Buttons, DialogButton{Label: T(attrTypeKeys[t]), Action: func() {
+				c := AttrColumn{Name: name, Type: AttrType(t), Edge: edge}
+				app.Do(Action{Kind: ActionDefineAttr, Column: &c})
+			}})
+		}
+		d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+		app.ShowDialog(d)
+	})
+}
+
+// Asks for "name = expression", then adds the computed column.
+// Its type is the kind of value the expression gives for the first vertex (numbers on an empty graph).
+func (app *App) askComputedColumn() {
+	app.askPredicate(T("attr.computed_prompt"), func(text string) {
+		name, source, ok := strings.Cut(text, "=")
+		name, source = strings.TrimSpace(name), strings.TrimSpace(source)
+		if !ok || name == "" || source == "" {
+			app.Warn(T("warn.computed_syntax"))
+			return
+		}
+		if _, err := ParseExpr(source); err != nil {
+			app.Warn(T("warn.expr", source, err))
+			return
+		}
+		c := AttrColumn{Name: name, Type: AttrNumber, Expr: source}
+		if len(app.Graph.Vertices) > 0 {
+			g := app.Graph.Clone()
+			g.Schema.Define(c)
+			values, err := newExprEnv(g).computedColumn(c)
+			if err != nil {
+				app.Warn(T("warn.expr", source, err))
+				return
+			}
+			c.Type = exprType(values[0])
+		}
+		app.Do(Action{Kind: ActionDefineAttr, Column: &c})
+	})
+}
+
+// Lists the columns and offers to add one, or to set a value on the selection.
+func (app *App) ShowAttributesDialog() {
+	s := &app.Graph.Schema
+	var listed []string
+	for _, c := range s.Columns[:min(len(s.Columns), maxListedColumns)] {
+		kind := T("attr.vertex")
+		if c.Edge {
+			kind = T("attr.edge")
+		}
+		listed = append(listed, fmt.Sprintf("%s %s (%s)", kind, c.Name, T(attrTypeKeys[c.Type])))
+	}
+	if more := len(s.Columns) - maxListedColumns; more > 0 {
+		listed = append(listed, T("attr.more", more))
+	}
+	if len(listed) == 0 {
+		listed = append(listed, T("attr.none"))
+	}
+
+	d := &Dialog{Message: T("attr.dialog", strings.Join(listed, ", "))}
+	var targets []AttrColumn // Columns the selection has something to set on
+	for _, c := range s.Columns {
+		if c.Expr != "" {
+			continue // Computed, nothing to set
+		}
+		if (c.Edge && len(app.selectionEdges()) > 0) || (!c.Edge && len(app.selectionVertices()) > 0) {
+			targets = append(targets, c)
+		}
+	}
+	for _, c := range targets[:min(len(targets), maxColorChoices-2)] {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T("attr.set", c.Name), Action: func() { app.askSelectionAttr(c) }})
+	}
+	d.Buttons = append(d.Buttons,
+		DialogButton{Label: T("attr.new_vertex"), Action: func() { app.askNewColumn(false) }},
+		DialogButton{Label: T("attr.new_edge"), Action: func() { app.askNewColumn(true) }},
+		DialogButton{Label: T("attr.new_computed"), Action: app.askComputedColumn},
+		DialogButton{Label: T("dialog.cancel")},
+	)
+	app.ShowDialog(d)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// World and screen space:
+
+// The screen is drawn in two passes. The world pass (DrawGraph) draws edges and vertices at their
+// own coordinates, through the camera, which zooms and pans them. The screen pass draws everything else
+// at a constant size: title and legends, toolbar, option bar, panels, dialogs, and the marks on the
+// graph (selection, focus and edge start rings, the pen's next edge), which are only placed on it
+// with toScreen so they don't grow or shrink with the graph.
+//
+// The mouse wheel zooms around the cursor, dragging with the middle button (or with Space held)
+// pans, Home resets the view. Clicks on the canvas go through toWorld before hit-testing, so every
+// tool works at any zoom. Exports always draw the graph as it is, without the camera.
+
+const (
+	minZoom  = 0.1
+	maxZoom  = 10
+	zoomStep = 1.1 // Per wheel notch
+)
+
+// Zoom and pan of the canvas: a world point (x, y) is drawn at (x*Zoom + X, y*Zoom + Y).
+type Camera struct {
+	Zoom     float64
+	X, Y     float64
+	dragFrom *point // Cursor position the pan drag last moved from, nil if not panning
+}
+
+// Maps world coordinates (where vertices are) to screen coordinates.
+func (app *App) toScreen(x, y float64) (float64, float64) {
+	c := &app.Camera
+	return x*c.Zoom + c.X, y*c.Zoom + c.Y
+}
+
+// Maps screen coordinates (where the cursor is) to world coordinates.
+func (app *App) toWorld(x, y float64) (float64, float64) {
+	c := &app.Camera
+	return (x - c.X) / c.Zoom, (y - c.Y) / c.Zoom
+}
+
+// Returns how long a world distance looks on the screen.
+func (app *App) toScreenLength(d float64) float64 {
+	return d * app.Camera.Zoom
+}
+
+// Returns the cursor position in world coordinates.
+func (app *App) worldCursor() (float64, float64) {
+	x, y := cursorPosition()
+	return app.toWorld(float64(x), float64(y))
+}
+
+// Runs draw with the drawing helpers going through the camera.
+func (app *App) drawWorld(screen *ebiten.Image, draw func(screen *ebiten.Image)) {
+	scale := renderScale
+	renderScale *= app.Camera.Zoom
+	renderOffsetX, renderOffsetY = app.Camera.X*scale, app.Camera.Y*scale
+	defer func() {
+		renderScale = scale
+		renderOffsetX, renderOffsetY = 0, 0
+	}()
+	draw(screen)
+}
+
+// Zooms by a factor, keeping the screen point (x, y) over the same spot of the drawing.
+func (app *App) ZoomAt(x, y, factor float64) {
+	c := &app.Camera
+	wx, wy := app.toWorld(x, y)
+	c.Zoom = math.Max(minZoom, math.Min(maxZoom, c.Zoom*factor))
+	c.X, c.Y = x-wx*c.Zoom, y-wy*c.Zoom
+	app.clock.redraw = true
+}
+
+// Puts the camera back to showing the drawing at its own size.
+func (app *App) ResetView() {
+	app.Camera = Camera{Zoom: 1}
+	app.clock.redraw = true
+	app.Announce(T("announce.view_reset"))
+}
+
+// Zooms with the wheel and pans with middle or Space drags. Returns whether the mouse was used,
+// in which case the tools don't see it.
+func (app *App) UpdateCamera(mx, my float64) bool {
+	c := &app.Camera
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		app.ResetView()
+	}
+
+	spaceDrag := ebiten.IsKeyPressed(ebiten.KeySpace) && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	if c.dragFrom == nil && my >= canvasTop() &&
+		(inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) || (spaceDrag && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft))) {
+		c.dragFrom = &point{mx, my}
+	}
+	if c.dragFrom != nil {
+		if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) && !spaceDrag {
+			c.dragFrom = nil
+			return true
+		}
+		c.X, c.Y = c.X+mx-c.dragFrom.X, c.Y+my-c.dragFrom.Y
+		c.dragFrom = &point{mx, my}
+		return true
+	}
+
+	if _, wy := ebiten.Wheel(); wy != 0 && my >= canvasTop() {
+		app.ZoomAt(mx, my, math.Pow(zoomStep, wy))
+		return true
+	}
+	return false
+}
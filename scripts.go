@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// User scripts:
+
+// Scripts are text files of commands, the same ones a stream takes (stream.go) plus two for looks:
+//
+//	COLOR c [WHERE expr]   color the vertices matching an expression (expr.go), all of them without WHERE
+//	MAP name | MAP OFF     color by a metric or attribute column (C does the same by hand)
+//
+// Settings.LoadScripts run whenever a graph is loaded (imported or from a template),
+// Settings.ChangeScripts after every change, e.g. "COLOR red WHERE degree >= 4" keeps hubs red
+// while editing. Scripts are read again every time they run, so editing one takes effect right away.
+// Code can hook the same events with OnLoad and OnChange.
+//
+// A script's changes are an undo step of their own (load scripts are part of the load's step).
+// Change scripts run once per update at most, and their own changes don't trigger them again.
+
+// Registers a hook run after a graph is loaded.
+func (app *App) OnLoad(hook func()) {
+	app.loadHooks = append(app.loadHooks, hook)
+}
+
+// Registers a hook run after the graph changed, once per update.
+func (app *App) OnChange(hook func()) {
+	app.changeHooks = append(app.changeHooks, hook)
+}
+
+// Hooks up the scripts named in the settings.
+func (app *App) registerScripts() {
+	for _, path := range app.Settings.LoadScripts {
+		app.OnLoad(func() { app.RunScript(path) })
+	}
+	for _, path := range app.Settings.ChangeScripts {
+		app.OnChange(func() { app.RunScript(path) })
+	}
+}
+
+// Runs the load hooks. Called once a graph is loaded.
+func (app *App) fireLoad() {
+	for _, hook := range app.loadHooks {
+		hook()
+	}
+}
+
+// Runs the change hooks if the graph changed since they last ran. Called every update.
+func (app *App) UpdateScripts() {
+	if app.revision == app.scriptRevision || app.History.depth > 0 {
+		return // Nothing new, or in the middle of a change (e.g. a drag)
+	}
+	if len(app.changeHooks) > 0 {
+		app.BeginTransaction(T("undo.script"))
+		for _, hook := range app.changeHooks {
+			hook()
+		}
+		app.Commit()
+	}
+	app.scriptRevision = app.revision
+}
+
+// Runs the commands of a script file, warning about the lines that fail.
+func (app *App) RunScript(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		app.Warn(T("warn.script", path, err))
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if err := app.scriptCommand(scanner.Text()); err != nil {
+			app.Warn(T("warn.script_line", path, n, err))
+		}
+	}
+}
+
+// Applies one script line: the looks commands, or a stream command.
+func (app *App) scriptCommand(line string) error {
+	cmd, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToUpper(cmd) {
+	case "COLOR":
+		return app.scriptColor(rest)
+	case "MAP":
+		return app.scriptMap(rest)
+	}
+	return app.streamCommand(line)
+}
+
+// COLOR c [WHERE expr]
+func (app *App) scriptColor(args string) error {
+	name, query, _ := strings.Cut(args, " ")
+	if query = strings.TrimSpace(query); query != "" {
+		keyword, rest, _ := strings.Cut(query, " ")
+		if !strings.EqualFold(keyword, "where") {
+			return errStreamSyntax
+		}
+		query = rest
+	}
+
+	clr := parseHexColor(name)
+	for _, n := range namedColors {
+		if strings.EqualFold(n.Name, name) {
+			clr = &n.Color
+		}
+	}
+	if clr == nil {
+		return fmt.Errorf("unknown color %q", name)
+	}
+
+	keep := make([]bool, len(app.Graph.Vertices))
+	if query == "" {
+		for i := range keep {
+			keep[i] = true
+		}
+	} else {
+		expr, err := ParseExpr(query)
+		if err != nil {
+			return err
+		}
+		if keep, err = (&Filter{expr: expr}).matches(app.Graph); err != nil {
+			return err
+		}
+	}
+	for i, v := range app.Graph.Vertices {
+		if keep[i] && v.Color != *clr { // Unchanged vertices make no change, nor undo step
+			app.Do(Action{Kind: ActionColorVertex, V1: i, Color: *clr})
+		}
+	}
+	return nil
+}
+
+// MAP name | MAP OFF
+func (app *App) scriptMap(name string) error {
+	if strings.EqualFold(name, "off") {
+		app.ColorMap = nil
+		return nil
+	}
+	for m, metric := range app.vertexMetrics() {
+		if metricID(metric) == name {
+			app.ColorMap = &ColorMapping{Metric: m, Categorical: metric.Categorical}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown metric %q", name)
+}
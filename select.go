@@ -0,0 +1,464 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Selection:
+
+// The Select tool picks vertices and edges. Click one to select it, Shift+click to add or remove it,
+// click empty space to drop the selection, or drag over it to select a region (lasso.go). Edges are selected per vertex pair, so all parallel
+// copies between two vertices are one selected edge.
+//
+// The selection can then be edited in the option bar, deleted (Delete/Backspace),
+// copied and pasted (Ctrl+C / Ctrl+V), or extracted (E: delete everything outside it; ego.go for Shift+E).
+
+var selectionColor = color.RGBA{0, 200, 255, 255}
+
+type Selection struct {
+	Vertices map[int]bool
+	Edges    map[EdgeKey]bool
+}
+
+// Reports whether nothing is selected.
+func (s *Selection) Empty() bool {
+	return len(s.Vertices) == 0 && len(s.Edges) == 0
+}
+
+// Drops the whole selection.
+func (s *Selection) Clear() {
+	s.Vertices, s.Edges = nil, nil
+}
+
+// Selects a vertex, or flips it if toggle is set.
+func (s *Selection) AddVertex(i int, toggle bool) {
+	if s.Vertices == nil {
+		s.Vertices = map[int]bool{}
+	}
+	if toggle && s.Vertices[i] {
+		delete(s.Vertices, i)
+		return
+	}
+	s.Vertices[i] = true
+}
+
+// Selects the edges between two vertices, or flips them if toggle is set.
+func (s *Selection) AddEdge(v1, v2 int, toggle bool) {
+	if s.Edges == nil {
+		s.Edges = map[EdgeKey]bool{}
+	}
+	k := Edge(v1, v2)
+	if toggle && s.Edges[k] {
+		delete(s.Edges, k)
+		return
+	}
+	s.Edges[k] = true
+}
+
+// Fixes up selected indices after a vertex is deleted, same as App.forgetVertex.
+func (s *Selection) forgetVertex(index int) {
+	shift := func(i int) int {
+		if i > index {
+			return i - 1
+		}
+		return i
+	}
+	vertices := map[int]bool{}
+	for i := range s.Vertices {
+		if i != index {
+			vertices[shift(i)] = true
+		}
+	}
+	edges := map[EdgeKey]bool{}
+	for k := range s.Edges {
+		if k.A != index && k.B != index {
+			edges[EdgeKey{shift(k.A), shift(k.B)}] = true
+		}
+	}
+	s.Vertices, s.Edges = vertices, edges
+}
+
+// Renumbers the selected vertices and edges, vertex i becoming position[i].
+func (s *Selection) renumberVertices(position []int) {
+	vertices := map[int]bool{}
+	for i := range s.Vertices {
+		vertices[position[i]] = true
+	}
+	edges := map[EdgeKey]bool{}
+	for k := range s.Edges {
+		edges[Edge(position[k.A], position[k.B])] = true
+	}
+	s.Vertices, s.Edges = vertices, edges
+}
+
+// Handles a click with the Select tool.
+func (app *App) SelectAt(x, y float64, toggle bool) {
+	if !toggle {
+		app.Selection.Clear()
+	}
+	if i := app.VertexAt(x, y); i >= 0 {
+		app.Selection.AddVertex(i, toggle)
+		app.Announce(T("announce.selected", app.selectionSummary()))
+	} else if i, j, ok := app.EdgeAt(x, y); ok {
+		app.Selection.AddEdge(i, j, toggle)
+		app.Announce(T("announce.selected", app.selectionSummary()))
+	} else {
+		app.startSelectDrag(x, y, toggle)
+	}
+}
+
+// Short description of the selection, e.g. "2 vertices, 1 edges".
+func (app *App) selectionSummary() string {
+	return T("select.summary", len(app.Selection.Vertices), len(app.Selection.Edges))
+}
+
+// Returns the selected vertex indices, in increasing order.
+func (s *Selection) sortedVertices() []int {
+	var indices []int
+	for i := range s.Vertices {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// Returns the selected edges, in a stable order.
+func (s *Selection) sortedEdges() []EdgeKey {
+	var keys []EdgeKey
+	for k := range s.Edges {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].A != keys[j].A {
+			return keys[i].A < keys[j].A
+		}
+		return keys[i].B < keys[j].B
+	})
+	return keys
+}
+
+// Reports whether the edges between i and j belong to the selected subgraph:
+// selected themselves, or between two selected vertices.
+func (app *App) inSelection(i, j int) bool {
+	s := &app.Selection
+	return s.Edges[Edge(i, j)] || (s.Vertices[i] && s.Vertices[j])
+}
+
+// Returns the vertices of the selected subgraph: the selected ones plus the ends of selected edges.
+func (app *App) selectionVertices() []int {
+	keep := map[int]bool{}
+	for i := range app.Selection.Vertices {
+		keep[i] = true
+	}
+	for k := range app.Selection.Edges {
+		keep[k.A], keep[k.B] = true, true
+	}
+	var indices []int
+	for i := range keep {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// Returns the edges of the selected subgraph, one key per vertex pair.
+func (app *App) selectionEdges() []EdgeKey {
+	var keys []EdgeKey
+	for i := range app.Graph.Vertices {
+		for j := i; j < len(app.Graph.Vertices); j++ {
+			if app.Graph.Multiplicity(i, j) > 0 && app.inSelection(i, j) {
+				keys = append(keys, Edge(i, j))
+			}
+		}
+	}
+	return keys
+}
+
+// Deletes the selected vertices and edges.
+func (app *App) DeleteSelection() {
+	if app.Selection.Empty() {
+		return
+	}
+	edges := app.Selection.sortedEdges()
+	vertices := app.Selection.sortedVertices()
+	app.Selection.Clear()
+	app.BeginTransaction(T("undo.delete"))
+	defer app.Commit()
+
+	// Edges first, vertex indices shift once vertices go
+	for _, k := range edges {
+		for app.Graph.CheckVertices(k.A, k.B) == nil && app.Graph.Multiplicity(k.A, k.B) > 0 {
+			from, to := app.Graph.edgeEnds(k.A, k.B, 0)
+			if !app.Do(Action{Kind: ActionDeleteEdge, V1: from, V2: to}) {
+				break
+			}
+		}
+	}
+	for n := len(vertices) - 1; n >= 0; n-- { // Highest index first, so the others stay valid
+		app.Do(Action{Kind: ActionDeleteVertex, V1: vertices[n]})
+	}
+}
+
+// Deletes everything outside the selected subgraph.
+func (app *App) ExtractSelection() {
+	if app.Selection.Empty() {
+		return
+	}
+	keep := map[int]bool{}
+	for _, i := range app.selectionVertices() {
+		keep[i] = true
+	}
+	app.BeginTransaction(T("undo.extract"))
+	defer app.Commit()
+
+	// Unselected edges between kept vertices go first, while indices still match
+	g := app.Graph
+	for i := range g.Vertices {
+		for j := i; j < len(g.Vertices); j++ {
+			if !keep[i] || !keep[j] || app.inSelection(i, j) {
+				continue
+			}
+			for g.Multiplicity(i, j) > 0 {
+				from, to := g.edgeEnds(i, j, 0)
+				if !app.Do(Action{Kind: ActionDeleteEdge, V1: from, V2: to}) {
+					break
+				}
+			}
+		}
+	}
+	for i := len(g.Vertices) - 1; i >= 0; i-- {
+		if !keep[i] {
+			app.Do(Action{Kind: ActionDeleteVertex, V1: i})
+		}
+	}
+}
+
+// Copied subgraph, pasted with Ctrl+V. Positions are relative to its center.
+type CopiedGraph struct {
+	Vertices []Vertex
+	Edges    []CopiedEdge
+	Text     string // Tab separated edge list put on the system clipboard, for spreadsheets
+}
+
+type CopiedEdge struct {
+	A, B   int // Indices into CopiedGraph.Vertices
+	Count  int
+	Style  *EdgeStyle // nil for the default style
+	Time   *Interval  // nil for always
+	Attrs  map[string]string
+	Weight *float64 // nil for the default weight
+}
+
+// Copies the selected subgraph.
+func (app *App) CopySelection() {
+	if app.Selection.Empty() {
+		return
+	}
+	g := app.Graph
+	indices := app.selectionVertices()
+
+	var cx, cy float64
+	for _, i := range indices {
+		cx += g.Vertices[i].X / float64(len(indices))
+		cy += g.Vertices[i].Y / float64(len(indices))
+	}
+
+	copied := &CopiedGraph{}
+	position := map[int]int{} // Graph index -> copy index
+	for n, i := range indices {
+		v := g.Vertices[i]
+		v.X, v.Y = v.X-cx, v.Y-cy
+		copied.Vertices = append(copied.Vertices, v)
+		position[i] = n
+	}
+	for a, i := range indices {
+		for _, j := range indices[a:] {
+			if !app.inSelection(i, j) {
+				continue
+			}
+			ends := [][2]int{{i, j}}
+			if g.Directed && i != j {
+				ends = append(ends, [2]int{j, i}) // The edges going back
+			}
+			for _, end := range ends {
+				from, to := end[0], end[1]
+				if g.AdjMatrix[from][to] == 0 {
+					continue
+				}
+				e := CopiedEdge{A: position[from], B: position[to], Count: g.AdjMatrix[from][to]}
+				if s, ok := g.EdgeStyles[Edge(i, j)]; ok {
+					e.Style = &s
+				}
+				if iv, ok := g.EdgeTimes[Edge(i, j)]; ok {
+					e.Time = &iv
+				}
+				e.Attrs = g.EdgeAttrs[Edge(i, j)]
+				if w, ok := g.weightOf(from, to); ok {
+					e.Weight = &w
+				}
+				copied.Edges = append(copied.Edges, e)
+			}
+		}
+	}
+	var text strings.Builder
+	for _, e := range copied.Edges {
+		for range e.Count {
+			fmt.Fprintf(&text, "%s\t%s\n", copied.Vertices[e.A].Label, copied.Vertices[e.B].Label)
+		}
+	}
+	copied.Text = text.String()
+	writeClipboard(copied.Text) // Best effort, Ctrl+V inside the app doesn't need it
+	app.Copied = copied
+	app.Notify(T("select.copied", app.selectionSummary()))
+}
+
+// Pastes the copied subgraph centered at (x, y) and selects it.
+func (app *App) Paste(x, y float64) {
+	if app.Copied == nil {
+		return
+	}
+	app.BeginTransaction(T("undo.paste"))
+	defer app.Commit()
+	base := len(app.Graph.Vertices)
+	for _, v := range app.Copied.Vertices {
+		label := fmt.Sprintf("V%d", len(app.Graph.Vertices)+1)
+		if v.Label != "" && !isDefaultLabel(v.Label) {
+			label = v.Label
+		}
+		app.Do(Action{Kind: ActionAddVertex, X: x + v.X, Y: y + v.Y, Label: label, Color: v.Color, Radius: v.Radius})
+		if v.Weight != 1 {
+			app.Do(Action{Kind: ActionWeightVertex, V1: len(app.Graph.Vertices) - 1, Weight: v.Weight})
+		}
+		if v.Active != nil {
+			app.Do(Action{Kind: ActionTimeVertex, V1: len(app.Graph.Vertices) - 1, Time: v.Active})
+		}
+		if v.Attrs != nil {
+			app.Do(Action{Kind: ActionAttrVertex, V1: len(app.Graph.Vertices) - 1, Attrs: v.Attrs})
+		}
+		if v.LabelOffset != nil {
+			app.Do(Action{Kind: ActionPlaceLabel, V1: len(app.Graph.Vertices) - 1, Offset: v.LabelOffset})
+		}
+		if v.Layer != 0 {
+			app.Do(Action{Kind: ActionLayerVertex, V1: len(app.Graph.Vertices) - 1, Layer: v.Layer})
+		}
+	}
+	app.Selection.Clear()
+	for n := range app.Copied.Vertices {
+		app.Selection.AddVertex(base+n, false)
+	}
+	for _, e := range app.Copied.Edges {
+		for range e.Count {
+			app.Do(Action{Kind: ActionAddEdge, V1: base + e.A, V2: base + e.B})
+		}
+		if e.Style != nil {
+			app.Do(Action{Kind: ActionStyleEdge, V1: base + e.A, V2: base + e.B, Color: e.Style.Color, Width: e.Style.Width, Curved: e.Style.Curved})
+		}
+		if e.Time != nil {
+			app.Do(Action{Kind: ActionTimeEdge, V1: base + e.A, V2: base + e.B, Time: e.Time})
+		}
+		if e.Attrs != nil {
+			app.Do(Action{Kind: ActionAttrEdge, V1: base + e.A, V2: base + e.B, Attrs: e.Attrs})
+		}
+		if e.Weight != nil {
+			app.Do(Action{Kind: ActionWeightEdge, V1: base + e.A, V2: base + e.B, Weight: *e.Weight})
+		}
+	}
+}
+
+// Reports whether a label looks like one handed out automatically ("V12").
+// Those are renumbered on paste, so the copies don't clash with the originals.
+func isDefaultLabel(label string) bool {
+	var n int
+	_, err := fmt.Sscanf(label, "V%d", &n)
+	return err == nil && fmt.Sprintf("V%d", n) == label
+}
+
+// Style of the edges between i and j, falling back to the defaults.
+func (app *App) edgeStyle(i, j int) EdgeStyle {
+	if s, ok := app.Graph.EdgeStyles[Edge(i, j)]; ok {
+		return s
+	}
+	d := app.Settings.Defaults
+	return EdgeStyle{Color: d.EdgeColor, Width: d.EdgeWidth, Curved: d.EdgeCurved}
+}
+
+// Applies a change to the style of every selected edge.
+func (app *App) styleSelectedEdges(change func(s *EdgeStyle)) {
+	app.BeginTransaction(T("undo.style"))
+	defer app.Commit()
+	for _, k := range app.Selection.sortedEdges() {
+		s := app.edgeStyle(k.A, k.B)
+		change(&s)
+		app.Do(Action{Kind: ActionStyleEdge, V1: k.A, V2: k.B, Color: s.Color, Width: s.Width, Curved: s.Curved})
+	}
+}
+
+// Options of the Select tool: style of the selected elements.
+func (app *App) selectionOptions() []ToolOption {
+	s := &app.Selection
+	mode := T("option.select_marquee")
+	if app.LassoSelect {
+		mode = T("option.select_lasso")
+	}
+	options := []ToolOption{
+		{Label: mode, Action: func() { app.LassoSelect = !app.LassoSelect }},
+		{Label: app.selectionSummary(), Action: func() {}},
+		{Label: T("predicate.dialog"), Action: app.ShowSelectByDialog},
+	}
+	if len(s.Vertices) > 0 {
+		first := app.Graph.Vertices[s.sortedVertices()[0]].Color
+		options = append(options, ToolOption{Label: T("option.color"), Swatch: &first, Action: func() {
+			c := app.nextVertexColor(first)
+			app.BeginTransaction(T("undo.color"))
+			defer app.Commit()
+			for _, i := range s.sortedVertices() {
+				app.Do(Action{Kind: ActionColorVertex, V1: i, Color: c})
+			}
+		}}, ToolOption{Label: T("option.opacity", opacityPercent(first)), Action: func() {
+			app.BeginTransaction(T("undo.color"))
+			defer app.Commit()
+			for _, i := range s.sortedVertices() {
+				app.Do(Action{Kind: ActionColorVertex, V1: i, Color: withOpacity(app.Graph.Vertices[i].Color, nextOpacity(first).A)})
+			}
+		}})
+	}
+	if len(s.Edges) > 0 {
+		k := s.sortedEdges()[0]
+		first := app.edgeStyle(k.A, k.B)
+		options = append(options,
+			ToolOption{Label: T("option.edge_color"), Swatch: &first.Color, Action: func() {
+				c := app.nextVertexColor(first.Color)
+				app.styleSelectedEdges(func(s *EdgeStyle) { s.Color = c })
+			}},
+			ToolOption{Label: T("option.edge_width", first.Width), Action: func() {
+				w := cycleFloat(first.Width, 1, 2, 3, 5)
+				app.styleSelectedEdges(func(s *EdgeStyle) { s.Width = w })
+			}},
+			ToolOption{Label: T("option.opacity", opacityPercent(first.Color)), Action: func() {
+				a := nextOpacity(first.Color).A
+				app.styleSelectedEdges(func(s *EdgeStyle) { s.Color = withOpacity(s.Color, a) })
+			}},
+			ToolOption{Label: T("option.curved", onOff(first.Curved)), Action: func() {
+				app.styleSelectedEdges(func(s *EdgeStyle) { s.Curved = !first.Curved })
+			}},
+		)
+	}
+	return options
+}
+
+// Draws a ring around selected vertices.
+func (app *App) drawSelection(screen *ebiten.Image) {
+	for i := range app.Selection.Vertices {
+		if app.Graph.CheckVertices(i) != nil {
+			continue
+		}
+		v := app.Graph.Vertices[i]
+		x, y := app.toScreen(v.X, v.Y)
+		strokeCircle(screen, float32(x), float32(y), float32(app.toScreenLength(app.vertexRadius(i)))+4, 2, selectionColor, true)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sessions:
+
+// A session file (.gts, "graph tool session") holds the whole working state: the graph, the tool,
+// the view toggles and palette, the title and caption, the color and size mappings, the filter and
+// the saved queries, the time slider, the open exercise, the constraints, the journal, the last macro, and the undo steps.
+// Opening one (like any other file, Ctrl+O or the command line) puts everything back as it was,
+// so work can be resumed later or handed to someone else. Once a session is opened or saved, Ctrl+S
+// (or the Save button) saves to it again.
+//
+// Otherwise Ctrl+S saves the graph file opened or saved last, in its own format, and the first time
+// asks where to save the drawing as node-link JSON (graph.json, nodelink.go): positions, labels,
+// colors, attributes, weights and edges, opened again with Ctrl+O (or the Open button).
+//
+// The file is gzipped gob: it round-trips everything exactly, open time intervals (infinite ends)
+// and per-edge maps included, which JSON can't. Load scripts don't run on a session, it's already how it was left.
+
+const (
+	exportSessionFile = "graph.gts"
+	sessionVersion    = 1 // Bumped when the format changes in ways older files can't be read
+)
+
+var errSessionVersion = errors.New("session saved by a newer version")
+
+type Session struct {
+	Version int
+
+	Graph   *Graph
+	Tool    Tool
+	View    ViewOptions
+	Palette int
+	Bundle  float64 // Settings.BundleStrength
+	Title   string
+	Caption string
+
+	ColorMap     *ColorMapping
+	SizeMap      *SizeMapping
+	FilterQuery  string // "" for no filter
+	FilterDim    bool
+	SavedFilters []string
+	Timeline     *float64 // Time shown on the slider, nil if it's hidden
+	Exercise     *Exercise
+	Constraints  []Constraint
+	WarnOnly     bool // App.ConstraintsWarnOnly
+
+	Journal Journal
+	Macro   *Macro
+	Undo    []SessionStep // Oldest first
+}
+
+type SessionStep struct {
+	Name  string
+	Graph *Graph
+}
+
+// Captures the current working state.
+func (app *App) session() *Session {
+	s := &Session{
+		Version:      sessionVersion,
+		Graph:        app.Graph,
+		Tool:         app.Tool,
+		View:         app.View,
+		Palette:      app.Settings.Palette,
+		Bundle:       app.Settings.BundleStrength,
+		Title:        app.Title,
+		Caption:      app.Caption,
+		ColorMap:     app.ColorMap,
+		SizeMap:      app.SizeMap,
+		SavedFilters: app.SavedFilters,
+		Exercise:     app.Exercise,
+		Constraints:  app.Constraints,
+		WarnOnly:     app.ConstraintsWarnOnly,
+		Journal:      app.Journal,
+		Macro:        app.Macro,
+	}
+	if app.Filter != nil {
+		s.FilterQuery, s.FilterDim = app.Filter.Query, app.Filter.Dim
+	}
+	if app.Timeline != nil {
+		s.Timeline = &app.Timeline.Time
+	}
+	for _, step := range app.History.steps {
+		s.Undo = append(s.Undo, SessionStep{Name: step.Name, Graph: step.graph})
+	}
+	return s
+}
+
+// Writes the current working state to a session file.
+func (app *App) SaveSession(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	err = gob.NewEncoder(zw).Encode(app.session())
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Reads a session file.
+func readSession(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := gob.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Version > sessionVersion {
+		return nil, fmt.Errorf("%w (%d)", errSessionVersion, s.Version)
+	}
+	if s.Graph == nil {
+		s.Graph = &Graph{}
+	}
+	return &s, nil
+}
+
+// Replaces the working state with a session's. The undo steps become the session's too.
+func (app *App) restoreSession(s *Session) {
+	app.StopAnimation()
+	app.Selected, app.EdgeStart, app.MovingVertex, app.Focused, app.PenLast = nil, nil, nil, nil, nil
+	app.Selection.Clear()
+
+	app.Graph = s.Graph
+	app.revision++
+	app.Tool, app.View = s.Tool, s.View
+	app.Settings.Palette, app.Settings.BundleStrength = s.Palette, s.Bundle
+	app.Title, app.Caption = s.Title, s.Caption
+	app.ColorMap, app.SizeMap = s.ColorMap, s.SizeMap
+	app.Exercise = s.Exercise
+	app.Constraints, app.ConstraintsWarnOnly = s.Constraints, s.WarnOnly
+	app.Journal, app.Macro = s.Journal, s.Macro
+
+	app.Filter = nil
+	if s.FilterQuery != "" {
+		if expr, err := ParseExpr(s.FilterQuery); err == nil {
+			app.Filter = &Filter{Query: s.FilterQuery, Dim: s.FilterDim, expr: expr}
+		}
+	}
+	app.SavedFilters = s.SavedFilters
+	app.Timeline = nil
+	if s.Timeline != nil {
+		app.Timeline = newTimeline(*s.Timeline)
+	}
+
+	app.History = History{}
+	for _, step := range s.Undo {
+		app.History.steps = append(app.History.steps, undoStep{Name: step.Name, graph: step.Graph})
+	}
+}
+
+// Opens a session file, offering first to save unsaved changes.
+func (app *App) OpenSession(path string) {
+	s, err := readSession(path)
+	if err != nil {
+		app.Warn(T("warn.session", path, err))
+		return
+	}
+	load := func() {
+		app.restoreSession(s)
+		app.SavePath = path
+		app.markSaved(path)
+		app.Notify(T("session.opened", path))
+	}
+	app.checkUnsaved(load)
+}
+
+// Asks where to save the session.
+func (app *App) exportSession() {
+	app.saveFile(exportSessionFile, app.saveSessionAs)
+}
+
+// Saves the drawing to the file it was opened from or last saved to, asking where the first time
+// (graph.json).
+func (app *App) Save() {
+	if app.SavePath == "" {
+		app.saveFile(exportJSONFile, app.saveAs)
+		return
+	}
+	app.saveAs(app.SavePath)
+}
+
+// Saves the drawing to path: the session for a .gts file, else the graph in the format its extension
+// names. Refuses formats that can't be opened again, such as images.
+func (app *App) saveAs(path string) {
+	if !canSave(path) {
+		app.Warn(T("warn.save_format", filepath.Ext(path)))
+		return
+	}
+	if strings.EqualFold(filepath.Ext(path), ".gts") {
+		app.saveSessionAs(path)
+		return
+	}
+	app.export(path)
+}
+
+// Saves the session to a file, which Ctrl+S then saves to.
+func (app *App) saveSessionAs(path string) {
+	if err := app.SaveSession(path); err != nil {
+		app.Warn(T("warn.write_file", path, err))
+		return
+	}
+	app.SavePath = path
+	app.markSaved(path)
+	app.Notify(T("info.saved", path))
+}
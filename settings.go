@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"os"
+	"path/filepath"
+)
+
+// User preferences:
+
+// Stored as JSON in the user's config directory, e.g. ~/.config/graph-tool/settings.json.
+// Missing or broken files just mean default settings.
+
+type Settings struct {
+	Confirmations bool // Ask before destructive actions
+	ConfirmDegree int  // Deleting a vertex with at least this many incident edges asks first
+
+	Locale          string // UI language ("en", "es", ...), empty means use the environment
+	AnnounceCommand string // Program run with every announcement as its argument (e.g. "espeak")
+
+	Palette int // Index into palettes (palette.go)
+
+	LabelOnCreate bool // Open a label field right after placing a vertex
+
+	CollapseThreshold int // Parallel edges drawn as one with a multiplicity label from this many on
+
+	Defaults ElementDefaults // Style of new vertices and edges
+
+	SizeMinRadius, SizeMaxRadius float64 // Range of vertex sizes when sizing by a metric
+
+	AnimationStepMs int // How long each step of an algorithm animation is shown
+
+	AutoLayoutIntensity float64 // Speed of the auto layout, 1 moves vertices up to 2 pixels per 1/60 s
+
+	BundleStrength float64 // How far bundled edges bend toward their bundle, 0 (straight) to 1
+
+	SampleSize int // Imports with more vertices than this offer to show only about this many (sample.go)
+
+	LoadScripts   []string // Script files run after loading a graph (see scripts.go)
+	ChangeScripts []string // Script files run after every change
+}
+
+// Style used for newly created elements.
+type ElementDefaults struct {
+	VertexColor  color.RGBA // Zero (fully transparent) means the first palette color
+	VertexRadius float64
+	EdgeColor    color.RGBA
+	EdgeWidth    float64
+	EdgeCurved   bool // Single edges drawn as arcs
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Confirmations: true,
+		ConfirmDegree: 3,
+
+		CollapseThreshold: 4,
+
+		SizeMinRadius: 8,
+		SizeMaxRadius: 30,
+
+		AnimationStepMs: 600,
+
+		AutoLayoutIntensity: 0.5,
+
+		BundleStrength: 0.8,
+
+		SampleSize: 500,
+
+		Defaults: ElementDefaults{
+			VertexRadius: 15,
+			EdgeColor:    color.RGBA{255, 0, 0, 255},
+			EdgeWidth:    3,
+		},
+	}
+}
+
+// Path of the settings file.
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "graph-tool", "settings.json"), nil
+}
+
+// Loads the settings, falling back to defaults for anything missing.
+func LoadSettings() Settings {
+	s := DefaultSettings()
+	path, err := settingsPath()
+	if err != nil {
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	json.Unmarshal(data, &s) // Keeps defaults on error
+	return s
+}
+
+// Writes the settings to disk.
+func (s Settings) Save() error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Color for new vertices.
+func (app *App) defaultVertexColor() color.RGBA {
+	if c := app.Settings.Defaults.VertexColor; c.A != 0 {
+		return c
+	}
+	return app.PaletteColor(0)
+}
+
+// Makes the style of vertex i the default for new vertices.
+func (app *App) SetAsDefault(i int) {
+	v := app.Graph.Vertices[i]
+	app.Settings.Defaults.VertexColor = v.Color
+	app.Settings.Defaults.VertexRadius = v.Radius
+	if err := app.Settings.Save(); err != nil {
+		app.Warn(T("warn.save_settings", err))
+	}
+	app.Notify(T("status.set_default", v.Label))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+)
+
+// Size by metric:
+
+// Scales every vertex between Settings.SizeMinRadius and Settings.SizeMaxRadius by a metric (metrics.go).
+// Like coloring by a metric it's only a view, but the scaled sizes are what gets drawn, hit and exported.
+//
+//	R: size by the next metric (after the last one, back to the vertices' own radius).
+
+type SizeMapping struct {
+	Metric int // Index into vertexMetrics
+}
+
+// Switches to sizing by the next metric, or back to plain sizes after the last one.
+// Categorical metrics are skipped, their values have no order.
+func (app *App) CycleSizeMapping() {
+	ms := app.vertexMetrics()
+	next := 0
+	if app.SizeMap != nil {
+		next = app.SizeMap.Metric + 1
+	}
+	for next < len(ms) && ms[next].Categorical {
+		next++
+	}
+	if next >= len(ms) {
+		app.SizeMap = nil
+		app.Notify(T("sizemap.off"))
+		app.Announce(T("sizemap.off"))
+		return
+	}
+	app.SizeMap = &SizeMapping{Metric: next}
+	msg := T("sizemap.by", T(ms[next].Name))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Radius vertex i is drawn with.
+func (app *App) vertexRadius(i int) float64 {
+	if app.SizeMap == nil {
+		return app.Graph.Vertices[i].Radius
+	}
+	values := app.metricValues(app.SizeMap.Metric)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	t := 0.5 // Everything the same, use the middle size
+	if hi > lo {
+		t = (values[i] - lo) / (hi - lo)
+	}
+	s := app.Settings
+	return s.SizeMinRadius + t*(s.SizeMaxRadius-s.SizeMinRadius)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Sliders:
+
+// A horizontal track drawn on the canvas, dragged with the mouse (the time slider, bundling strength).
+// The slider only knows a fraction from 0 to 1, its owner maps that to a value.
+
+const sliderGrab = 10 // Grab distance around the track
+
+type Slider struct {
+	X0, X1, Y float64
+	Dragging  bool
+}
+
+// Handles dragging. While the slider has the mouse, returns the fraction under the cursor and true.
+// done is set on the frame the button is released.
+func (s *Slider) Update(x, y float64) (f float64, active, done bool) {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) &&
+		x >= s.X0-sliderGrab && x <= s.X1+sliderGrab && math.Abs(y-s.Y) <= sliderGrab {
+		s.Dragging = true
+	}
+	if !s.Dragging {
+		return 0, false, false
+	}
+	f = math.Max(0, math.Min(1, (x-s.X0)/(s.X1-s.X0)))
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		s.Dragging = false
+		return f, true, true
+	}
+	return f, true, false
+}
+
+// Draws the track with its end labels, and the knob at f with a label above it.
+func (s *Slider) Draw(screen *ebiten.Image, f float64, label, left, right string) {
+	gray := color.RGBA{200, 200, 200, 255}
+	strokeLine(screen, float32(s.X0), float32(s.Y), float32(s.X1), float32(s.Y), 2, gray, true)
+	for _, end := range []struct {
+		x    float64
+		text string
+	}{{s.X0, left}, {s.X1, right}} {
+		strokeLine(screen, float32(end.x), float32(s.Y)-5, float32(end.x), float32(s.Y)+5, 1, gray, true)
+		printAt(screen, end.text, int(end.x)-textWidth(end.text)/2, int(s.Y)+6)
+	}
+
+	kx := s.X0 + math.Max(0, math.Min(1, f))*(s.X1-s.X0)
+	fillCircle(screen, float32(kx), float32(s.Y), 6, highlightEdgeColor, true)
+	printAt(screen, label, int(kx)-textWidth(label)/2, int(s.Y)-24)
+}
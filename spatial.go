@@ -0,0 +1,240 @@
+package main
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"time"
+)
+
+// Spatial index:
+
+// Hit testing (clicks, hover, delete) looks vertices and edges up in a grid of spatialCellSize cells
+// instead of going over every vertex and pair. Edges are stored as the segments they're drawn with
+// (curves flattened the same way, edges.go), long segments cut to cell length so each sits in a few
+// cells only. The grid is rebuilt when the graph, the vertex positions or sizes, or the collapsing of
+// parallel edges change; visibility (filter, time slider) is checked on the hits, not stored.
+
+const spatialCellSize = 64
+
+type cellKey struct {
+	X, Y int
+}
+
+// Piece of a drawn edge.
+type edgeSegment struct {
+	A, B     point
+	I, J     int // Vertex pair, I <= J
+	From, To int // Ends of the edge it's part of, start first for a directed edge
+}
+
+// What the grid was built from.
+type spatialKey struct {
+	revision  int
+	layout    uint64 // Hash of the vertex positions and radii
+	collapse  bool
+	threshold int
+	curved    bool // Settings.Defaults.EdgeCurved
+}
+
+type spatialIndex struct {
+	key       spatialKey
+	valid     bool
+	vertices  map[cellKey][]int // Vertices by the cell of their center
+	segments  map[cellKey][]int // Indices into segs
+	segs      []edgeSegment
+	maxRadius float64 // Largest vertex radius, how far from its cell a vertex can be hit
+}
+
+// Returns the cell containing (x, y).
+func cellAt(x, y float64) cellKey {
+	return cellKey{int(math.Floor(x / spatialCellSize)), int(math.Floor(y / spatialCellSize))}
+}
+
+// Calls f for every cell within distance r of (x, y) (in its bounding box).
+func cellsAround(x, y, r float64, f func(c cellKey)) {
+	lo, hi := cellAt(x-r, y-r), cellAt(x+r, y+r)
+	for cx := lo.X; cx <= hi.X; cx++ {
+		for cy := lo.Y; cy <= hi.Y; cy++ {
+			f(cellKey{cx, cy})
+		}
+	}
+}
+
+// Returns what the grid for the current graph would be built from.
+func (app *App) spatialKey() spatialKey {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(f float64) {
+		bits := math.Float64bits(f)
+		for k := range buf {
+			buf[k] = byte(bits >> (8 * k))
+		}
+		h.Write(buf[:])
+	}
+	for _, v := range app.Graph.Vertices {
+		put(v.X)
+		put(v.Y)
+		put(v.Radius)
+	}
+	return spatialKey{revision: app.revision, layout: h.Sum64(), collapse: app.View.CollapseParallel, threshold: app.Settings.CollapseThreshold, curved: app.Settings.Defaults.EdgeCurved}
+}
+
+// Returns the grid for the current graph, rebuilding it if anything it depends on changed.
+func (app *App) spatialIndex() *spatialIndex {
+	key := app.spatialKey()
+	s := &app.spatial
+	if s.valid && s.key == key {
+		return s
+	}
+	start := time.Now()
+	if app.Debug != nil {
+		defer func() { app.Debug.IndexBuild = time.Since(start) }()
+	}
+	*s = spatialIndex{key: key, valid: true, vertices: map[cellKey][]int{}, segments: map[cellKey][]int{}}
+	g := app.Graph
+	for i, v := range g.Vertices {
+		c := cellAt(v.X, v.Y)
+		s.vertices[c] = append(s.vertices[c], i)
+		s.maxRadius = math.Max(s.maxRadius, v.Radius)
+	}
+	if app.SizeMap != nil {
+		s.maxRadius = math.Max(app.Settings.SizeMinRadius, app.Settings.SizeMaxRadius)
+	}
+	for _, curve := range app.hitCurves() {
+		for n := 1; n < len(curve.points); n++ {
+			s.addSegment(curve.points[n-1], curve.points[n], curve.seg)
+		}
+	}
+	return s
+}
+
+// Adds a segment of an edge, cut into pieces no longer than a cell.
+func (s *spatialIndex) addSegment(a, b point, seg edgeSegment) {
+	pieces := max(1, int(math.Ceil(math.Hypot(b.X-a.X, b.Y-a.Y)/spatialCellSize)))
+	for k := range pieces {
+		t0, t1 := float64(k)/float64(pieces), float64(k+1)/float64(pieces)
+		seg.A = point{a.X + t0*(b.X-a.X), a.Y + t0*(b.Y-a.Y)}
+		seg.B = point{a.X + t1*(b.X-a.X), a.Y + t1*(b.Y-a.Y)}
+		s.segs = append(s.segs, seg)
+		seen := map[cellKey]bool{}
+		lo, hi := cellAt(min(seg.A.X, seg.B.X), min(seg.A.Y, seg.B.Y)), cellAt(max(seg.A.X, seg.B.X), max(seg.A.Y, seg.B.Y))
+		for cx := lo.X; cx <= hi.X; cx++ {
+			for cy := lo.Y; cy <= hi.Y; cy++ {
+				if c := (cellKey{cx, cy}); !seen[c] {
+					seen[c] = true
+					s.segments[c] = append(s.segments[c], len(s.segs)-1)
+				}
+			}
+		}
+	}
+}
+
+// Edge as drawn, a polyline, for the grid.
+type hitCurve struct {
+	points []point
+	seg    edgeSegment // Pair and ends, points unset
+}
+
+// Returns every edge as the polyline it's drawn with (loops and parallel edges flattened).
+func (app *App) hitCurves() []hitCurve {
+	g := app.Graph
+	var curves []hitCurve
+	for i, a := range g.Vertices {
+		for j := i; j < len(g.Vertices); j++ {
+			b := g.Vertices[j]
+			count := g.Multiplicity(i, j)
+			if count == 0 {
+				continue
+			}
+			if app.collapsed(count) {
+				count = 1 // Drawn as a single edge or loop
+			}
+			for k := range count {
+				from, to := g.edgeEnds(i, j, k)
+				seg := edgeSegment{I: i, J: j, From: from, To: to}
+				var points []point
+				switch {
+				case i == j:
+					cxLeft, cyLeft, cxRight, cyRight := loopControls(a.X, a.Y, k, count)
+					points = cubicPoints(point{a.X, a.Y}, point{cxLeft, cyLeft}, point{cxRight, cyRight}, point{a.X, a.Y})
+				case count == 1:
+					points = []point{{a.X, a.Y}, {b.X, b.Y}}
+					if cx, cy, ok := app.curvedEdge(i, j); ok {
+						points = quadraticPoints(point{a.X, a.Y}, point{cx, cy}, point{b.X, b.Y})
+					}
+				default:
+					cx, cy := g.edgeControl(i, j, k, count)
+					points = quadraticPoints(point{a.X, a.Y}, point{cx, cy}, point{b.X, b.Y})
+				}
+				curves = append(curves, hitCurve{points: points, seg: seg})
+			}
+		}
+	}
+	return curves
+}
+
+// Returns the shown vertex under (x, y), the one drawn on top if several are (zorder.go), -1 if
+// there's none.
+func (s *spatialIndex) vertexAt(app *App, x, y float64) int {
+	best := -1
+	cellsAround(x, y, s.maxRadius, func(c cellKey) {
+		for _, i := range s.vertices[c] {
+			if best >= 0 && !app.drawnAbove(i, best) {
+				continue
+			}
+			v := app.Graph.Vertices[i]
+			if math.Hypot(v.X-x, v.Y-y) < app.vertexRadius(i) && app.vertexShown(i) {
+				best = i
+			}
+		}
+	})
+	return best
+}
+
+// Returns the shown edge drawn closest to (x, y) within edgeHitDistance.
+func (s *spatialIndex) nearestEdge(app *App, x, y float64) (seg edgeSegment, dist float64, ok bool) {
+	dist = math.Inf(1)
+	cellsAround(x, y, edgeHitDistance, func(c cellKey) {
+		for _, n := range s.segments[c] {
+			e := s.segs[n]
+			d := pointToLineDistance(x, y, e.A.X, e.A.Y, e.B.X, e.B.Y)
+			if d < edgeHitDistance && d < dist && app.edgeShown(e.I, e.J) {
+				seg, dist, ok = e, d, true
+			}
+		}
+	})
+	return seg, dist, ok
+}
+
+// Returns every shown vertex under (x, y), in index order.
+func (s *spatialIndex) verticesAt(app *App, x, y float64) []int {
+	var hits []int
+	cellsAround(x, y, s.maxRadius, func(c cellKey) {
+		for _, i := range s.vertices[c] {
+			v := app.Graph.Vertices[i]
+			if math.Hypot(v.X-x, v.Y-y) < app.vertexRadius(i) && app.vertexShown(i) {
+				hits = append(hits, i)
+			}
+		}
+	})
+	slices.Sort(hits)
+	return hits
+}
+
+// Returns the distance to every shown edge drawn within edgeHitDistance of (x, y), by its ends
+// (start first for a directed edge). Parallel edges with the same ends count once.
+func (s *spatialIndex) edgesNear(app *App, x, y float64) map[[2]int]float64 {
+	hits := map[[2]int]float64{}
+	cellsAround(x, y, edgeHitDistance, func(c cellKey) {
+		for _, n := range s.segments[c] {
+			e := s.segs[n]
+			d := pointToLineDistance(x, y, e.A.X, e.A.Y, e.B.X, e.B.Y)
+			ends := [2]int{e.From, e.To}
+			if best, ok := hits[ends]; d < edgeHitDistance && (!ok || d < best) && app.edgeShown(e.I, e.J) {
+				hits[ends] = d
+			}
+		}
+	})
+	return hits
+}
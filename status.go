@@ -0,0 +1,55 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// On-screen warnings and notices:
+
+// Problems that used to be silently ignored (or panic) are shown at the bottom of the canvas for a few seconds.
+// Notices use the same spot for plain feedback (e.g. which palette is active).
+
+const warningDuration = 4 * time.Second
+
+type Warning struct {
+	Text   string
+	Time   time.Time
+	Notice bool // Just information, not a problem
+}
+
+// Shows a non-fatal warning on screen.
+func (app *App) Warn(text string) {
+	app.Warnings = append(app.Warnings, Warning{Text: text, Time: time.Now()})
+}
+
+// Shows an informational message on screen.
+func (app *App) Notify(text string) {
+	app.Warnings = append(app.Warnings, Warning{Text: text, Time: time.Now(), Notice: true})
+}
+
+// Draws the warnings that haven't expired yet, newest at the bottom.
+func (app *App) DrawWarnings(screen *ebiten.Image) {
+	// Drop expired warnings
+	live := app.Warnings[:0]
+	for _, w := range app.Warnings {
+		if time.Since(w.Time) < warningDuration {
+			live = append(live, w)
+		}
+	}
+	app.Warnings = live
+
+	_, h := logicalSize(screen)
+	y := h - 20*len(app.Warnings)
+	for _, w := range app.Warnings {
+		bg := color.RGBA{120, 0, 0, 220}
+		if w.Notice {
+			bg = color.RGBA{60, 60, 60, 220}
+		}
+		fillRect(screen, 40, float32(y), float32(textWidth(w.Text)+10), 18, bg, true)
+		printAt(screen, w.Text, 45, y)
+		y += 20
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Streaming updates:
+
+// Started with -stream FILE (or -stream - for stdin), the app follows a text file of commands,
+// one per line, and applies them as they are written, so another program can draw an evolving graph.
+// Vertices are named by their labels:
+//
+//	ADD v [x y]   add vertex v (at a random spot if no position is given)
+//	EDGE a b      add an edge, adding missing vertices first
+//	DEL v         delete vertex v
+//	DEL a b       delete one edge between a and b
+//	CLEAR         remove everything
+//	# ...         comment
+//
+// Lines are read in the background and posted to the mutation queue (mutations.go), so they're applied
+// between frames like any other edit. The lines read since the last frame are applied together as one
+// undo step.
+// Files are followed like tail -f: reading goes on at the end, and starts over if the file is truncated.
+
+const streamPollInterval = 200 * time.Millisecond
+
+var errStreamSyntax = errors.New("unknown command or wrong number of arguments")
+
+type Stream struct {
+	Source string
+	post   func(f func(app *App)) // The app's mutation queue
+	line   int                    // Lines applied so far, for error messages
+
+	mu      sync.Mutex // Guards pending
+	pending []string   // Lines read but not applied yet
+}
+
+// Starts reading commands from a file, or stdin for "-".
+func (app *App) StartStream(path string) error {
+	s := &Stream{Source: path, post: app.Post}
+	if path == "-" {
+		go s.read(os.Stdin)
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		go s.follow(f)
+	}
+	app.Stream = s
+	return nil
+}
+
+// Queues a line to be applied, posting the pending lines when it's the first of them.
+func (s *Stream) send(line string) {
+	s.mu.Lock()
+	s.pending = append(s.pending, line)
+	first := len(s.pending) == 1
+	s.mu.Unlock()
+	if first {
+		s.post(func(app *App) { app.streamLines(s) })
+	}
+}
+
+// Posts the error that stopped reading.
+func (s *Stream) fail(err error) {
+	s.post(func(app *App) {
+		app.Warn(T("warn.stream", s.Source, err))
+		app.Stream = nil
+	})
+}
+
+// Reads lines until the input ends.
+func (s *Stream) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		s.send(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		s.fail(err)
+		return
+	}
+	s.post(func(app *App) {
+		app.Notify(T("stream.ended", s.Source))
+		app.Stream = nil
+	})
+}
+
+// Reads lines from a file forever, waiting for more at the end.
+func (s *Stream) follow(f *os.File) {
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var offset int64
+	partial := ""
+	for {
+		chunk, err := r.ReadString('\n')
+		offset += int64(len(chunk))
+		if err == nil {
+			s.send(strings.TrimRight(partial+chunk, "\r\n"))
+			partial = ""
+			continue
+		}
+		partial += chunk // Unfinished line, the rest comes later
+		if !errors.Is(err, io.EOF) {
+			s.fail(err)
+			return
+		}
+		time.Sleep(streamPollInterval)
+		if info, err := f.Stat(); err == nil && info.Size() < offset { // Truncated, start over
+			f.Seek(0, io.SeekStart)
+			r.Reset(f)
+			offset, partial = 0, ""
+		}
+	}
+}
+
+// Returns the index of the first vertex with a label, or -1.
+func (g *Graph) VertexByLabel(label string) int {
+	for i, v := range g.Vertices {
+		if v.Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// Applies the pending lines of the stream in one transaction.
+func (app *App) streamLines(s *Stream) {
+	s.mu.Lock()
+	lines := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	app.BeginTransaction(T("undo.stream"))
+	defer app.Commit()
+	for _, line := range lines {
+		s.line++
+		if err := app.streamCommand(line); err != nil {
+			app.Warn(T("warn.stream_line", s.line, line, err))
+		}
+	}
+}
+
+// Returns the vertex with a label, adding it at a random spot if there is none.
+func (app *App) streamVertex(label string) int {
+	if i := app.Graph.VertexByLabel(label); i >= 0 {
+		return i
+	}
+	x := 50 + rand.Float64()*(screenWidth-100)
+	y := canvasTop() + 50 + rand.Float64()*(screenHeight-canvasTop()-100)
+	app.Do(Action{Kind: ActionAddVertex, X: x, Y: y, Label: label, Color: app.defaultVertexColor(), Radius: app.Settings.Defaults.VertexRadius})
+	return len(app.Graph.Vertices) - 1
+}
+
+// Applies one command line.
+func (app *App) streamCommand(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+	g := app.Graph
+	lookup := func(label string) (int, error) {
+		if i := g.VertexByLabel(label); i >= 0 {
+			return i, nil
+		}
+		return -1, fmt.Errorf("%w: %s", ErrNoVertex, label)
+	}
+
+	switch cmd, args := strings.ToUpper(fields[0]), fields[1:]; {
+	case cmd == "ADD" && (len(args) == 1 || len(args) == 3):
+		if g.VertexByLabel(args[0]) >= 0 {
+			return nil // Already there, adding twice is harmless
+		}
+		i := app.streamVertex(args[0])
+		if len(args) == 3 {
+			x, errX := strconv.ParseFloat(args[1], 64)
+			y, errY := strconv.ParseFloat(args[2], 64)
+			if errX != nil || errY != nil {
+				return errStreamSyntax
+			}
+			app.Do(Action{Kind: ActionMoveVertex, V1: i, X: x, Y: y})
+		}
+	case cmd == "EDGE" && len(args) == 2:
+		app.Do(Action{Kind: ActionAddEdge, V1: app.streamVertex(args[0]), V2: app.streamVertex(args[1])})
+	case cmd == "DEL" && len(args) == 1:
+		i, err := lookup(args[0])
+		if err != nil {
+			return err
+		}
+		app.Do(Action{Kind: ActionDeleteVertex, V1: i})
+	case cmd == "DEL" && len(args) == 2:
+		i, err := lookup(args[0])
+		if err != nil {
+			return err
+		}
+		j, err := lookup(args[1])
+		if err != nil {
+			return err
+		}
+		app.Do(Action{Kind: ActionDeleteEdge, V1: i, V2: j})
+	case cmd == "CLEAR" && len(args) == 0:
+		app.Do(Action{Kind: ActionClear})
+	default:
+		return errStreamSyntax
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Stroke tessellation:
+
+// Edges are drawn as one triangle mesh each instead of a vector call per segment: the polyline is
+// widened into a strip with mitered joins (beveled past strokeMiterLimit) and capped at both ends,
+// then drawn in a single DrawTriangles call. Segments no longer leave notches or overlap at the
+// joins, and with the non-zero fill rule (every triangle wound the same way) the parts of a stroke
+// that do overlap are painted once, so transparent edges look the same along their whole length.
+// Vertices go through the same scale and camera offset as the other drawing helpers (hidpi.go),
+// so strokes keep their shape at any zoom and in PNG exports.
+
+const (
+	strokeMiterLimit = 4  // Longest miter, in half widths
+	strokeRoundSteps = 12 // Triangles per half circle of a round cap
+)
+
+type strokeCap int
+
+const (
+	capButt   strokeCap = iota // Ends at the end point
+	capSquare                  // Goes on for half the width
+	capRound                   // Half circle
+)
+
+var (
+	strokeSource   *ebiten.Image // White pixel the triangles are colored from
+	strokeVertices []ebiten.Vertex
+	strokeIndices  []uint16
+)
+
+func whitePixel() *ebiten.Image {
+	if strokeSource == nil {
+		img := ebiten.NewImage(3, 3)
+		img.Fill(color.White)
+		strokeSource = img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+	}
+	return strokeSource
+}
+
+// Adds a mesh vertex at a logical point.
+func addStrokeVertex(x, y float64, clr color.RGBA) uint16 {
+	s := renderScale
+	strokeVertices = append(strokeVertices, ebiten.Vertex{
+		DstX: float32(x*s + renderOffsetX), DstY: float32(y*s + renderOffsetY),
+		SrcX: 1, SrcY: 1,
+		ColorR: float32(clr.R) / 255, ColorG: float32(clr.G) / 255, ColorB: float32(clr.B) / 255, ColorA: float32(clr.A) / 255,
+	})
+	return uint16(len(strokeVertices) - 1)
+}
+
+// Adds a copy of a mesh vertex moved by a logical offset.
+func shiftedStrokeVertex(i uint16, dx, dy float64) uint16 {
+	v := strokeVertices[i]
+	v.DstX, v.DstY = v.DstX+float32(dx*renderScale), v.DstY+float32(dy*renderScale)
+	strokeVertices = append(strokeVertices, v)
+	return uint16(len(strokeVertices) - 1)
+}
+
+// Adds a triangle, wound counterclockwise (on screen) whatever the order of its corners.
+func addStrokeTriangle(a, b, c uint16) {
+	va, vb, vc := strokeVertices[a], strokeVertices[b], strokeVertices[c]
+	if (vb.DstX-va.DstX)*(vc.DstY-va.DstY)-(vb.DstY-va.DstY)*(vc.DstX-va.DstX) < 0 {
+		b, c = c, b
+	}
+	strokeIndices = append(strokeIndices, a, b, c)
+}
+
+// Returns the points without repeats, which have no direction.
+func dedupPoints(points []point) []point {
+	out := make([]point, 0, len(points))
+	for _, p := range points {
+		if len(out) == 0 || math.Hypot(p.X-out[len(out)-1].X, p.Y-out[len(out)-1].Y) > 1e-9 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Unit normal (to the left) of the segment from a to b.
+func segmentNormal(a, b point) point {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	d := math.Hypot(dx, dy)
+	return point{-dy / d, dx / d}
+}
+
+// Strokes a polyline as a single triangle mesh.
+func strokePath(screen *ebiten.Image, points []point, width float64, clr color.RGBA, cap strokeCap) {
+	points = dedupPoints(points)
+	if len(points) < 2 || width <= 0 {
+		return
+	}
+	strokeVertices, strokeIndices = strokeVertices[:0], strokeIndices[:0]
+	h := width / 2
+
+	// Left and right side of the strip at every point, where the segment before it ends (in)
+	// and where the one after it starts (out); they differ at bevels
+	type side struct{ left, right uint16 }
+	in, out := make([]side, len(points)), make([]side, len(points))
+	for i, p := range points {
+		var nIn, nOut point
+		if i > 0 {
+			nIn = segmentNormal(points[i-1], p)
+		}
+		if i < len(points)-1 {
+			nOut = segmentNormal(p, points[i+1])
+		}
+		switch {
+		case i == 0:
+			nIn = nOut
+		case i == len(points)-1:
+			nOut = nIn
+		}
+		mx, my := nIn.X+nOut.X, nIn.Y+nOut.Y
+		m := math.Hypot(mx, my)
+		cos := m / 2 // Between the miter and each normal
+		if m < 1e-9 || 1/cos > strokeMiterLimit {
+			// Bevel: the segments end square, a triangle on each side fills the gap
+			center := addStrokeVertex(p.X, p.Y, clr)
+			in[i] = side{addStrokeVertex(p.X+nIn.X*h, p.Y+nIn.Y*h, clr), addStrokeVertex(p.X-nIn.X*h, p.Y-nIn.Y*h, clr)}
+			out[i] = side{addStrokeVertex(p.X+nOut.X*h, p.Y+nOut.Y*h, clr), addStrokeVertex(p.X-nOut.X*h, p.Y-nOut.Y*h, clr)}
+			addStrokeTriangle(center, in[i].left, out[i].left)
+			addStrokeTriangle(center, in[i].right, out[i].right)
+			continue
+		}
+		l := h / cos
+		mx, my = mx/m*l, my/m*l
+		in[i] = side{addStrokeVertex(p.X+mx, p.Y+my, clr), addStrokeVertex(p.X-mx, p.Y-my, clr)}
+		out[i] = in[i]
+	}
+	for i := 1; i < len(points); i++ {
+		a, b := out[i-1], in[i]
+		addStrokeTriangle(a.left, a.right, b.left)
+		addStrokeTriangle(b.left, a.right, b.right)
+	}
+
+	// Caps
+	n := len(points)
+	addStrokeCap(points[0], points[1], h, clr, cap, out[0].left, out[0].right)
+	addStrokeCap(points[n-1], points[n-2], h, clr, cap, in[n-1].left, in[n-1].right)
+
+	screen.DrawTriangles(strokeVertices, strokeIndices, whitePixel(), &ebiten.DrawTrianglesOptions{AntiAlias: true, FillRule: ebiten.FillRuleNonZero})
+}
+
+// Adds the cap at end point p of a stroke, whose neighbor point is q, closing the strip's end (left, right).
+func addStrokeCap(p, q point, h float64, clr color.RGBA, cap strokeCap, left, right uint16) {
+	d := math.Hypot(p.X-q.X, p.Y-q.Y)
+	dx, dy := (p.X-q.X)/d, (p.Y-q.Y)/d // Pointing out of the stroke
+	switch cap {
+	case capSquare:
+		a, b := shiftedStrokeVertex(left, dx*h, dy*h), shiftedStrokeVertex(right, dx*h, dy*h)
+		addStrokeTriangle(left, right, a)
+		addStrokeTriangle(a, right, b)
+	case capRound:
+		center := addStrokeVertex(p.X, p.Y, clr)
+		angle := math.Atan2(dy, dx)
+		prev := addStrokeVertex(p.X+h*math.Cos(angle-math.Pi/2), p.Y+h*math.Sin(angle-math.Pi/2), clr)
+		for k := 1; k <= strokeRoundSteps; k++ {
+			a := angle - math.Pi/2 + math.Pi*float64(k)/strokeRoundSteps
+			next := addStrokeVertex(p.X+h*math.Cos(a), p.Y+h*math.Sin(a), clr)
+			addStrokeTriangle(center, prev, next)
+			prev = next
+		}
+	}
+}
+
+// Bézier curves:
+
+// Curves are stroked by vector.Path, which flattens them in device pixels, so they stay smooth however
+// far in the camera zooms. Its stroke triangles overlap at the joins and aren't wound the same way, so
+// they're turned counterclockwise before drawing, like the ones above, and transparent curves are
+// painted once too.
+
+// Returns a logical point in device pixels, where the path is built.
+func pathPoint(p point) (x, y float32) {
+	return float32(p.X*renderScale + renderOffsetX), float32(p.Y*renderScale + renderOffsetY)
+}
+
+// Strokes the quadratic Bézier curve from a to b with control point c.
+func strokeQuadratic(screen *ebiten.Image, a, c, b point, width float64, clr color.RGBA) {
+	var path vector.Path
+	path.MoveTo(pathPoint(a))
+	cx, cy := pathPoint(c)
+	bx, by := pathPoint(b)
+	path.QuadTo(cx, cy, bx, by)
+	strokeCurve(screen, &path, width, clr)
+}
+
+// Strokes the cubic Bézier curve from a to b with control points c1 and c2.
+func strokeCubic(screen *ebiten.Image, a, c1, c2, b point, width float64, clr color.RGBA) {
+	var path vector.Path
+	path.MoveTo(pathPoint(a))
+	c1x, c1y := pathPoint(c1)
+	c2x, c2y := pathPoint(c2)
+	bx, by := pathPoint(b)
+	path.CubicTo(c1x, c1y, c2x, c2y, bx, by)
+	strokeCurve(screen, &path, width, clr)
+}
+
+// Strokes a path in device pixels as one mesh, with butt caps and mitered joins.
+func strokeCurve(screen *ebiten.Image, path *vector.Path, width float64, clr color.RGBA) {
+	if width <= 0 {
+		return
+	}
+	strokeVertices, strokeIndices = path.AppendVerticesAndIndicesForStroke(strokeVertices[:0], strokeIndices[:0], &vector.StrokeOptions{
+		Width:      float32(width * renderScale),
+		LineJoin:   vector.LineJoinMiter,
+		MiterLimit: strokeMiterLimit,
+	})
+	for i := range strokeVertices {
+		v := &strokeVertices[i]
+		v.SrcX, v.SrcY = 1, 1
+		v.ColorR, v.ColorG, v.ColorB, v.ColorA = float32(clr.R)/255, float32(clr.G)/255, float32(clr.B)/255, float32(clr.A)/255
+	}
+	for n := 0; n+2 < len(strokeIndices); n += 3 {
+		a, b, c := strokeVertices[strokeIndices[n]], strokeVertices[strokeIndices[n+1]], strokeVertices[strokeIndices[n+2]]
+		if (b.DstX-a.DstX)*(c.DstY-a.DstY)-(b.DstY-a.DstY)*(c.DstX-a.DstX) < 0 {
+			strokeIndices[n+1], strokeIndices[n+2] = strokeIndices[n+2], strokeIndices[n+1]
+		}
+	}
+	screen.DrawTriangles(strokeVertices, strokeIndices, whitePixel(), &ebiten.DrawTrianglesOptions{AntiAlias: true, FillRule: ebiten.FillRuleNonZero})
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Pasting tables:
+
+// Ctrl+V with cells copied from a spreadsheet (tab separated, or commas) builds a graph from them.
+// A dialog shows what the table reads as and lets the user pick:
+//   - Adjacency matrix: n×n numbers, optionally with a header row and first column of labels.
+//     Cell (i, j) is the number of edges, the upper triangle is used.
+//   - Edge list: one edge per row (first two cells), more cells are kept as edge attributes
+//     ("weight" for a third one), or named by a header row.
+//
+// Ctrl+V with anything else (or what the app itself copied) pastes the copied selection as before.
+
+type table [][]string
+
+// Splits pasted text into cells. Returns nil unless it looks like a table (at least two cells in some row).
+func parseTable(text string) table {
+	sep := "\t"
+	if !strings.Contains(text, "\t") {
+		sep = ","
+	}
+	var t table
+	wide := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cells := strings.Split(line, sep)
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		wide = wide || len(cells) >= 2
+		t = append(t, cells)
+	}
+	if !wide {
+		return nil
+	}
+	return t
+}
+
+// Reads the table as an adjacency matrix, ok is false if it isn't one.
+func (t table) matrix() (ig *ImportedGraph, ok bool) {
+	numeric := func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	}
+	rows, labeled := t, false
+	if len(t) > 1 && len(t[0]) == len(t) && !numeric(t[0][len(t[0])-1]) { // Header row, labels in the first column too
+		rows, labeled = t[1:], true
+	}
+	n := len(rows)
+	ig = &ImportedGraph{}
+	counts := make([][]int, n)
+	for i, row := range rows {
+		cells := row
+		label := ""
+		if labeled {
+			label, cells = row[0], row[1:]
+		}
+		if len(cells) != n {
+			return nil, false
+		}
+		counts[i] = make([]int, n)
+		for j, c := range cells {
+			if c == "" {
+				continue // Empty spreadsheet cell, no edge
+			}
+			f, err := strconv.ParseFloat(c, 64)
+			if err != nil || f < 0 {
+				return nil, false
+			}
+			counts[i][j] = int(math.Round(f))
+		}
+		if labeled && t[0][i+1] != "" {
+			label = t[0][i+1]
+		}
+		ig.Vertices = append(ig.Vertices, ImportedVertex{Label: label})
+	}
+	for i := range n {
+		for j := i; j < n; j++ {
+			for range counts[i][j] {
+				ig.Edges = append(ig.Edges, ImportedEdge{A: i, B: j})
+			}
+		}
+	}
+	return ig, true
+}
+
+// Reads the table as an edge list. With header, the first row names the columns.
+func (t table) edgeList(header bool) *ImportedGraph {
+	rows := t
+	var names []string
+	if header {
+		names, rows = t[0], t[1:]
+	}
+	ig := &ImportedGraph{}
+	index := map[string]int{}
+	vertex := func(label string) int {
+		if i, ok := index[label]; ok {
+			return i
+		}
+		index[label] = len(ig.Vertices)
+		ig.Vertices = append(ig.Vertices, ImportedVertex{Label: label})
+		return index[label]
+	}
+	for _, row := range rows {
+		if len(row) < 2 || row[0] == "" || row[1] == "" {
+			continue
+		}
+		e := ImportedEdge{A: vertex(row[0]), B: vertex(row[1])}
+		for k, c := range row[2:] {
+			name := "weight"
+			if k < len(names)-2 && names[k+2] != "" {
+				name = names[k+2]
+			} else if k > 0 {
+				name = "column" + strconv.Itoa(k+3)
+			}
+			if c != "" {
+				if e.Attrs == nil {
+					e.Attrs = map[string]string{}
+				}
+				e.Attrs[name] = c
+			}
+		}
+		ig.Edges = append(ig.Edges, e)
+	}
+	return ig
+}
+
+// Pastes the system clipboard: a table becomes a graph (after asking how to read it),
+// anything else pastes the copied selection.
+func (app *App) PasteClipboard(x, y float64) {
+	text, err := readClipboard()
+	if err != nil || (app.Copied != nil && strings.TrimSpace(text) == strings.TrimSpace(app.Copied.Text)) {
+		app.Paste(x, y)
+		return
+	}
+	t := parseTable(text)
+	if t == nil {
+		app.Paste(x, y)
+		return
+	}
+
+	choice := func(key string, ig *ImportedGraph) DialogButton {
+		return DialogButton{Label: T(key, len(ig.Vertices), len(ig.Edges)), Action: func() { app.importGraph(T("table.source"), ig) }}
+	}
+	d := &Dialog{Message: T("table.dialog", len(t), len(t[0]))}
+	if ig, ok := t.matrix(); ok {
+		d.Buttons = append(d.Buttons, choice("table.matrix", ig))
+	}
+	d.Buttons = append(d.Buttons, choice("table.edge_list", t.edgeList(false)))
+	if len(t) > 1 {
+		d.Buttons = append(d.Buttons, choice("table.edge_list_header", t.edgeList(true)))
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
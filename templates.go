@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Graph templates:
+
+// Starting graphs for exercises, offered at startup and with Ctrl+N.
+// Each template lays out its vertices around the middle of the canvas.
+
+type Template struct {
+	Name  string // Locale key
+	Build func(cx, cy float64) (positions []point, edges [][2]int)
+}
+
+// Positions of n vertices evenly spaced on a circle.
+func circle(n int, cx, cy, r float64) []point {
+	positions := make([]point, n)
+	for i := range positions {
+		angle := 2*math.Pi*float64(i)/float64(n) - math.Pi/2 // First one on top
+		positions[i] = point{cx + r*math.Cos(angle), cy + r*math.Sin(angle)}
+	}
+	return positions
+}
+
+var templates = []Template{
+	{Name: "template.empty", Build: func(cx, cy float64) ([]point, [][2]int) { return nil, nil }},
+	{Name: "template.k5", Build: func(cx, cy float64) ([]point, [][2]int) {
+		var edges [][2]int
+		for i := range 5 {
+			for j := i + 1; j < 5; j++ {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+		return circle(5, cx, cy, 150), edges
+	}},
+	{Name: "template.petersen", Build: func(cx, cy float64) ([]point, [][2]int) {
+		positions := append(circle(5, cx, cy, 180), circle(5, cx, cy, 80)...)
+		var edges [][2]int
+		for i := range 5 {
+			edges = append(edges,
+				[2]int{i, (i + 1) % 5},     // Outer cycle
+				[2]int{i, i + 5},           // Spokes
+				[2]int{i + 5, (i+2)%5 + 5}, // Inner star
+			)
+		}
+		return positions, edges
+	}},
+	{Name: "template.grid", Build: func(cx, cy float64) ([]point, [][2]int) {
+		const size, spacing = 4, 90
+		var positions []point
+		var edges [][2]int
+		for row := range size {
+			for col := range size {
+				i := row*size + col
+				positions = append(positions, point{cx + spacing*(float64(col)-1.5), cy + spacing*(float64(row)-1.5)})
+				if col > 0 {
+					edges = append(edges, [2]int{i - 1, i})
+				}
+				if row > 0 {
+					edges = append(edges, [2]int{i - size, i})
+				}
+			}
+		}
+		return positions, edges
+	}},
+	{Name: "template.random", Build: func(cx, cy float64) ([]point, [][2]int) {
+		const n, p = 20, 0.2
+		var edges [][2]int
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				if rand.Float64() < p {
+					edges = append(edges, [2]int{i, j})
+				}
+			}
+		}
+		return circle(n, cx, cy, 200), edges
+	}},
+	{Name: "template.binary_tree", Build: func(cx, cy float64) ([]point, [][2]int) {
+		const depth = 4
+		var positions []point
+		var edges [][2]int
+		for level := range depth + 1 {
+			width := 1 << level
+			for k := range width {
+				x := cx + (float64(k)+0.5-float64(width)/2)*float64(screenWidth-40)/float64(width)
+				y := cy - 180 + 90*float64(level)
+				positions = append(positions, point{x, y})
+				if i := len(positions) - 1; i > 0 {
+					edges = append(edges, [2]int{(i - 1) / 2, i})
+				}
+			}
+		}
+		return positions, edges
+	}},
+}
+
+// Replaces the drawing with a template, offering first to save unsaved changes. The template is a
+// new, untitled drawing: Ctrl+S asks where to save it. then (may be nil) runs once the template is in.
+func (app *App) LoadTemplate(t Template, then func()) {
+	app.openUntitled(func() { app.loadTemplate(t) }, then)
+}
+
+// Replaces the drawing with a template.
+func (app *App) loadTemplate(t Template) {
+	app.BeginTransaction(T("undo.template"))
+	defer app.Commit()
+	if len(app.Graph.Vertices) > 0 {
+		app.Do(Action{Kind: ActionClear})
+	}
+	cx, cy := float64(screenWidth)/2, (canvasTop()+screenHeight)/2
+	positions, edges := t.Build(cx, cy)
+	for i, p := range positions {
+		app.Do(Action{Kind: ActionAddVertex, X: p.X, Y: p.Y, Label: fmt.Sprintf("V%d", i+1), Color: app.defaultVertexColor(), Radius: app.Settings.Defaults.VertexRadius})
+	}
+	for _, e := range edges {
+		app.Do(Action{Kind: ActionAddEdge, V1: e[0], V2: e[1]})
+	}
+	app.fireLoad()
+}
+
+// Asks which template to start from.
+func (app *App) ShowTemplateDialog() {
+	d := &Dialog{Message: T("template.dialog")}
+	for _, t := range templates {
+		d.Buttons = append(d.Buttons, DialogButton{Label: T(t.Name), Action: func() { app.LoadTemplate(t, nil) }})
+	}
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("family.button"), Action: app.ShowFamilyDialog})
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("generate.button"), Action: app.ShowGenerateDialog})
+	d.Buttons = append(d.Buttons, DialogButton{Label: T("dialog.cancel")})
+	app.ShowDialog(d)
+}
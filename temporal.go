@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Temporal graphs:
+
+// Vertices and edges can be given a time interval they exist in (Shift+I on the selection: "2 5", "3" for
+// "from 3 on", empty for always). The time slider (I) at the bottom of the canvas shows the graph
+// as it is at one moment: whatever isn't active then is hidden, and can't be clicked.
+// An edge only shows while both of its ends do.
+
+const (
+	timelineLeft  = 150
+	timelineRight = screenWidth - 200 // Leaves room for the legends
+	timelineY     = screenHeight - 50
+)
+
+var errBadInterval = errors.New("expected \"from to\", \"from\" or nothing")
+
+type Interval struct {
+	From, To float64 // To is +Inf for intervals open on the right
+}
+
+type Timeline struct {
+	Time   float64
+	slider Slider
+}
+
+// Reports whether t is inside the interval (ends included).
+func (iv Interval) Contains(t float64) bool {
+	return t >= iv.From && t <= iv.To
+}
+
+// Formats an interval, nil being "always".
+func (iv *Interval) String() string {
+	switch {
+	case iv == nil:
+		return T("time.always")
+	case math.IsInf(iv.To, 1):
+		return T("time.from", formatMetric(iv.From))
+	}
+	return T("time.between", formatMetric(iv.From), formatMetric(iv.To))
+}
+
+// Parses "from to" or "from", nil for an empty text.
+func parseInterval(text string) (*Interval, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) > 2 {
+		return nil, errBadInterval
+	}
+	iv := &Interval{To: math.Inf(1)}
+	var err error
+	if iv.From, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return nil, errBadInterval
+	}
+	if len(fields) == 2 {
+		if iv.To, err = strconv.ParseFloat(fields[1], 64); err != nil || iv.To < iv.From {
+			return nil, errBadInterval
+		}
+	}
+	return iv, nil
+}
+
+// Sets when the edges between two vertices exist, nil for always.
+func (g *Graph) SetEdgeTime(v1, v2 int, iv *Interval) error {
+	if err := g.CheckVertices(v1, v2); err != nil {
+		return err
+	}
+	if g.Multiplicity(v1, v2) <= 0 {
+		return fmt.Errorf("%w: %s - %s", ErrNoEdge, g.Vertices[v1].Label, g.Vertices[v2].Label)
+	}
+	if iv == nil {
+		delete(g.EdgeTimes, Edge(v1, v2))
+		return nil
+	}
+	if g.EdgeTimes == nil {
+		g.EdgeTimes = map[EdgeKey]Interval{}
+	}
+	g.EdgeTimes[Edge(v1, v2)] = *iv
+	return nil
+}
+
+// Returns the earliest and latest interval ends, for the slider's scale. ok is false if nothing has a time.
+func (g *Graph) TimeRange() (lo, hi float64, ok bool) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	add := func(iv Interval) {
+		lo, hi, ok = math.Min(lo, iv.From), math.Max(hi, iv.From), true
+		if !math.IsInf(iv.To, 1) {
+			hi = math.Max(hi, iv.To)
+		}
+	}
+	for _, v := range g.Vertices {
+		if v.Active != nil {
+			add(*v.Active)
+		}
+	}
+	for _, iv := range g.EdgeTimes {
+		add(iv)
+	}
+	if ok && hi == lo {
+		hi = lo + 1
+	}
+	return lo, hi, ok
+}
+
+// Reports whether vertex i exists at the slider's time.
+// Vertices hidden by the filter (filter.go) aren't shown either.
+func (app *App) vertexShown(i int) bool {
+	if app.Filter != nil && !app.Filter.Dim && app.filteredOut(i) {
+		return false
+	}
+	active := app.Graph.Vertices[i].Active
+	return app.Timeline == nil || active == nil || active.Contains(app.Timeline.Time)
+}
+
+// Reports whether the edges between i and j exist at the slider's time.
+func (app *App) edgeShown(i, j int) bool {
+	if !app.vertexShown(i) || !app.vertexShown(j) {
+		return false
+	}
+	if app.Timeline == nil {
+		return true
+	}
+	iv, ok := app.Graph.EdgeTimes[Edge(i, j)]
+	return !ok || iv.Contains(app.Timeline.Time)
+}
+
+// Returns a time slider showing time t.
+func newTimeline(t float64) *Timeline {
+	return &Timeline{Time: t, slider: Slider{X0: timelineLeft, X1: timelineRight, Y: timelineY}}
+}
+
+// Shows or hides the time slider. Hiding it shows everything again.
+func (app *App) ToggleTimeline() {
+	if app.Timeline != nil {
+		app.Timeline = nil
+		app.Notify(T("time.off"))
+		return
+	}
+	lo, _, ok := app.Graph.TimeRange()
+	if !ok {
+		app.Warn(T("time.none"))
+		return
+	}
+	app.Timeline = newTimeline(lo)
+	app.Announce(T("time.at", formatMetric(lo)))
+}
+
+// Asks when the selected vertices and edges exist.
+func (app *App) askSelectionTime() {
+	s := &app.Selection
+	if s.Empty() {
+		app.Warn(T("time.no_selection"))
+		return
+	}
+	app.askPredicate(T("time.prompt"), func(text string) {
+		iv, err := parseInterval(text)
+		if err != nil {
+			app.Warn(T("warn.bad_interval", text, err))
+			return
+		}
+		app.BeginTransaction(T("undo.times"))
+		defer app.Commit()
+		for _, i := range s.sortedVertices() {
+			app.Do(Action{Kind: ActionTimeVertex, V1: i, Time: iv})
+		}
+		for _, e := range s.sortedEdges() {
+			app.Do(Action{Kind: ActionTimeEdge, V1: e.A, V2: e.B, Time: iv})
+		}
+	})
+}
+
+// Handles dragging the slider. Returns true while it has the mouse.
+func (app *App) UpdateTimeline(x, y float64) bool {
+	tl := app.Timeline
+	if tl == nil {
+		return false
+	}
+	f, active, done := tl.slider.Update(x, y)
+	if !active {
+		return false
+	}
+	if lo, hi, ok := app.Graph.TimeRange(); ok {
+		tl.Time = lo + f*(hi-lo)
+	}
+	if done {
+		app.Announce(T("time.at", formatMetric(tl.Time)))
+	}
+	return true
+}
+
+// Draws the time slider.
+func (app *App) DrawTimeline(screen *ebiten.Image) {
+	tl := app.Timeline
+	if tl == nil {
+		return
+	}
+	lo, hi, ok := app.Graph.TimeRange()
+	if !ok {
+		lo, hi = tl.Time, tl.Time+1
+	}
+	tl.slider.Draw(screen, (tl.Time-lo)/(hi-lo), T("time.at", formatMetric(tl.Time)), formatMetric(lo), formatMetric(hi))
+}
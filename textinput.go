@@ -0,0 +1,98 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Inline text input:
+
+// A small text field drawn on the canvas (e.g. under a new vertex).
+// While it's open, typed characters go into it instead of triggering shortcuts.
+// Enter commits, Escape cancels, clicking somewhere else commits.
+// Up and Down go back and forth through the field's history, if it has one.
+
+type TextInput struct {
+	Text     string
+	X, Y     float64           // Top left corner, on the screen
+	OnCommit func(text string) // Called on Enter
+	OnCancel func()            // Called on Escape (may be nil)
+	History  []string          // Earlier entries, oldest first (may be nil)
+	recalled int               // Entries back in the history, 0 for what's being typed
+}
+
+// Opens an inline text field.
+func (app *App) OpenTextInput(t *TextInput) {
+	app.TextInput = t
+}
+
+// Closes the text field, keeping what was typed.
+func (app *App) CommitTextInput() {
+	t := app.TextInput
+	app.TextInput = nil
+	if t != nil && t.OnCommit != nil {
+		t.OnCommit(t.Text)
+	}
+}
+
+// Closes the text field, throwing away what was typed.
+func (app *App) CancelTextInput() {
+	t := app.TextInput
+	app.TextInput = nil
+	if t != nil && t.OnCancel != nil {
+		t.OnCancel()
+	}
+}
+
+// Reports whether a key should act now: on the first frame, then repeating while held.
+func keyRepeat(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d >= 30 && d%3 == 0)
+}
+
+// Processes keyboard input for the open text field.
+func (app *App) HandleTextInput() {
+	t := app.TextInput
+
+	t.Text = string(ebiten.AppendInputChars([]rune(t.Text)))
+	if keyRepeat(ebiten.KeyBackspace) && len(t.Text) > 0 {
+		runes := []rune(t.Text)
+		t.Text = string(runes[:len(runes)-1])
+	}
+
+	switch {
+	case keyRepeat(ebiten.KeyUp) && t.recalled < len(t.History):
+		t.recalled++
+		t.Text = t.History[len(t.History)-t.recalled]
+	case keyRepeat(ebiten.KeyDown) && t.recalled > 0:
+		t.recalled--
+		t.Text = ""
+		if t.recalled > 0 {
+			t.Text = t.History[len(t.History)-t.recalled]
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter):
+		app.CommitTextInput()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		app.CancelTextInput()
+	}
+}
+
+// Draws the open text field (if any), with a blinking cursor.
+func (app *App) DrawTextInput(screen *ebiten.Image) {
+	t := app.TextInput
+	if t == nil {
+		return
+	}
+	w := float32(max(60, textWidth(t.Text)+12))
+	fillRect(screen, float32(t.X), float32(t.Y), w, 20, color.RGBA{40, 40, 40, 255}, true)
+	strokeRect(screen, float32(t.X), float32(t.Y), w, 20, 1, color.RGBA{255, 255, 0, 255}, true)
+
+	text := t.Text
+	if time.Now().UnixMilli()/500%2 == 0 {
+		text += "_"
+	}
+	printAt(screen, text, int(t.X)+4, int(t.Y)+2)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Trivial Graph Format:
+
+// One vertex per line (id, then the label), a line with "#", then one edge per line (two ids, then an optional label):
+//
+//	1 Alice
+//	2 Bob
+//	#
+//	1 2 friends
+//
+// Edge labels are kept as the "label" attribute. TGF has no positions, imported vertices go on a circle.
+
+// Parses TGF.
+func parseTGF(data []byte) (*ImportedGraph, error) {
+	ig := &ImportedGraph{}
+	index := map[string]int{}
+	edges := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line == "#":
+			edges = true
+			continue
+		}
+		id, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		if !edges {
+			label := rest
+			if label == "" {
+				label = id
+			}
+			index[id] = len(ig.Vertices)
+			ig.Vertices = append(ig.Vertices, ImportedVertex{Label: label})
+			continue
+		}
+		target, label, _ := strings.Cut(rest, " ")
+		a, okA := index[id]
+		b, okB := index[target]
+		if !okA || !okB {
+			return nil, fmt.Errorf("%w: line %d", ErrNoVertex, n)
+		}
+		e := ImportedEdge{A: a, B: b}
+		if label = strings.TrimSpace(label); label != "" {
+			e.Attrs = map[string]string{"label": label}
+		}
+		ig.Edges = append(ig.Edges, e)
+	}
+	return ig, scanner.Err()
+}
+
+// Writes the graph as TGF, vertices numbered from 1. Directed edges go from the first id to the second.
+func writeTGF(buf *bytes.Buffer, g *Graph) {
+	for i, v := range g.Vertices {
+		fmt.Fprintf(buf, "%d %s\n", i+1, v.Label)
+	}
+	fmt.Fprintln(buf, "#")
+	for i := range g.Vertices {
+		for j := range g.Vertices {
+			if j < i && !g.Directed { // Written from the lower index only
+				continue
+			}
+			label := g.EdgeAttrs[Edge(i, j)]["label"]
+			for range g.AdjMatrix[i][j] {
+				fmt.Fprintln(buf, strings.TrimSpace(fmt.Sprintf("%d %d %s", i+1, j+1, label)))
+			}
+		}
+	}
+}
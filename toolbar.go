@@ -0,0 +1,49 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Toolbar:
+
+// Buttons are 100x40 and wrap into more rows once they don't fit the screen width.
+
+const (
+	toolButtonWidth  = 100
+	toolButtonHeight = 40
+	toolsPerRow      = screenWidth / toolButtonWidth
+)
+
+// Height of the toolbar (all rows).
+func toolbarHeight() float64 {
+	rows := (len(toolNames) + toolsPerRow - 1) / toolsPerRow
+	return float64(rows * toolButtonHeight)
+}
+
+// Returns the tool button under (x, y), or -1 if there is none.
+func toolAt(x, y float64) int {
+	if x < 0 || y < 0 || y >= toolbarHeight() || x >= toolsPerRow*toolButtonWidth {
+		return -1
+	}
+	i := int(y)/toolButtonHeight*toolsPerRow + int(x)/toolButtonWidth
+	if i >= len(toolNames) {
+		return -1
+	}
+	return i
+}
+
+// Draws the toolbar, highlighting the selected tool.
+func (app *App) DrawToolbar(screen *ebiten.Image) {
+	for i, toolName := range toolNames {
+		toolColor := color.RGBA{200, 200, 200, 255}
+		if app.Tool == Tool(i) {
+			toolColor = color.RGBA{100, 100, 255, 255} // Highlight selected tool
+		}
+		x, y := i%toolsPerRow*toolButtonWidth, i/toolsPerRow*toolButtonHeight
+		fillRect(screen, float32(x), float32(y), toolButtonWidth, toolButtonHeight, toolColor, true)
+		strokeRect(screen, float32(x), float32(y), toolButtonWidth, toolButtonHeight, 1, color.RGBA{120, 120, 120, 255}, true)
+		printAt(screen, T(toolName), x+5, y+10)
+	}
+}
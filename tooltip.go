@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Hover tooltips:
+
+// Resting the cursor on a vertex or edge for a moment shows its details next to the cursor.
+
+const tooltipDelay = 500 * time.Millisecond
+
+// What the cursor is resting on.
+type hoverTarget struct {
+	Vertex int // -1 if not a vertex
+	V1, V2 int // Edge ends, only used when Vertex is -1
+	Edge   bool
+}
+
+type Hover struct {
+	Target hoverTarget
+	Since  time.Time
+}
+
+// Finds what's under the cursor, the closest the way a click picks it (disambiguate.go).
+func (app *App) hoverTargetAt(x, y float64) (hoverTarget, bool) {
+	hits := app.hitsAt(x, y, true, true)
+	switch {
+	case len(hits) == 0:
+		return hoverTarget{}, false
+	case hits[0].Vertex >= 0:
+		return hoverTarget{Vertex: hits[0].Vertex}, true
+	}
+	return hoverTarget{Vertex: -1, V1: hits[0].V1, V2: hits[0].V2, Edge: true}, true
+}
+
+// Tracks what the cursor rests on. Called every frame.
+func (app *App) UpdateHover() {
+	x, y := cursorPosition()
+	mx, my := app.toWorld(float64(x), float64(y))
+
+	target, ok := hoverTarget{}, false
+	if float64(y) >= canvasTop() && inpututil.MouseButtonPressDuration(ebiten.MouseButtonLeft) == 0 {
+		target, ok = app.hoverTargetAt(mx, my)
+	}
+	switch {
+	case !ok:
+		app.Hover = nil
+	case app.Hover == nil || app.Hover.Target != target:
+		app.Hover = &Hover{Target: target, Since: time.Now()}
+	}
+}
+
+// Lines of text describing the hovered element.
+func (app *App) tooltipLines(t hoverTarget) []string {
+	g := app.Graph
+	if !t.Edge {
+		if g.CheckVertices(t.Vertex) != nil {
+			return nil
+		}
+		v := g.Vertices[t.Vertex]
+		lines := []string{
+			v.Label,
+			T("tooltip.index", t.Vertex),
+			T("tooltip.degree", g.Degree(t.Vertex)),
+			T("tooltip.position", v.X, v.Y),
+			T("tooltip.color", fmt.Sprintf("#%02x%02x%02x", v.Color.R, v.Color.G, v.Color.B)),
+		}
+		return append(lines, g.describeVertex(t.Vertex)...)
+	}
+	if g.CheckVertices(t.V1, t.V2) != nil {
+		return nil
+	}
+	lines := []string{T("tooltip.edge", g.Vertices[t.V1].Label, g.Vertices[t.V2].Label)}
+	if t.V1 == t.V2 {
+		lines = append(lines, T("tooltip.loop"))
+	}
+	lines = append(lines, T("tooltip.multiplicity", g.AdjMatrix[t.V1][t.V2]))
+	return append(lines, g.describeEdge(t.V1, t.V2)...)
+}
+
+// Draws the tooltip once the cursor has rested long enough.
+func (app *App) DrawTooltip(screen *ebiten.Image) {
+	if app.Hover == nil || time.Since(app.Hover.Since) < tooltipDelay || app.Dialog != nil || app.HitMenu != nil {
+		return
+	}
+	lines := app.tooltipLines(app.Hover.Target)
+	if len(lines) == 0 {
+		return
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, textWidth(line))
+	}
+	w, h := float32(width+10), float32(16*len(lines)+6)
+
+	// Next to the cursor, but kept on screen
+	cx, cy := cursorPosition()
+	x, y := float32(cx+16), float32(cy+16)
+	sw, sh := logicalSize(screen)
+	x = min(x, float32(sw)-w)
+	y = min(y, float32(sh)-h)
+
+	fillRect(screen, x, y, w, h, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, w, h, 1, color.RGBA{200, 200, 200, 255}, true)
+	printAt(screen, strings.Join(lines, "\n"), int(x)+5, int(y)+3)
+}
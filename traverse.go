@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Traversals:
+
+// The Traverse tool runs a breadth first search from the vertex clicked (depth first with Shift held)
+// and plays it as an animation, one step per vertex in the order the search visits them. Visited
+// vertices are colored and numbered, the one just reached stands out, and the tree edges (how each
+// vertex was first reached) are highlighted. The visit order builds up in a panel at the side, each vertex
+// with the one it was reached from.
+// Neighbors are taken in vertex order, along the edge directions if the graph is directed;
+// vertices out of reach are left out.
+
+// Visit order of a traversal from start, and the vertex each one was reached from (-1 for start and
+// the vertices never reached).
+func (g *Graph) Traversal(start int, depthFirst bool) (order, parent []int) {
+	n := len(g.Vertices)
+	parent = make([]int, n)
+	visited := make([]bool, n)
+	for i := range parent {
+		parent[i] = -1
+	}
+	if depthFirst {
+		// Stack of (vertex, reached from) pairs, neighbors pushed in reverse so the lowest is visited
+		// first, as a recursive search would
+		stack := [][2]int{{start, -1}}
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			v := top[0]
+			if visited[v] {
+				continue
+			}
+			visited[v], parent[v] = true, top[1]
+			order = append(order, v)
+			for w := n - 1; w >= 0; w-- {
+				if w != v && g.AdjMatrix[v][w] > 0 && !visited[w] {
+					stack = append(stack, [2]int{w, v})
+				}
+			}
+		}
+		return order, parent
+	}
+	visited[start] = true
+	queue := []int{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		order = append(order, v)
+		for w := range n {
+			if w != v && g.AdjMatrix[v][w] > 0 && !visited[w] {
+				visited[w], parent[w] = true, v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return order, parent
+}
+
+// Animates a traversal from a vertex.
+func (app *App) Traverse(start int, depthFirst bool) {
+	g := app.Graph
+	if g.CheckVertices(start) != nil {
+		return
+	}
+	order, parent := g.Traversal(start, depthFirst)
+	title := T("traverse.bfs", g.Vertices[start].Label)
+	if depthFirst {
+		title = T("traverse.dfs", g.Vertices[start].Label)
+	}
+	visitedColor, currentColor := app.PaletteColor(2), highlightEdgeColor
+	a := &Animation{Title: title}
+	for k, v := range order {
+		step := AnimationStep{Vertices: map[int]color.RGBA{}, Edges: map[EdgeKey]bool{}, Labels: map[int]string{}}
+		for n, w := range order[:k+1] {
+			step.Vertices[w] = visitedColor
+			step.Labels[w] = fmt.Sprint(n + 1)
+			if p := parent[w]; p >= 0 {
+				step.Edges[Edge(p, w)] = true
+			}
+		}
+		step.Vertices[v] = currentColor
+		line := fmt.Sprintf("%d. %s", k+1, g.Vertices[v].Label)
+		if p := parent[v]; p >= 0 {
+			step.Text = T("traverse.visit", g.Vertices[v].Label, g.Vertices[p].Label)
+			line += " (" + g.Vertices[p].Label + ")"
+		} else {
+			step.Text = T("traverse.start", g.Vertices[v].Label)
+		}
+		a.Steps = append(a.Steps, step)
+		a.Log = append(a.Log, line)
+	}
+	app.PlayAnimation(a)
+	if len(order) < len(g.Vertices) {
+		app.Notify(T("traverse.unreached", len(g.Vertices)-len(order)))
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Tree panels:
+
+// Trees of vertex sets (tree decompositions, modular decompositions) are drawn in panels down the
+// right side of the canvas, each one starting where the one above it ended. Nodes are boxes of text:
+// leaves side by side in depth first order, parents centered over their children. Trees too wide or
+// too deep to fit are only summed up.
+
+const (
+	treePanelMaxWidth = 420
+	treePanelMaxDepth = 12
+	treeNodeHeight    = 18
+	treeNodeGap       = 14 // Between levels, and between nodes side by side
+	treePanelSpacing  = 10 // Between stacked panels
+)
+
+type TreePanel struct {
+	Summary string
+	Texts   []string     // Text of each node
+	Parent  []int        // Parent node, -1 for roots
+	Borders []color.RGBA // Outline of each node
+}
+
+// Screen area of a node, for clicks.
+type nodeBox struct {
+	X, Y, W, H float32
+}
+
+func (b nodeBox) contains(x, y float64) bool {
+	return float32(x) >= b.X && float32(x) < b.X+b.W && float32(y) >= b.Y && float32(y) < b.Y+b.H
+}
+
+// Places the nodes. Returns the center x (from 0) and level of each node, and the total width.
+func (p *TreePanel) layout() (xs []float32, levels []int, width float32) {
+	children := make([][]int, len(p.Texts))
+	var roots []int
+	for k, parent := range p.Parent {
+		if parent < 0 {
+			roots = append(roots, k)
+		} else {
+			children[parent] = append(children[parent], k)
+		}
+	}
+	xs, levels = make([]float32, len(p.Texts)), make([]int, len(p.Texts))
+	var place func(k, level int)
+	place = func(k, level int) {
+		levels[k] = level
+		if len(children[k]) == 0 {
+			w := float32(textWidth(p.Texts[k]) + 8)
+			xs[k] = width + w/2
+			width += w + treeNodeGap
+			return
+		}
+		for _, c := range children[k] {
+			place(c, level+1)
+		}
+		xs[k] = (xs[children[k][0]] + xs[children[k][len(children[k])-1]]) / 2
+	}
+	for _, r := range roots {
+		place(r, 0)
+	}
+	return xs, levels, width - treeNodeGap
+}
+
+// Draws the panel with its top at y. Returns where the next panel goes,
+// and the box of every node (nil if the tree was only summed up).
+func (p *TreePanel) Draw(screen *ebiten.Image, y float32) (float32, []nodeBox) {
+	xs, levels, treeWidth := p.layout()
+	depth := 0
+	if len(levels) > 0 {
+		depth = slices.Max(levels) + 1
+	}
+
+	w, h := float32(textWidth(p.Summary)+10), float32(22)
+	showTree := depth > 0 && treeWidth <= treePanelMaxWidth && depth <= treePanelMaxDepth
+	if showTree {
+		w = max(w, treeWidth+20)
+		h += float32(depth)*(treeNodeHeight+treeNodeGap) + 4
+	}
+	sw, _ := logicalSize(screen)
+	x := float32(sw) - w - 10
+	lineColor := color.RGBA{200, 200, 200, 255}
+	fillRect(screen, x, y, w, h, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, w, h, 1, lineColor, true)
+	printAt(screen, p.Summary, int(x)+5, int(y)+3)
+	if !showTree {
+		return y + h + treePanelSpacing, nil
+	}
+
+	left, top := x+10+(w-20-treeWidth)/2, y+26
+	center := func(k int) (float32, float32) {
+		return left + xs[k], top + float32(levels[k])*(treeNodeHeight+treeNodeGap) + treeNodeHeight/2
+	}
+	for k, parent := range p.Parent {
+		if parent >= 0 {
+			x0, y0 := center(k)
+			x1, y1 := center(parent)
+			strokeLine(screen, x0, y0-treeNodeHeight/2, x1, y1+treeNodeHeight/2, 1, lineColor, true)
+		}
+	}
+	boxes := make([]nodeBox, len(p.Texts))
+	for k, text := range p.Texts {
+		nw := float32(textWidth(text) + 8)
+		cx, cy := center(k)
+		boxes[k] = nodeBox{cx - nw/2, cy - treeNodeHeight/2, nw, treeNodeHeight}
+		fillRect(screen, boxes[k].X, boxes[k].Y, nw, treeNodeHeight, color.RGBA{50, 50, 50, 255}, true)
+		strokeRect(screen, boxes[k].X, boxes[k].Y, nw, treeNodeHeight, 1, p.Borders[k], true)
+		printAt(screen, text, int(boxes[k].X)+4, int(cy)-8)
+	}
+	return y + h + treePanelSpacing, boxes
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Tree decompositions:
+
+// J toggles a panel on the right (treepanel.go) with a tree decomposition of the graph and its width,
+// an upper bound on the treewidth. Vertices are eliminated one at a time, each time the one with
+// the fewest neighbors left (min-degree) or whose neighbors miss the fewest edges between them
+// (min-fill); its neighbors are then joined up. Each vertex with the neighbors it had when eliminated
+// makes a bag, hung under the bag of the first of those neighbors to go. Bags inside their parent are
+// dropped. Both heuristics are tried and the narrower decomposition is kept.
+// Loops and parallel edges don't matter, edges are read both ways.
+
+type TreeDecomposition struct {
+	Bags      [][]int // Vertex indices, sorted
+	Parent    []int   // Parent bag, -1 for the root of each component
+	Width     int     // Largest bag size minus one
+	Heuristic string  // Locale key of the heuristic that found it
+}
+
+// Returns how many edges are missing between the vertices.
+func missingEdges(vertices []int, adj [][]bool) int {
+	missing := 0
+	for a, v := range vertices {
+		for _, w := range vertices[a+1:] {
+			if !adj[v][w] {
+				missing++
+			}
+		}
+	}
+	return missing
+}
+
+// Builds a tree decomposition by eliminating vertices, picking the lowest cost one each time.
+func (g *Graph) eliminationDecomposition(cost func(neighbors []int, adj [][]bool) int) *TreeDecomposition {
+	n := len(g.Vertices)
+	adj := make([][]bool, n) // Grows with the fill edges
+	for i := range n {
+		adj[i] = make([]bool, n)
+		for j := range n {
+			adj[i][j] = g.adjacent(i, j) || g.adjacent(j, i)
+		}
+	}
+	gone := make([]bool, n)
+	neighbors := func(v int) []int {
+		var ns []int
+		for w := range n {
+			if adj[v][w] && !gone[w] {
+				ns = append(ns, w)
+			}
+		}
+		return ns
+	}
+
+	pos := make([]int, n) // When each vertex went, also the index of its bag
+	td := &TreeDecomposition{Width: -1}
+	for range n {
+		best, bestCost := -1, 0
+		for v := range n {
+			if gone[v] {
+				continue
+			}
+			if c := cost(neighbors(v), adj); best < 0 || c < bestCost {
+				best, bestCost = v, c
+			}
+		}
+		ns := neighbors(best)
+		for a, v := range ns {
+			for _, w := range ns[a+1:] {
+				adj[v][w], adj[w][v] = true, true
+			}
+		}
+		gone[best] = true
+		pos[best] = len(td.Bags)
+		bag := append([]int{best}, ns...)
+		slices.Sort(bag)
+		td.Bags = append(td.Bags, bag)
+		td.Width = max(td.Width, len(bag)-1)
+	}
+
+	// Each bag hangs under the bag of its first neighbor eliminated after it
+	td.Parent = make([]int, len(td.Bags))
+	for k, bag := range td.Bags {
+		td.Parent[k] = -1
+		for _, w := range bag {
+			if pos[w] > k && (td.Parent[k] < 0 || pos[w] < td.Parent[k]) {
+				td.Parent[k] = pos[w]
+			}
+		}
+	}
+	td.dropRedundantBags()
+	return td
+}
+
+// Removes bags contained in their parent, their children move up to the parent.
+func (td *TreeDecomposition) dropRedundantBags() {
+	keep := make([]bool, len(td.Bags))
+	for k := range td.Bags {
+		keep[k] = true
+	}
+	for k, p := range td.Parent {
+		if p >= 0 && subsetOf(td.Bags[k], toSet(td.Bags[p])) {
+			keep[k] = false
+		}
+	}
+	// Children of dropped bags point past them
+	for k := range td.Bags {
+		for p := td.Parent[k]; p >= 0 && !keep[p]; p = td.Parent[p] {
+			td.Parent[k] = td.Parent[p]
+		}
+	}
+	index := make([]int, len(td.Bags))
+	var bags [][]int
+	for k, bag := range td.Bags {
+		if keep[k] {
+			index[k] = len(bags)
+			bags = append(bags, bag)
+		}
+	}
+	parent := make([]int, 0, len(bags))
+	for k := range td.Bags {
+		if keep[k] {
+			p := td.Parent[k]
+			if p >= 0 {
+				p = index[p]
+			}
+			parent = append(parent, p)
+		}
+	}
+	td.Bags, td.Parent = bags, parent
+}
+
+func toSet(s []int) map[int]bool {
+	set := make(map[int]bool, len(s))
+	for _, x := range s {
+		set[x] = true
+	}
+	return set
+}
+
+// Returns the narrower of the min-degree and min-fill decompositions, nil for the empty graph.
+func (g *Graph) TreeDecomposition() *TreeDecomposition {
+	if len(g.Vertices) == 0 {
+		return nil
+	}
+	minDegree := g.eliminationDecomposition(func(ns []int, _ [][]bool) int { return len(ns) })
+	minDegree.Heuristic = "treewidth.min_degree"
+	minFill := g.eliminationDecomposition(missingEdges)
+	minFill.Heuristic = "treewidth.min_fill"
+	if minFill.Width < minDegree.Width {
+		return minFill
+	}
+	return minDegree
+}
+
+// Decomposition of the graph, recomputed after edits.
+type decompositionCache struct {
+	revision int
+	valid    bool
+	td       *TreeDecomposition
+}
+
+// Returns the tree decomposition of the current graph.
+func (app *App) treeDecomposition() *TreeDecomposition {
+	c := &app.decomposition
+	if !c.valid || c.revision != app.revision {
+		*c = decompositionCache{revision: app.revision, valid: true, td: app.Graph.TreeDecomposition()}
+	}
+	return c.td
+}
+
+// Turns the decomposition panel on or off.
+func (app *App) ToggleDecompositionView() {
+	app.toggleView(&app.View.ShowDecomposition, "view.decomposition")
+}
+
+// Text of a bag, its vertex labels.
+func (g *Graph) bagText(bag []int) string {
+	labels := make([]string, len(bag))
+	for i, v := range bag {
+		labels[i] = g.Vertices[v].Label
+	}
+	return strings.Join(labels, " ")
+}
+
+// Draws the decomposition panel with its top at y, returns where the next panel goes.
+func (app *App) DrawDecompositionView(screen *ebiten.Image, y float32) float32 {
+	if !app.View.ShowDecomposition {
+		return y
+	}
+	td := app.treeDecomposition()
+	if td == nil {
+		return y
+	}
+	panel := TreePanel{Summary: T("treewidth.summary", td.Width, len(td.Bags), T(td.Heuristic)), Parent: td.Parent}
+	for _, bag := range td.Bags {
+		panel.Texts = append(panel.Texts, app.Graph.bagText(bag))
+		border := color.RGBA{200, 200, 200, 255}
+		if len(bag)-1 == td.Width {
+			border = highlightEdgeColor // The bags that make the width
+		}
+		panel.Borders = append(panel.Borders, border)
+	}
+	y, _ = panel.Draw(screen, y)
+	return y
+}
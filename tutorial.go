@@ -0,0 +1,89 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Tutorial:
+
+// F1 walks through the basics step by step: placing vertices, edges, parallel edges, loops and Print Info.
+// The toolbar button to use is outlined, and a step is done as soon as the drawing shows it.
+
+type TutorialStep struct {
+	Text string // Locale key
+	Tool Tool   // Button to highlight, -1 for none
+	Done func(app *App) bool
+}
+
+// Reports whether some pair of vertices has at least count edges between it (loops if loops is set).
+func hasMultiEdge(g *Graph, count int, loops bool) bool {
+	for i, row := range g.AdjMatrix {
+		for j, c := range row {
+			if (i == j) == loops && c >= count {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var tutorialSteps = []TutorialStep{
+	{Text: "tutorial.vertices", Tool: ToolAddVertex, Done: func(app *App) bool { return len(app.Graph.Vertices) >= 2 }},
+	{Text: "tutorial.edge", Tool: ToolAddEdge, Done: func(app *App) bool { return hasMultiEdge(app.Graph, 1, false) }},
+	{Text: "tutorial.parallel", Tool: ToolAddEdge, Done: func(app *App) bool { return hasMultiEdge(app.Graph, 2, false) }},
+	{Text: "tutorial.loop", Tool: ToolAddEdge, Done: func(app *App) bool { return hasMultiEdge(app.Graph, 1, true) }},
+	{Text: "tutorial.info", Tool: ToolPrintInfo, Done: func(app *App) bool {
+		return app.Dialog != nil && app.Dialog.Message == T("info.dialog")
+	}},
+	{Text: "tutorial.done", Tool: -1, Done: func(app *App) bool { return false }},
+}
+
+type Tutorial struct {
+	Step int
+}
+
+// Starts the tutorial, or stops it if it's running.
+func (app *App) ToggleTutorial() {
+	if app.Tutorial != nil {
+		app.Tutorial = nil
+		return
+	}
+	app.Tutorial = &Tutorial{}
+	app.Announce(T(tutorialSteps[0].Text))
+}
+
+// Moves on once the current step is done. Called every frame, dialogs included.
+func (app *App) UpdateTutorial() {
+	t := app.Tutorial
+	if t == nil || t.Step >= len(tutorialSteps)-1 || !tutorialSteps[t.Step].Done(app) {
+		return
+	}
+	t.Step++
+	app.Announce(T(tutorialSteps[t.Step].Text))
+}
+
+// Draws the current instruction and outlines the button to use.
+func (app *App) DrawTutorial(screen *ebiten.Image) {
+	t := app.Tutorial
+	if t == nil {
+		return
+	}
+	step := tutorialSteps[t.Step]
+
+	if step.Tool >= 0 {
+		pulse := uint8(155 + 100*math.Abs(math.Sin(float64(time.Now().UnixMilli())/300)))
+		x, y := int(step.Tool)%toolsPerRow*toolButtonWidth, int(step.Tool)/toolsPerRow*toolButtonHeight
+		strokeRect(screen, float32(x)+2, float32(y)+2, toolButtonWidth-4, toolButtonHeight-4, 4, color.RGBA{pulse, pulse, 0, 255}, true)
+	}
+
+	progress := T("tutorial.progress", t.Step+1, len(tutorialSteps))
+	w, h := float32(max(textWidth(progress), textWidth(T(step.Text)))+10), float32(38)
+	x, y := float32(10), float32(screenHeight)-h-40
+	fillRect(screen, x, y, w, h, color.RGBA{30, 30, 30, 230}, true)
+	strokeRect(screen, x, y, w, h, 1, color.RGBA{255, 255, 0, 255}, true)
+	printAt(screen, progress+"\n"+T(step.Text), int(x)+5, int(y)+3)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Display options:
+
+// Toggles that only change how the graph is drawn, not the graph itself.
+
+type ViewOptions struct {
+	ShowDegrees bool // Degree badge next to every vertex
+	FocusMode   bool // Emphasize the selected vertex's neighborhood, dim the rest
+
+	// Draw parallel edges (or loops) as a single one with a "×k" label
+	// once there are at least Settings.CollapseThreshold of them.
+	CollapseParallel bool
+
+	AutoLayout bool // Keep a light force layout running while editing (layout.go)
+
+	ColoringMode bool // Number the color classes and flag same-colored neighbors (coloring.go)
+
+	BundleEdges bool // Draw edges in bundles (bundle.go)
+
+	ShowModel bool // Interval model or permutation diagram panel (model.go)
+
+	ShowDecomposition bool // Tree decomposition panel (treewidth.go)
+
+	ShowModules bool // Modular decomposition panel (modules.go)
+
+	ShowHeatmap  bool         // Adjacency heatmap panel (heatmap.go)
+	HeatmapOrder HeatmapOrder // Order of its rows and columns
+
+	LabelPlacement LabelPlacement // Where vertex labels go (labels.go)
+}
+
+var highlightEdgeColor = color.RGBA{255, 255, 0, 255}
+
+// Returns a faded version of a color, used for everything outside the focus.
+func dim(c color.RGBA) color.RGBA {
+	return color.RGBA{c.R / 4, c.G / 4, c.B / 4, c.A / 4}
+}
+
+// Returns the vertex focus mode is centered on, or -1.
+// That's the selected vertex, or the keyboard focus if nothing is selected.
+func (app *App) focusCenter() int {
+	if !app.View.FocusMode {
+		return -1
+	}
+	for _, ref := range []*int{app.Selected, app.Focused} {
+		if ref != nil && app.Graph.CheckVertices(*ref) == nil {
+			return *ref
+		}
+	}
+	return -1
+}
+
+// Color used to draw the edges between i and j.
+func (app *App) edgeColor(i, j int) color.RGBA {
+	if app.Selection.Edges[Edge(i, j)] {
+		return selectionColor
+	}
+	if step := app.animationStep(); step != nil && step.Edges[Edge(i, j)] {
+		return highlightEdgeColor
+	}
+	if app.conflicting(i, j) {
+		return conflictColor
+	}
+	clr := app.edgeStyle(i, j).Color
+	if app.filteredOut(i) || app.filteredOut(j) { // Dimmed by the filter, hidden ones aren't drawn
+		return dim(clr)
+	}
+	if c := app.focusCenter(); c >= 0 {
+		if i == c || j == c {
+			return highlightEdgeColor
+		}
+		return dim(clr)
+	}
+	return clr
+}
+
+// Color used to draw vertex i. mapped holds the colors from coloring by a metric (may be nil).
+func (app *App) vertexColor(i int, mapped []color.RGBA) color.RGBA {
+	clr := app.Graph.Vertices[i].Color
+	if mapped != nil {
+		clr = mapped[i]
+	}
+	if step := app.animationStep(); step != nil {
+		if c, ok := step.Vertices[i]; ok {
+			return c
+		}
+	}
+	if app.filteredOut(i) {
+		return dim(clr)
+	}
+	c := app.focusCenter()
+	if c < 0 || i == c || app.Graph.AdjMatrix[c][i] > 0 {
+		return clr
+	}
+	return dim(clr)
+}
+
+// Flips a display option and shows a notice saying which way it went.
+func (app *App) toggleView(option *bool, name string) {
+	*option = !*option
+	state := T("view.off")
+	if *option {
+		state = T("view.on")
+	}
+	msg := T("view.toggled", T(name), state)
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Draws a small badge with the vertex degree at the vertex's top right.
+func (app *App) drawDegreeBadge(screen *ebiten.Image, i int) {
+	v := app.Graph.Vertices[i]
+	text := strconv.Itoa(app.Graph.Degree(i))
+	offset := app.vertexRadius(i) * 0.95 // Roughly on the rim, at 45 degrees
+	bx, by := float32(v.X+offset), float32(v.Y-offset)
+	r := float32(max(8, textWidth(text)/2+3))
+	fillCircle(screen, bx, by, r, color.RGBA{50, 50, 50, 255}, true)
+	strokeCircle(screen, bx, by, r, 1, color.RGBA{200, 200, 200, 255}, true)
+	printAt(screen, text, int(bx)-textWidth(text)/2, int(by)-8)
+}
+
+// Reports whether this many parallel edges are drawn as a single labeled one.
+func (app *App) collapsed(count int) bool {
+	return app.View.CollapseParallel && count > 1 && count >= app.Settings.CollapseThreshold
+}
+
+// Draws the edges between i and j as one edge (or loop) labeled with the multiplicity.
+func (app *App) drawCollapsedEdges(screen *ebiten.Image, i, j int, clr color.RGBA) {
+	g := app.Graph
+	v1, v2 := g.Vertices[i], g.Vertices[j]
+	label := "×" + strconv.Itoa(g.Multiplicity(i, j))
+
+	var lx, ly float64
+	if i == j {
+		DrawLoopEdge(screen, v1.X, v1.Y, 1, app.edgeStyle(i, j).Width, clr)
+		lx, ly = v1.X+50, v1.Y // Tip of the loop
+	} else {
+		strokeLine(screen, float32(v1.X), float32(v1.Y), float32(v2.X), float32(v2.Y), float32(app.edgeStyle(i, j).Width), clr, true)
+		lx, ly = (v1.X+v2.X)/2, (v1.Y+v2.Y)/2
+	}
+
+	w := float32(textWidth(label) + 6)
+	fillRect(screen, float32(lx)-w/2, float32(ly)-9, w, 18, color.RGBA{30, 30, 30, 255}, true)
+	strokeRect(screen, float32(lx)-w/2, float32(ly)-9, w, 18, 1, clr, true)
+	printAt(screen, label, int(lx)-textWidth(label)/2, int(ly)-8)
+}
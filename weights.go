@@ -0,0 +1,165 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Vertex weights:
+
+// Every vertex carries a weight (1 by default), shown under it when it's something else.
+// W opens the weights dialog: set the weight of the selected vertices, or run a vertex-weighted algorithm.
+// Results come back as the selection, with their total weight. Edge directions don't matter.
+//
+// Both problems are NP-hard, so these are the classic quick approximations, not exact answers:
+//   - Independent set: greedy, repeatedly take the vertex with the largest weight/(degree+1) and drop its neighbors.
+//   - Vertex cover: local ratio (Bar-Yehuda & Even), at most twice the optimal weight.
+//
+// Weights are also a metric (metrics.go), so vertices can be colored and sized by them.
+
+// Reports whether any vertex has a weight other than 1.
+func (g *Graph) Weighted() bool {
+	for _, v := range g.Vertices {
+		if v.Weight != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the total weight of some vertices.
+func (g *Graph) TotalWeight(vertices []int) float64 {
+	total := 0.0
+	for _, i := range vertices {
+		total += g.Vertices[i].Weight
+	}
+	return total
+}
+
+// Returns a heavy independent set, in vertex order. Vertices with loops are never in it.
+func (g *Graph) WeightedIndependentSet() []int {
+	n := len(g.Vertices)
+	removed := make([]bool, n)
+	for i := range n {
+		removed[i] = g.AdjMatrix[i][i] > 0
+	}
+	var set []int
+	for {
+		best, bestScore := -1, 0.0
+		for i := range n {
+			if removed[i] {
+				continue
+			}
+			degree := 0
+			for j := range n {
+				if !removed[j] && g.adjacent(i, j) {
+					degree++
+				}
+			}
+			if score := g.Vertices[i].Weight / float64(degree+1); best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+		set = append(set, best)
+		removed[best] = true
+		for j := range n {
+			if g.Multiplicity(best, j) > 0 {
+				removed[j] = true
+			}
+		}
+	}
+	sort.Ints(set)
+	return set
+}
+
+// Returns a light vertex cover, in vertex order. Vertices with loops are always in it.
+// Each edge pays the smaller leftover weight of its ends; the vertices paid off entirely form the cover.
+func (g *Graph) WeightedVertexCover() []int {
+	n := len(g.Vertices)
+	left := make([]float64, n)
+	for i, v := range g.Vertices {
+		left[i] = v.Weight
+	}
+	var cover []int
+	inCover := func(i int) bool { return left[i] <= 0 || g.AdjMatrix[i][i] > 0 }
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			if g.Multiplicity(i, j) == 0 || inCover(i) || inCover(j) {
+				continue
+			}
+			pay := min(left[i], left[j])
+			left[i] -= pay
+			left[j] -= pay
+		}
+	}
+	for i := range n {
+		if inCover(i) {
+			cover = append(cover, i)
+		}
+	}
+	return cover
+}
+
+// Draws the weight under vertex i.
+func (app *App) drawWeight(screen *ebiten.Image, i int) {
+	v := app.Graph.Vertices[i]
+	text := "w=" + formatMetric(v.Weight)
+	printAt(screen, text, int(v.X)-textWidth(text)/2, int(v.Y+app.vertexRadius(i))+1)
+}
+
+// Asks for a weight, then gives it to every selected vertex.
+func (app *App) weighSelection() {
+	vertices := app.selectionVertices()
+	if len(vertices) == 0 {
+		app.Warn(T("weights.no_selection"))
+		return
+	}
+	app.askPredicate(T("weights.prompt"), func(text string) {
+		w, err := strconv.ParseFloat(text, 64)
+		if err != nil || w < 0 {
+			app.Warn(T("warn.not_a_number", text))
+			return
+		}
+		app.BeginTransaction(T("undo.weights"))
+		defer app.Commit()
+		for _, i := range vertices {
+			app.Do(Action{Kind: ActionWeightVertex, V1: i, Weight: w})
+		}
+	})
+}
+
+// Selects the result of a weighted algorithm and says how heavy it is.
+func (app *App) selectWeighted(name string, vertices []int) {
+	app.Selection.Clear()
+	for _, i := range vertices {
+		app.Selection.AddVertex(i, false)
+	}
+	msg := T("weights.result", T(name), len(vertices), formatMetric(app.Graph.TotalWeight(vertices)))
+	app.Notify(msg)
+	app.Announce(msg)
+}
+
+// Asks what to do with weights.
+func (app *App) ShowWeightsDialog() {
+	if len(app.Graph.Vertices) == 0 {
+		return
+	}
+	app.ShowDialog(&Dialog{
+		Message: T("weights.dialog"),
+		Buttons: []DialogButton{
+			{Label: T("weights.set"), Action: app.weighSelection},
+			{Label: T("weights.independent_set"), Action: func() {
+				app.selectWeighted("weights.independent_set", app.Graph.WeightedIndependentSet())
+			}},
+			{Label: T("weights.vertex_cover"), Action: func() {
+				app.selectWeighted("weights.vertex_cover", app.Graph.WeightedVertexCover())
+			}},
+			{Label: T("dialog.cancel")},
+		},
+	})
+}